@@ -77,7 +77,11 @@ func NewApp(container *di.Container) *gin.Engine {
 	routers.RegisterAuthRoutes(v2, container)
 	routers.RegisterUsersRoutes(v2, container)
 	routers.RegisterTeamRoutes(v2, container)
+	routers.RegisterImageRoutes(v2, container)
+	routers.RegisterThemeRoutes(v2, container)
+	routers.RegisterFontRoutes(v2, container)
 	routers.RegisterSongRoutes(v2, container)
+	routers.RegisterImportRoutes(v2, container)
 	routers.RegisterDeckRoutes(v2, container)
 	routers.RegisterLiturgyRoutes(v2, container)
 	routers.RegisterLiveRoutes(v2, container)
@@ -12,13 +12,16 @@ func scheduleRemove(name string) {
 	DeletePublicFile(name)
 }
 
+func PublicFilePath(name string) string {
+	return fmt.Sprintf("public/%s", name)
+}
+
 func DeletePublicFile(name string) error {
-	path := fmt.Sprintf("public/%s", name)
-	return os.Remove(path)
+	return os.Remove(PublicFilePath(name))
 }
 
 func SavePublicFile(src io.Reader, name string) error {
-	path := fmt.Sprintf("public/%s", name)
+	path := PublicFilePath(name)
 	dest, err := os.Create(path)
 	if err != nil {
 		return err
@@ -0,0 +1,236 @@
+package core
+
+import (
+	"math"
+	"strings"
+)
+
+const TranslationTag = "<translation>"
+
+const TranslationStacked = "stacked"
+const TranslationColumns = "columns"
+
+type TranslationOptions struct {
+	// Layout is either TranslationStacked (the default), which puts every line
+	// above its translation, or TranslationColumns.
+	Layout string
+	// FontScale sets the size of the stacked translation relative to the verse.
+	FontScale float64
+	Color     Color
+	// Language is the language the translation is hyphenated in, if the deck is hyphenated.
+	Language string
+}
+
+// JoinTranslation puts a verse together with its translation,
+// so that they are laid out on the same slides.
+func JoinTranslation(verse string, translation string) string {
+	return verse + "\n" + TranslationTag + "\n" + translation
+}
+
+func SplitTranslation(verse string) (string, string, bool) {
+	return strings.Cut(verse, "\n"+TranslationTag+"\n")
+}
+
+// StackTranslation puts every line of a verse above its translation,
+// for the outputs that only have plain text.
+func StackTranslation(verse string) string {
+	original, translation, ok := SplitTranslation(verse)
+	if !ok {
+		return verse
+	}
+
+	originalLines := strings.Split(original, "\n")
+	translationLines := strings.Split(translation, "\n")
+	lines := make([]string, 0, len(originalLines)+len(translationLines))
+	for i := 0; i < max(len(originalLines), len(translationLines)); i++ {
+		if i < len(originalLines) {
+			lines = append(lines, originalLines[i])
+		}
+		if i < len(translationLines) {
+			lines = append(lines, translationLines[i])
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (c PageConfig) isColumnLayout() bool {
+	return c.Translation.Layout == TranslationColumns
+}
+
+// TranslationFontSize is the font size of the translation of a verse set in
+// the given size. Columns are set in the same size, so that their lines match.
+func (c PageConfig) TranslationFontSize(fontSize int) int {
+	if c.isColumnLayout() || c.Translation.FontScale <= 0 {
+		return fontSize
+	}
+
+	return max(1, int(math.Round(float64(fontSize)*c.Translation.FontScale)))
+}
+
+// TranslationColumnWidth is the width of each column, with a margin between them.
+func (c PageConfig) TranslationColumnWidth() float64 {
+	return (c.ContentWidth() - c.Margin) / 2
+}
+
+func (c PageConfig) lineFontSize(line SlideLine, fontSize int) int {
+	if line.Translation {
+		return c.TranslationFontSize(fontSize)
+	}
+
+	return fontSize
+}
+
+// LinesHeight is the height of the lines of a slide, taking the translation into account.
+func (c PageConfig) LinesHeight(lines []SlideLine, fontSize int) float64 {
+	height := 0.0
+	for _, line := range lines {
+		if line.Translation && c.isColumnLayout() {
+			continue
+		}
+		height += c.LineHeightAt(c.lineFontSize(line, fontSize))
+	}
+
+	return height
+}
+
+// SplitColumns separates the lines of the verse from the lines of its translation.
+func SplitColumns(lines []SlideLine) ([]SlideLine, []SlideLine) {
+	original := make([]SlideLine, 0)
+	translation := make([]SlideLine, 0)
+	for _, line := range lines {
+		if line.Translation {
+			translation = append(translation, line)
+		} else {
+			original = append(original, line)
+		}
+	}
+
+	return original, translation
+}
+
+func toSlideLines(lines []string, responses []bool, isTranslation bool) []SlideLine {
+	slideLines := make([]SlideLine, len(lines))
+	for i, line := range lines {
+		text, isParagraphEnd := strings.CutSuffix(line, LineEndMark)
+		slideLines[i] = SlideLine{
+			Text:         strings.TrimRight(text, " "),
+			Response:     responses[i],
+			ParagraphEnd: isParagraphEnd,
+			Translation:  isTranslation,
+		}
+	}
+
+	return slideLines
+}
+
+// translationRows breaks the lines of a verse and its translation. Every line
+// makes a group of rows along with its translation, which is never separated
+// from it. A row holds one line, or one line of each column.
+func translationRows(original []string, translation []string, pageConfig PageConfig, measure Measurer, fontSize int) [][][]SlideLine {
+	contentWidth := pageConfig.ContentWidth()
+	if pageConfig.isColumnLayout() {
+		contentWidth = pageConfig.TranslationColumnWidth()
+	}
+
+	translationConfig := pageConfig
+	translationConfig.LineBreak.Language = pageConfig.Translation.Language
+
+	groups := make([][][]SlideLine, 0)
+	for i := 0; i < max(len(original), len(translation)); i++ {
+		originalLines := make([]SlideLine, 0)
+		if i < len(original) {
+			lines, responses := breakVerse(original[i:i+1], pageConfig, measure, fontSize, contentWidth)
+			originalLines = toSlideLines(lines, responses, false)
+		}
+
+		translationLines := make([]SlideLine, 0)
+		if i < len(translation) {
+			lines, responses := breakVerse(translation[i:i+1], translationConfig, measure, pageConfig.TranslationFontSize(fontSize), contentWidth)
+			translationLines = toSlideLines(lines, responses, true)
+		}
+
+		group := make([][]SlideLine, 0)
+		if pageConfig.isColumnLayout() {
+			for j := 0; j < max(len(originalLines), len(translationLines)); j++ {
+				row := []SlideLine{{ParagraphEnd: true}, {ParagraphEnd: true, Translation: true}}
+				if j < len(originalLines) {
+					row[0] = originalLines[j]
+				}
+				if j < len(translationLines) {
+					row[1] = translationLines[j]
+				}
+				group = append(group, row)
+			}
+		} else {
+			for _, line := range append(originalLines, translationLines...) {
+				group = append(group, []SlideLine{line})
+			}
+		}
+
+		groups = append(groups, group)
+	}
+
+	return groups
+}
+
+// paginateRows fills the slides with groups of rows, moving a group to the next
+// slide when it doesn't fit. Only the groups longer than a slide are split.
+func paginateRows(groups [][][]SlideLine, pageConfig PageConfig, fontSize int) [][]SlideLine {
+	pages := make([][]SlideLine, 0)
+	page := make([]SlideLine, 0)
+	height := 0.0
+
+	for _, group := range groups {
+		groupHeight := 0.0
+		for _, row := range group {
+			groupHeight += pageConfig.LinesHeight(row, fontSize)
+		}
+
+		if height > 0 && height+groupHeight > pageConfig.PageHeight {
+			pages = append(pages, page)
+			page = make([]SlideLine, 0)
+			height = 0
+		}
+
+		for _, row := range group {
+			rowHeight := pageConfig.LinesHeight(row, fontSize)
+			if height > 0 && height+rowHeight > pageConfig.PageHeight {
+				pages = append(pages, page)
+				page = make([]SlideLine, 0)
+				height = 0
+			}
+
+			page = append(page, row...)
+			height += rowHeight
+		}
+	}
+
+	if len(page) > 0 {
+		pages = append(pages, page)
+	}
+
+	return pages
+}
+
+// fitTranslation lays out a verse with its translation like fitVerse does,
+// shrinking it according to the fit mode.
+func fitTranslation(verse string, pageConfig PageConfig, measure Measurer) (int, [][]SlideLine) {
+	original, translation, _ := SplitTranslation(verse)
+	originalLines := strings.Split(original, "\n")
+	translationLines := strings.Split(translation, "\n")
+
+	fontSize := pageConfig.FontSize
+	pages := paginateRows(translationRows(originalLines, translationLines, pageConfig, measure, fontSize), pageConfig, fontSize)
+
+	if pageConfig.FitMode != FitShrink && pageConfig.FitMode != FitShrinkThenSplit {
+		return fontSize, pages
+	}
+
+	for len(pages) > 1 && fontSize > pageConfig.minFitFontSize() {
+		fontSize--
+		pages = paginateRows(translationRows(originalLines, translationLines, pageConfig, measure, fontSize), pageConfig, fontSize)
+	}
+
+	return fontSize, pages
+}
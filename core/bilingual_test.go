@@ -0,0 +1,76 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStackTranslation(t *testing.T) {
+	verse := JoinTranslation("Pan blisko jest\nOczekuj Go", "The Lord is near\nAwait Him\nAlleluia")
+	expected := "Pan blisko jest\nThe Lord is near\nOczekuj Go\nAwait Him\nAlleluia"
+
+	if result := StackTranslation(verse); result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func translationTestPageConfig(layout string) PageConfig {
+	return PageConfig{
+		PageWidth:   1000,
+		PageHeight:  100,
+		FontSize:    20,
+		LineSpacing: 1.25,
+		Translation: TranslationOptions{Layout: layout, FontScale: 0.5},
+	}
+}
+
+func TestLayoutDeckKeepsLinesWithTranslation(t *testing.T) {
+	verse := JoinTranslation("Pierwsza\nDruga\nTrzecia", "First\nSecond\nThird")
+	slides := verseSlides(LayoutDeck([][]string{{verse}}, nil, translationTestPageConfig(TranslationStacked), measureLength))
+
+	// a line with its translation is 25+12.5 points high, so two of them fit on a slide
+	if len(slides) != 2 {
+		t.Fatalf("Expected 2 slides, got %d", len(slides))
+	}
+
+	expected := [][]SlideLine{
+		{
+			{Text: "Pierwsza", ParagraphEnd: true},
+			{Text: "First", ParagraphEnd: true, Translation: true},
+			{Text: "Druga", ParagraphEnd: true},
+			{Text: "Second", ParagraphEnd: true, Translation: true},
+		},
+		{
+			{Text: "Trzecia", ParagraphEnd: true},
+			{Text: "Third", ParagraphEnd: true, Translation: true},
+		},
+	}
+
+	for i, slide := range slides {
+		if !reflect.DeepEqual(slide.Lines, expected[i]) {
+			t.Errorf("Expected slide %d to have %v, got %v", i, expected[i], slide.Lines)
+		}
+	}
+}
+
+func TestLayoutDeckAlignsTranslationColumns(t *testing.T) {
+	verse := JoinTranslation("Pierwsza\nDruga", "First\nSecond\nThird")
+	slides := verseSlides(LayoutDeck([][]string{{verse}}, nil, translationTestPageConfig(TranslationColumns), measureLength))
+
+	if len(slides) != 1 {
+		t.Fatalf("Expected 1 slide, got %d", len(slides))
+	}
+
+	original, translation := SplitColumns(slides[0].Lines)
+	if len(original) != 3 || len(translation) != 3 {
+		t.Fatalf("Expected both columns to have 3 lines, got %v and %v", original, translation)
+	}
+
+	if original[2].Text != "" || translation[2].Text != "Third" {
+		t.Errorf("Expected the missing line to be padded, got %v and %v", original[2], translation[2])
+	}
+
+	if slides[0].FontSize != 20 || translationTestPageConfig(TranslationColumns).TranslationFontSize(20) != 20 {
+		t.Errorf("Expected both columns to keep the font size")
+	}
+}
@@ -0,0 +1,209 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/signintech/gopdf"
+)
+
+type ChordSheetOptions struct {
+	Transpose int
+	Capo      int
+}
+
+const chordSheetMargin = 40.0
+const chordSheetFontSize = 12
+const chordSheetTitleFontSize = 16
+const chordSheetChordFontSize = 11
+const chordSheetLineSpacing = 1.25
+const chordSheetChordGap = 4.0
+
+var chordColor = Color{R: 176, G: 32, B: 32}
+
+type chordSheetLine struct {
+	text   string
+	chords []ChordMark
+}
+
+type ChordSheet struct {
+	goPdf   *gopdf.GoPdf
+	options ChordSheetOptions
+	y       float64
+}
+
+func (s *ChordSheet) contentWidth() float64 {
+	return gopdf.PageSizeA4.W - 2*chordSheetMargin
+}
+
+func (s *ChordSheet) lineHeight(fontSize int) float64 {
+	return float64(fontSize) * chordSheetLineSpacing
+}
+
+func (s *ChordSheet) measure(text string, fontSize int) float64 {
+	s.goPdf.SetFont("default", "", fontSize)
+	width, _ := s.goPdf.MeasureTextWidth(text)
+	return width
+}
+
+// wrapLine breaks a line on spaces to fit the page width,
+// keeping each chord attached to the syllable it was placed over.
+func (s *ChordSheet) wrapLine(line chordSheetLine) []chordSheetLine {
+	if s.measure(line.text, chordSheetFontSize) <= s.contentWidth() {
+		return []chordSheetLine{line}
+	}
+
+	result := make([]chordSheetLine, 0)
+	start := 0
+	lastSpace := -1
+
+	for i := 0; i <= len(line.text); i++ {
+		if i < len(line.text) && line.text[i] != ' ' {
+			continue
+		}
+
+		if s.measure(line.text[start:i], chordSheetFontSize) > s.contentWidth() && lastSpace > start {
+			result = append(result, line.slice(start, lastSpace))
+			start = lastSpace + 1
+		}
+		lastSpace = i
+	}
+
+	return append(result, line.slice(start, len(line.text)))
+}
+
+func (l chordSheetLine) slice(start int, end int) chordSheetLine {
+	// the parts after the first one start after the space where the line was broken,
+	// and a chord placed over that space goes to the start of the part
+	from := start
+	if start > 0 {
+		from = start - 1
+	}
+
+	chords := make([]ChordMark, 0)
+	for _, chord := range l.chords {
+		if chord.Position >= from && (chord.Position < end || end == len(l.text)) {
+			chords = append(chords, ChordMark{Position: max(chord.Position-start, 0), Chord: chord.Chord})
+		}
+	}
+
+	return chordSheetLine{text: l.text[start:end], chords: chords}
+}
+
+func (s *ChordSheet) lineBlockHeight(line chordSheetLine) float64 {
+	height := s.lineHeight(chordSheetFontSize)
+	if len(line.chords) > 0 {
+		height += s.lineHeight(chordSheetChordFontSize)
+	}
+
+	return height
+}
+
+func (s *ChordSheet) ensureSpace(height float64) {
+	if s.y+height > gopdf.PageSizeA4.H-chordSheetMargin {
+		s.goPdf.AddPage()
+		s.y = chordSheetMargin
+	}
+}
+
+func (s *ChordSheet) writeText(text string, x float64, fontSize int, color Color) {
+	s.goPdf.SetFont("default", "", fontSize)
+	s.goPdf.SetFillColor(color.R, color.G, color.B)
+	s.goPdf.SetX(x)
+	s.goPdf.SetY(s.y)
+	s.goPdf.Cell(nil, text)
+}
+
+func (s *ChordSheet) writeLine(line chordSheetLine) {
+	if len(line.chords) > 0 {
+		minX := chordSheetMargin
+		for _, chord := range line.chords {
+			x := chordSheetMargin + s.measure(line.text[:chord.Position], chordSheetFontSize)
+			x = max(x, minX)
+			s.writeText(chord.Chord, x, chordSheetChordFontSize, chordColor)
+			minX = x + s.measure(chord.Chord, chordSheetChordFontSize) + chordSheetChordGap
+		}
+		s.y += s.lineHeight(chordSheetChordFontSize)
+	}
+
+	s.writeText(line.text, chordSheetMargin, chordSheetFontSize, Color{})
+	s.y += s.lineHeight(chordSheetFontSize)
+}
+
+func (s *ChordSheet) writeVerse(verse string) {
+	verse = TransposeChords(verse, s.options.Transpose-s.options.Capo)
+
+	lines := make([]chordSheetLine, 0)
+	for _, rawLine := range strings.Split(PlainVerse(verse), "\n") {
+		text, chords := ParseChordLine(rawLine)
+		lines = append(lines, s.wrapLine(chordSheetLine{text: text, chords: chords})...)
+	}
+
+	verseHeight := 0.0
+	for _, line := range lines {
+		verseHeight += s.lineBlockHeight(line)
+	}
+
+	// keep a verse on a single page unless it is longer than the page itself
+	s.ensureSpace(min(verseHeight, gopdf.PageSizeA4.H-2*chordSheetMargin))
+
+	for _, line := range lines {
+		s.ensureSpace(s.lineBlockHeight(line))
+		s.writeLine(line)
+	}
+
+	s.y += s.lineHeight(chordSheetFontSize)
+}
+
+func (s *ChordSheet) writeTitle(info ItemInfo) {
+	if info.Title == "" {
+		return
+	}
+
+	s.ensureSpace(s.lineHeight(chordSheetTitleFontSize) + 3*s.lineHeight(chordSheetFontSize))
+	s.writeText(info.Title, chordSheetMargin, chordSheetTitleFontSize, Color{})
+	s.y += s.lineHeight(chordSheetTitleFontSize)
+
+	if info.Author != "" {
+		s.writeText(info.Author, chordSheetMargin, chordSheetChordFontSize, songbookMetaColor)
+		s.y += s.lineHeight(chordSheetChordFontSize)
+	}
+}
+
+func BuildChordSheetPDF(textDeck [][]string, items []ItemInfo, pageConfig PageConfig, options ChordSheetOptions) (*gopdf.GoPdf, error) {
+	s := ChordSheet{goPdf: &gopdf.GoPdf{}, options: options, y: chordSheetMargin}
+	s.goPdf.Start(gopdf.Config{PageSize: *gopdf.PageSizeA4})
+
+	err := addFont(s.goPdf, "default", pageConfig.Font)
+	if err != nil {
+		return nil, err
+	}
+
+	s.goPdf.AddPage()
+
+	for itemIndex, song := range textDeck {
+		if itemIndex > 0 {
+			s.y += s.lineHeight(chordSheetFontSize)
+		}
+
+		if itemIndex < len(items) {
+			s.writeTitle(items[itemIndex])
+		}
+
+		if options.Capo > 0 {
+			s.ensureSpace(s.lineHeight(chordSheetChordFontSize))
+			s.writeText(fmt.Sprintf("Capo %d", options.Capo), chordSheetMargin, chordSheetChordFontSize, chordColor)
+			s.y += s.lineHeight(chordSheetChordFontSize)
+		}
+
+		for _, verse := range song {
+			if strings.HasPrefix(verse, HintStartTag) && strings.HasSuffix(verse, HintEndTag) {
+				continue
+			}
+
+			s.writeVerse(verse)
+		}
+	}
+
+	return s.goPdf, nil
+}
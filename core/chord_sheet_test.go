@@ -0,0 +1,23 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestChordSheetLineSlice(t *testing.T) {
+	text, chords := ParseChordLine("[G]Pan blisko[D] jest")
+	line := chordSheetLine{text, chords}
+	space := len("Pan blisko")
+
+	first := line.slice(0, space)
+	second := line.slice(space+1, len(text))
+
+	if first.text != "Pan blisko" || !reflect.DeepEqual(first.chords, []ChordMark{{0, "G"}}) {
+		t.Errorf("Unexpected first part: %+v", first)
+	}
+
+	if second.text != "jest" || !reflect.DeepEqual(second.chords, []ChordMark{{0, "D"}}) {
+		t.Errorf("Unexpected second part: %+v", second)
+	}
+}
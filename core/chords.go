@@ -0,0 +1,177 @@
+package core
+
+import (
+	"regexp"
+	"strings"
+)
+
+type ChordMark struct {
+	Position int
+	Chord    string
+}
+
+var chordRegexp = regexp.MustCompile(`\[([A-Ha-h][#b♯♭]?(?:maj|min|dim|aug|sus|add|m|M|\+|°|ø)?\d*(?:(?:sus|add|maj|b|#)\d+)*(?:/[A-Ha-h][#b♯♭]?)?)\]`)
+var multipleSpaces = regexp.MustCompile(` {2,}`)
+
+var sharpNotes = []string{"C", "C#", "D", "D#", "E", "F", "F#", "G", "G#", "A", "A#", "B"}
+var flatNotes = []string{"C", "Db", "D", "Eb", "E", "F", "Gb", "G", "Ab", "A", "Bb", "B"}
+
+var noteValues = map[string]int{"C": 0, "D": 2, "E": 4, "F": 5, "G": 7, "A": 9, "B": 11, "H": 11}
+
+func HasChords(text string) bool {
+	return chordRegexp.MatchString(text)
+}
+
+// IsChord tells whether a whole bracketed token, like "[G]", is a chord.
+func IsChord(token string) bool {
+	match := chordRegexp.FindStringIndex(token)
+	return match != nil && match[0] == 0 && match[1] == len(token)
+}
+
+func StripChords(text string) string {
+	if !HasChords(text) {
+		return text
+	}
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if !HasChords(line) {
+			continue
+		}
+
+		line = chordRegexp.ReplaceAllString(line, "")
+		line = multipleSpaces.ReplaceAllString(line, " ")
+		lines[i] = strings.Trim(line, " ")
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// ParseChordLine removes chord markers from a single line of lyrics
+// and returns them along with their byte offsets in the remaining text.
+func ParseChordLine(line string) (string, []ChordMark) {
+	chords := make([]ChordMark, 0)
+	text := ""
+	last := 0
+
+	for _, match := range chordRegexp.FindAllStringSubmatchIndex(line, -1) {
+		text += line[last:match[0]]
+		chords = append(chords, ChordMark{Position: len(text), Chord: line[match[2]:match[3]]})
+		last = match[1]
+	}
+	text += line[last:]
+
+	return text, chords
+}
+
+func usesGermanNotation(text string) bool {
+	for _, match := range chordRegexp.FindAllStringSubmatch(text, -1) {
+		if strings.EqualFold(match[1][0:1], "H") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func parseNote(chord string, germanNotation bool) (int, int, bool, bool) {
+	letter := strings.ToUpper(chord[0:1])
+	value, ok := noteValues[letter]
+	if !ok {
+		return 0, 0, false, false
+	}
+
+	if germanNotation && letter == "B" {
+		value = 10
+	}
+
+	length := 1
+	flat := false
+	rest := chord[1:]
+	switch {
+	case strings.HasPrefix(rest, "#"):
+		value++
+		length += len("#")
+	case strings.HasPrefix(rest, "♯"):
+		value++
+		length += len("♯")
+	case strings.HasPrefix(rest, "b") && !(letter == "B" && germanNotation):
+		value--
+		length += len("b")
+		flat = true
+	case strings.HasPrefix(rest, "♭"):
+		value--
+		length += len("♭")
+		flat = true
+	}
+
+	return (value + 12) % 12, length, flat, true
+}
+
+func noteName(value int, flat bool, germanNotation bool, lowercase bool) string {
+	name := sharpNotes[value]
+	if flat {
+		name = flatNotes[value]
+	}
+
+	if germanNotation {
+		switch value {
+		case 10:
+			name = "B"
+		case 11:
+			name = "H"
+		}
+	}
+
+	if lowercase {
+		name = strings.ToLower(name)
+	}
+
+	return name
+}
+
+func transposeNote(note string, semitones int, germanNotation bool) (string, int) {
+	value, length, flat, ok := parseNote(note, germanNotation)
+	if !ok {
+		return note, len(note)
+	}
+
+	lowercase := note[0:1] != strings.ToUpper(note[0:1])
+	value = ((value+semitones)%12 + 12) % 12
+
+	return noteName(value, flat, germanNotation, lowercase), length
+}
+
+// TransposeChord shifts a chord by the given number of semitones, keeping
+// its quality and bass note. In the German (Polish) notation H stands for
+// B natural and B for B flat.
+func TransposeChord(chord string, semitones int, germanNotation bool) string {
+	if semitones%12 == 0 || chord == "" {
+		return chord
+	}
+
+	root, rootLength := transposeNote(chord, semitones, germanNotation)
+	rest := chord[rootLength:]
+
+	if slashIndex := strings.LastIndex(rest, "/"); slashIndex >= 0 && slashIndex+1 < len(rest) {
+		bass, _ := transposeNote(rest[slashIndex+1:], semitones, germanNotation)
+		rest = rest[:slashIndex+1] + bass
+	}
+
+	return root + rest
+}
+
+// TransposeChords transposes every chord marker in the text. The notation
+// is detected once for the whole text, so B is read consistently.
+func TransposeChords(text string, semitones int) string {
+	if semitones%12 == 0 {
+		return text
+	}
+
+	germanNotation := usesGermanNotation(text)
+
+	return chordRegexp.ReplaceAllStringFunc(text, func(marker string) string {
+		chord := marker[1 : len(marker)-1]
+		return "[" + TransposeChord(chord, semitones, germanNotation) + "]"
+	})
+}
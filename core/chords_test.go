@@ -0,0 +1,98 @@
+package core
+
+import (
+	"testing"
+)
+
+func TestStripChords(t *testing.T) {
+	result := StripChords("[G]Pan blisko [D]jest,\n[e] oczekuj [C] Go.\nbez akordów")
+
+	if result != "Pan blisko jest,\noczekuj Go.\nbez akordów" {
+		t.Errorf("Unexpected result: %q", result)
+	}
+}
+
+func TestStripChordsKeepsVerseNames(t *testing.T) {
+	result := StripChords("[ref] Alleluja")
+
+	if result != "[ref] Alleluja" {
+		t.Errorf("Expected the verse name to be kept, got %q", result)
+	}
+}
+
+func TestParseChordLine(t *testing.T) {
+	text, chords := ParseChordLine("[G]Pan blisko [D/F#]jest")
+
+	if text != "Pan blisko jest" {
+		t.Errorf("Unexpected text: %q", text)
+	}
+
+	if len(chords) != 2 {
+		t.Fatalf("Expected 2 chords, got %d", len(chords))
+	}
+
+	if chords[0].Position != 0 || chords[0].Chord != "G" {
+		t.Errorf("Unexpected first chord: %+v", chords[0])
+	}
+
+	if chords[1].Position != len("Pan blisko ") || chords[1].Chord != "D/F#" {
+		t.Errorf("Unexpected second chord: %+v", chords[1])
+	}
+}
+
+func TestTransposeChord(t *testing.T) {
+	testCases := []struct {
+		chord          string
+		semitones      int
+		germanNotation bool
+		expected       string
+	}{
+		{"G", 2, false, "A"},
+		{"Em7", 2, false, "F#m7"},
+		{"Bb", 2, false, "C"},
+		{"Eb", 1, false, "E"},
+		{"Ab", 1, false, "A"},
+		{"D/F#", -2, false, "C/E"},
+		{"Csus4", -1, false, "Bsus4"},
+		{"e", 2, true, "f#"},
+		{"H7", 1, true, "C7"},
+		{"C", -1, true, "H"},
+		{"B", 1, true, "H"},
+		{"a", 3, true, "c"},
+	}
+
+	for _, tc := range testCases {
+		result := TransposeChord(tc.chord, tc.semitones, tc.germanNotation)
+		if result != tc.expected {
+			t.Errorf("Transposing %s by %d: expected %s, got %s", tc.chord, tc.semitones, tc.expected, result)
+		}
+	}
+}
+
+func TestTransposeChordsDetectsGermanNotation(t *testing.T) {
+	result := TransposeChords("[H]Pan [e]blisko [B]jest", 2)
+
+	if result != "[C#]Pan [f#]blisko [C]jest" {
+		t.Errorf("Unexpected result: %q", result)
+	}
+}
+
+func TestIsChord(t *testing.T) {
+	testCases := []struct {
+		token    string
+		expected bool
+	}{
+		{"[G]", true},
+		{"[c]", true},
+		{"[D/F#]", true},
+		{"[ref]", false},
+		{"[v1]", false},
+		{"[G] Pan", false},
+	}
+
+	for _, tc := range testCases {
+		if result := IsChord(tc.token); result != tc.expected {
+			t.Errorf("IsChord(%q) = %v, expected %v", tc.token, result, tc.expected)
+		}
+	}
+}
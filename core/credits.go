@@ -0,0 +1,109 @@
+package core
+
+import (
+	"strings"
+)
+
+const CreditsAll = "all"
+const CreditsLast = "last"
+
+// CreditsLine names the song along with its copyright and licence, for the footer
+// of its slides. Only the songs with a copyright or a CCLI number need one.
+func (c PageConfig) CreditsLine(info ItemInfo) string {
+	if info.Copyright == "" && info.CCLINumber == "" {
+		return ""
+	}
+
+	parts := make([]string, 0)
+	for _, part := range []string{info.Title, info.Author} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	if info.Copyright != "" {
+		parts = append(parts, "© "+info.Copyright)
+	}
+
+	if info.CCLINumber != "" {
+		parts = append(parts, "CCLI Song # "+info.CCLINumber)
+		if c.CCLILicense != "" {
+			parts = append(parts, "CCLI License # "+c.CCLILicense)
+		}
+	}
+
+	return StripEmphasis(strings.Join(parts, " · "))
+}
+
+// CreditsHeight is the space taken by the lines of the credits at the bottom of a slide.
+func (c PageConfig) CreditsHeight(lines []string) float64 {
+	if len(lines) == 0 {
+		return 0
+	}
+
+	return float64(len(lines))*c.LineHeightAt(c.CreditsFontSize) + c.Margin
+}
+
+// breakPlainText breaks text without any markup into lines set in the given font size.
+func breakPlainText(text string, pageConfig PageConfig, measure Measurer, fontSize int, width float64) []string {
+	lines := BreakLongLines([]string{text}, scaleMeasurer(measure, pageConfig.FontSize, fontSize), width)
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(strings.TrimSuffix(line, LineEndMark), " ")
+	}
+
+	return lines
+}
+
+func creditsLines(info ItemInfo, pageConfig PageConfig, measure Measurer) []string {
+	if pageConfig.Credits != CreditsAll && pageConfig.Credits != CreditsLast {
+		return nil
+	}
+
+	text := pageConfig.CreditsLine(info)
+	if text == "" {
+		return nil
+	}
+
+	return breakPlainText(text, pageConfig, measure, pageConfig.CreditsFontSize, pageConfig.ContentWidth())
+}
+
+// titleSlide shows the title of a song in bold, with its subtitle and author
+// in the hint font size below.
+func titleSlide(itemIndex int, info ItemInfo, pageConfig PageConfig, measure Measurer) Slide {
+	title := boldMark + StripEmphasis(info.Title) + boldMark
+	lines, responses := breakVerse([]string{title}, pageConfig, measure, pageConfig.FontSize, pageConfig.ContentWidth())
+
+	details := make([]string, 0)
+	for _, detail := range []string{info.Subtitle, info.Author} {
+		if detail != "" {
+			details = append(details, breakPlainText(StripEmphasis(detail), pageConfig, measure, pageConfig.HintFontSize, pageConfig.ContentWidth())...)
+		}
+	}
+
+	return Slide{
+		Type:      "title",
+		ItemIndex: itemIndex,
+		Lines:     toSlideLines(lines, responses, false),
+		Text:      strings.Join(details, "\n"),
+		FontSize:  pageConfig.FontSize,
+	}
+}
+
+// TitleDetails returns the lines of the subtitle and the author of a title slide.
+func (s Slide) TitleDetails() []string {
+	if s.Text == "" {
+		return nil
+	}
+
+	return strings.Split(s.Text, "\n")
+}
+
+// TitleHeight is the height of the title along with its details.
+func (c PageConfig) TitleHeight(slide Slide) float64 {
+	return c.LinesHeight(slide.Lines, slide.FontSize) + float64(len(slide.TitleDetails()))*c.LineHeightAt(c.HintFontSize)
+}
+
+// ContentHeight is the height of the slide that is left for the verse above the credits.
+func (c PageConfig) ContentHeight(slide Slide) float64 {
+	return c.PageHeight - c.CreditsHeight(slide.Credits)
+}
@@ -0,0 +1,99 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func creditsTestItems() []ItemInfo {
+	return []ItemInfo{{
+		Title:      "Barka",
+		Author:     "Stanisław Szmidt",
+		Copyright:  "Edycja Świętego Pawła",
+		CCLINumber: "1234567",
+	}}
+}
+
+func TestCreditsLine(t *testing.T) {
+	pageConfig := PageConfig{CCLILicense: "7654321"}
+
+	result := pageConfig.CreditsLine(creditsTestItems()[0])
+	expected := "Barka · Stanisław Szmidt · © Edycja Świętego Pawła · CCLI Song # 1234567 · CCLI License # 7654321"
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+
+	result = pageConfig.CreditsLine(ItemInfo{Title: "Psalm responsoryjny"})
+	if result != "" {
+		t.Errorf("Expected no credits for an item without a copyright, got %q", result)
+	}
+}
+
+func TestLayoutDeckAddsTitleSlide(t *testing.T) {
+	pageConfig := fitTestPageConfig(FitSplit)
+	pageConfig.TitleSlides = true
+	pageConfig.HintFontSize = 10
+	textDeck := [][]string{{HintStartTag + "Bar" + HintEndTag, "Pan kiedyś stanął nad brzegiem"}}
+
+	slides := LayoutDeck(textDeck, creditsTestItems(), pageConfig, measureLength)
+
+	types := make([]string, len(slides))
+	for i, slide := range slides {
+		types[i] = slide.Type
+	}
+	expectedTypes := []string{"blank", "hint", "title", "verse", "blank"}
+	if !reflect.DeepEqual(types, expectedTypes) {
+		t.Fatalf("Expected slides %v, got %v", expectedTypes, types)
+	}
+
+	title := slides[2]
+	if len(title.Lines) != 1 || title.Lines[0].Text != "**Barka**" {
+		t.Errorf("Expected a bold title, got %v", title.Lines)
+	}
+	if !reflect.DeepEqual(title.TitleDetails(), []string{"Stanisław Szmidt"}) {
+		t.Errorf("Expected the author below the title, got %v", title.TitleDetails())
+	}
+}
+
+func TestLayoutDeckAddsCredits(t *testing.T) {
+	testCases := []struct {
+		mode     string
+		expected []bool
+	}{
+		{"", []bool{false, false}},
+		{CreditsAll, []bool{true, true}},
+		{CreditsLast, []bool{false, true}},
+	}
+
+	for _, tc := range testCases {
+		pageConfig := fitTestPageConfig(FitSplit)
+		pageConfig.PageWidth = 10000
+		pageConfig.Credits = tc.mode
+		pageConfig.CreditsFontSize = 10
+
+		slides := verseSlides(LayoutDeck(fitTestDeck(), creditsTestItems(), pageConfig, measureLength))
+		if len(slides) != len(tc.expected) {
+			t.Fatalf("Mode %q: expected %d slides, got %d", tc.mode, len(tc.expected), len(slides))
+		}
+
+		for i, slide := range slides {
+			if (len(slide.Credits) > 0) != tc.expected[i] {
+				t.Errorf("Mode %q: expected credits on slide %d to be %v, got %v", tc.mode, i, tc.expected[i], slide.Credits)
+			}
+		}
+	}
+}
+
+func TestLayoutDeckLeavesRoomForCredits(t *testing.T) {
+	pageConfig := fitTestPageConfig(FitSplit)
+	pageConfig.PageWidth = 10000
+	pageConfig.Credits = CreditsAll
+	pageConfig.CreditsFontSize = 10
+
+	slides := verseSlides(LayoutDeck(fitTestDeck(), creditsTestItems(), pageConfig, measureLength))
+	for _, slide := range slides {
+		if height := pageConfig.LinesHeight(slide.Lines, slide.FontSize); height > pageConfig.ContentHeight(slide) {
+			t.Errorf("Expected the verse to fit above the credits, it takes %v of %v", height, pageConfig.ContentHeight(slide))
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package core
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const boldMark = "**"
+const italicMark = "*"
+
+// TextRun is a fragment of a line set in a single font style.
+type TextRun struct {
+	Text   string
+	Bold   bool
+	Italic bool
+}
+
+func (r TextRun) hasStyle(other TextRun) bool {
+	return r.Bold == other.Bold && r.Italic == other.Italic
+}
+
+// appendRun merges the run with the previous one of the same style,
+// also across the spaces between them.
+func appendRun(runs []TextRun, run TextRun) []TextRun {
+	n := len(runs)
+	if n > 0 && runs[n-1].hasStyle(run) {
+		runs[n-1].Text += run.Text
+		return runs
+	}
+
+	if n > 1 && runs[n-2].hasStyle(run) && strings.Trim(runs[n-1].Text, " ") == "" {
+		runs[n-2].Text += runs[n-1].Text + run.Text
+		return runs[:n-1]
+	}
+
+	return append(runs, run)
+}
+
+// isEmphasisMark tells whether the asterisks at the index open or close a style.
+// A mark opens before a word and closes after one, otherwise it's plain text,
+// like a lone asterisk between the words.
+func isEmphasisMark(line string, index int, mark string, style TextRun) bool {
+	closes := (mark == boldMark && style.Bold) || (mark == italicMark && style.Italic)
+	if closes {
+		previous, size := utf8.DecodeLastRuneInString(line[:index])
+		return size > 0 && !unicode.IsSpace(previous)
+	}
+
+	next, size := utf8.DecodeRuneInString(line[index+len(mark):])
+	return size > 0 && !unicode.IsSpace(next)
+}
+
+func parseEmphasis(line string, style TextRun) ([]TextRun, TextRun) {
+	runs := make([]TextRun, 0)
+	current := style
+
+	for i := 0; i < len(line); {
+		mark := ""
+		if strings.HasPrefix(line[i:], boldMark) {
+			mark = boldMark
+		} else if strings.HasPrefix(line[i:], italicMark) {
+			mark = italicMark
+		}
+
+		if mark == "" {
+			next := strings.Index(line[i:], italicMark)
+			if next < 0 {
+				next = len(line) - i
+			}
+			current.Text += line[i : i+next]
+			i += next
+			continue
+		}
+
+		if !isEmphasisMark(line, i, mark, current) {
+			current.Text += mark
+			i += len(mark)
+			continue
+		}
+
+		if current.Text != "" {
+			runs = appendRun(runs, current)
+		}
+		current = TextRun{
+			Bold:   current.Bold != (mark == boldMark),
+			Italic: current.Italic != (mark == italicMark),
+		}
+		i += len(mark)
+	}
+
+	if current.Text != "" {
+		runs = appendRun(runs, current)
+	}
+
+	return runs, TextRun{Bold: current.Bold, Italic: current.Italic}
+}
+
+// ParseEmphasis splits a line into runs of **bold**, *italic* and regular text.
+// A mark that isn't closed lasts until the end of the line.
+func ParseEmphasis(line string) []TextRun {
+	runs, _ := parseEmphasis(line, TextRun{})
+	return runs
+}
+
+// StripEmphasis removes the emphasis marks, for outputs that only have plain text.
+// The asterisks that aren't marks are kept.
+func StripEmphasis(text string) string {
+	if !strings.Contains(text, italicMark) {
+		return text
+	}
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		plainLine := ""
+		for _, run := range ParseEmphasis(line) {
+			plainLine += run.Text
+		}
+		lines[i] = plainLine
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func emphasisMarks(bold bool, italic bool) string {
+	marks := ""
+	if bold {
+		marks += boldMark
+	}
+	if italic {
+		marks += italicMark
+	}
+
+	return marks
+}
+
+// spreadEmphasis closes and reopens the marks around every word,
+// so that each part of a broken line keeps its style.
+func spreadEmphasis(line string) string {
+	if !strings.Contains(line, italicMark) {
+		return line
+	}
+
+	result := ""
+	style := TextRun{}
+	for _, run := range ParseEmphasis(line) {
+		for i, word := range strings.Split(run.Text, " ") {
+			if i > 0 {
+				result += emphasisMarks(style.Bold, style.Italic) + " "
+				style = TextRun{}
+			}
+			if word == "" {
+				continue
+			}
+
+			result += emphasisMarks(style.Bold != run.Bold, style.Italic != run.Italic) + word
+			style = TextRun{Bold: run.Bold, Italic: run.Italic}
+		}
+	}
+
+	return result + emphasisMarks(style.Bold, style.Italic)
+}
+
+// balanceEmphasis closes the marks left open at the end of a line and reopens
+// them on the next one. Hyphenation may split a word along with its marks.
+func balanceEmphasis(lines []string) []string {
+	result := make([]string, len(lines))
+	style := TextRun{}
+
+	for i, line := range lines {
+		text, isLineEnd := strings.CutSuffix(line, LineEndMark)
+		text = emphasisMarks(style.Bold, style.Italic) + text
+		_, style = parseEmphasis(text, TextRun{})
+
+		result[i] = text + emphasisMarks(style.Bold, style.Italic)
+		if isLineEnd {
+			result[i] += LineEndMark
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,99 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseEmphasis(t *testing.T) {
+	testCases := []struct {
+		line     string
+		expected []TextRun
+	}{
+		{"Pan blisko jest", []TextRun{{Text: "Pan blisko jest"}}},
+		{"Pan *blisko* jest", []TextRun{{Text: "Pan "}, {Text: "blisko", Italic: true}, {Text: " jest"}}},
+		{"**Pan** blisko", []TextRun{{Text: "Pan", Bold: true}, {Text: " blisko"}}},
+		{"***Pan*** blisko", []TextRun{{Text: "Pan", Bold: true, Italic: true}, {Text: " blisko"}}},
+		{"Pan *blisko jest", []TextRun{{Text: "Pan "}, {Text: "blisko jest", Italic: true}}},
+		{"*Pan* *blisko*", []TextRun{{Text: "Pan blisko", Italic: true}}},
+		{"Pan * blisko", []TextRun{{Text: "Pan * blisko"}}},
+		{"Alleluja* i *Pan*", []TextRun{{Text: "Alleluja* i "}, {Text: "Pan", Italic: true}}},
+		{"", []TextRun{}},
+	}
+
+	for _, tc := range testCases {
+		result := ParseEmphasis(tc.line)
+		if !reflect.DeepEqual(result, tc.expected) {
+			t.Errorf("Parsing %q: expected %v, got %v", tc.line, tc.expected, result)
+		}
+	}
+}
+
+func TestStripEmphasis(t *testing.T) {
+	testCases := []struct {
+		text     string
+		expected string
+	}{
+		{"**Pan** *blisko* jest", "Pan blisko jest"},
+		{"Pan *blisko*\njest **z nami**", "Pan blisko\njest z nami"},
+		{"Alleluja *\n2 * 3", "Alleluja *\n2 * 3"},
+	}
+
+	for _, tc := range testCases {
+		result := StripEmphasis(tc.text)
+		if result != tc.expected {
+			t.Errorf("Stripping %q: expected %q, got %q", tc.text, tc.expected, result)
+		}
+	}
+}
+
+func TestSpreadEmphasis(t *testing.T) {
+	testCases := []struct {
+		line     string
+		expected string
+	}{
+		{"Pan blisko jest", "Pan blisko jest"},
+		{"*Pan blisko* jest", "*Pan* *blisko* jest"},
+		{"**Pan *blisko*** jest", "**Pan** ***blisko*** jest"},
+		{"**Bo**że", "**Bo**że"},
+	}
+
+	for _, tc := range testCases {
+		result := spreadEmphasis(tc.line)
+		if result != tc.expected {
+			t.Errorf("Spreading %q: expected %q, got %q", tc.line, tc.expected, result)
+		}
+	}
+}
+
+func TestBalanceEmphasis(t *testing.T) {
+	lines := []string{"Pan *Bet-", "le-", "jem*" + LineEndMark}
+	expected := []string{"Pan *Bet-*", "*le-*", "*jem*" + LineEndMark}
+
+	result := balanceEmphasis(lines)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestLayoutDeckKeepsEmphasisOnBrokenLines(t *testing.T) {
+	pageConfig := PageConfig{
+		PageWidth:   20,
+		PageHeight:  1000,
+		FontSize:    20,
+		LineSpacing: 1.25,
+	}
+	textDeck := [][]string{{"*aaaa bbbb cccc dddd*"}}
+
+	slides := verseSlides(LayoutDeck(textDeck, nil, pageConfig, measureLength))
+	if len(slides) != 1 {
+		t.Fatalf("Expected 1 slide, got %d", len(slides))
+	}
+
+	for _, line := range slides[0].Lines {
+		runs := ParseEmphasis(line.Text)
+		if len(runs) != 1 || !runs[0].Italic {
+			t.Errorf("Expected the line %q to be italic", line.Text)
+		}
+	}
+}
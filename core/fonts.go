@@ -0,0 +1,133 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/signintech/gopdf"
+	fontmaker "github.com/signintech/gopdf/fontmaker/core"
+	"golang.org/x/image/font/sfnt"
+)
+
+// RequiredGlyphs are the characters that every font has to cover, the lyrics are mostly in Polish.
+const RequiredGlyphs = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789.,;:!?-–()\"'ĄĆĘŁŃÓŚŹŻąćęłńóśźż"
+
+func addFont(goPdf *gopdf.GoPdf, family string, data []byte) error {
+	return goPdf.AddTTFFontByReader(family, bytes.NewReader(data))
+}
+
+// addFonts registers the regular font along with the bold and italic ones
+// that are configured.
+func addFonts(goPdf *gopdf.GoPdf, pageConfig PageConfig) error {
+	fonts := map[string][]byte{
+		"default": pageConfig.Font,
+		"bold":    pageConfig.BoldFont,
+		"italic":  pageConfig.ItalicFont,
+	}
+
+	for family, data := range fonts {
+		if len(data) == 0 {
+			continue
+		}
+
+		err := addFont(goPdf, family, data)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fontAscent is the typographic ascender of a font per point of the font size.
+// gopdf places the text this far below the top edge of its cell.
+func fontAscent(data []byte) (float64, error) {
+	var parser fontmaker.TTFParser
+	err := parser.ParseByReader(bytes.NewReader(data))
+	if err != nil {
+		return 0, err
+	}
+
+	return float64(parser.TypoAscender()) / float64(parser.UnitsPerEm()), nil
+}
+
+// sfntFont is a parsed font, for the outputs that draw the glyphs themselves.
+type sfntFont struct {
+	font       *sfnt.Font
+	unitsPerEm float64
+	ascent     float64
+}
+
+// parseFonts parses the regular font along with the bold and italic ones that are configured.
+func parseFonts(pageConfig PageConfig) (map[string]sfntFont, error) {
+	fonts := make(map[string]sfntFont)
+	for family, data := range map[string][]byte{
+		"default": pageConfig.Font,
+		"bold":    pageConfig.BoldFont,
+		"italic":  pageConfig.ItalicFont,
+	} {
+		if len(data) == 0 {
+			continue
+		}
+
+		parsed, err := sfnt.Parse(data)
+		if err != nil {
+			return nil, err
+		}
+
+		ascent, err := fontAscent(data)
+		if err != nil {
+			return nil, err
+		}
+
+		fonts[family] = sfntFont{
+			font:       parsed,
+			unitsPerEm: float64(parsed.UnitsPerEm()),
+			ascent:     ascent,
+		}
+	}
+
+	return fonts, nil
+}
+
+// CheckFont makes sure that a TrueType font can be used on the slides and returns
+// its family name. The fonts with CFF outlines, like most .otf files, are rejected,
+// since the PDFs can't embed them.
+func CheckFont(data []byte) (string, error) {
+	if bytes.HasPrefix(data, []byte("OTTO")) {
+		return "", errors.New("fonts with CFF outlines are not supported, use a TrueType font")
+	}
+
+	font, err := sfnt.Parse(data)
+	if err != nil {
+		return "", errors.New("not a TrueType font")
+	}
+
+	var buf sfnt.Buffer
+	missing := make([]string, 0)
+	for _, r := range RequiredGlyphs {
+		index, err := font.GlyphIndex(&buf, r)
+		if err != nil || index == 0 {
+			missing = append(missing, string(r))
+		}
+	}
+
+	if len(missing) > 0 {
+		return "", fmt.Errorf("the font lacks characters: %s", strings.Join(missing, " "))
+	}
+
+	goPdf := &gopdf.GoPdf{}
+	goPdf.Start(gopdf.Config{PageSize: *gopdf.PageSizeA4})
+	if err := addFont(goPdf, "default", data); err != nil {
+		return "", errors.New("only TrueType fonts are supported")
+	}
+
+	family, err := font.Name(&buf, sfnt.NameIDFamily)
+	if err != nil || family == "" {
+		return "", errors.New("the font has no family name")
+	}
+
+	return family, nil
+}
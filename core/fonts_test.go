@@ -0,0 +1,30 @@
+package core
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCheckFont(t *testing.T) {
+	data, err := os.ReadFile("../fonts/source-sans-pro.ttf")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	family, err := CheckFont(data)
+	if err != nil {
+		t.Fatalf("Expected the bundled font to be accepted, got %v", err)
+	}
+	if family != "Source Sans Pro" {
+		t.Errorf("Expected the family name, got %q", family)
+	}
+
+	if _, err := CheckFont([]byte("not a font")); err == nil {
+		t.Error("Expected an error for invalid data")
+	}
+
+	if _, err := CheckFont(append([]byte("OTTO"), data[4:]...)); err == nil || !strings.Contains(err.Error(), "CFF") {
+		t.Errorf("Expected an error for a font with CFF outlines, got %v", err)
+	}
+}
@@ -0,0 +1,50 @@
+package core
+
+// what the hint shows
+const HintLetters = "letters"
+const HintTitle = "title"
+const HintFirstLine = "firstLine"
+const HintNumber = "number"
+
+// where the hint is shown
+const HintSlide = "slide"
+const HintOverlay = "overlay"
+
+// hintInsetScale keeps the hint 10pt from the edges of a page of the default size.
+const hintInsetScale = 10.0 / 432
+
+// hintInset is the distance of the hint from the edges of the slide.
+func (c PageConfig) hintInset() float64 {
+	return min(c.PageWidth, c.PageHeight) * hintInsetScale
+}
+
+func (c PageConfig) hintWidth() float64 {
+	return c.PageWidth - 2*c.hintInset()
+}
+
+// HintFontSizeOf is the font size of the hint of a slide,
+// the overlays are smaller than the hints on their own slides.
+func (c PageConfig) HintFontSizeOf(slide Slide) int {
+	if slide.Type == "hint" {
+		return c.HintFontSize
+	}
+
+	return c.HintOverlayFontSize
+}
+
+// HintPosition is the top left corner of the hint of a slide. A hint slide
+// shows it in the bottom left corner, an overlay in the top left one.
+func (c PageConfig) HintPosition(slide Slide) (float64, float64) {
+	inset := c.hintInset()
+	if slide.Type != "hint" {
+		return inset, inset
+	}
+
+	fontSize := c.HintFontSize
+	y := c.PageHeight - float64(fontSize) - inset - float64(len(slide.Hint)-1)*c.LineHeightAt(fontSize)
+	return inset, y
+}
+
+func hintLines(hint string, pageConfig PageConfig, measure Measurer, fontSize int) []string {
+	return breakPlainText(StripEmphasis(hint), pageConfig, measure, fontSize, pageConfig.hintWidth())
+}
@@ -0,0 +1,68 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func hintTestDeck() [][]string {
+	return [][]string{{HintStartTag + "Pan kiedyś stanął" + HintEndTag, "Pan kiedyś stanął nad brzegiem", "Ty wiesz, że ubogi"}}
+}
+
+func TestLayoutDeckShowsHintSlide(t *testing.T) {
+	pageConfig := fitTestPageConfig(FitSplit)
+	pageConfig.HintFontSize = 10
+
+	slides := LayoutDeck(hintTestDeck(), nil, pageConfig, measureLength)
+	if len(slides) < 2 || slides[1].Type != "hint" {
+		t.Fatalf("Expected a hint slide after the first blank one, got %v", slides)
+	}
+
+	if !reflect.DeepEqual(slides[1].Hint, []string{"Pan kiedyś stanął"}) {
+		t.Errorf("Expected the hint lines, got %v", slides[1].Hint)
+	}
+}
+
+func TestLayoutDeckOverlaysHint(t *testing.T) {
+	pageConfig := fitTestPageConfig(FitSplit)
+	pageConfig.HintPlacement = HintOverlay
+	pageConfig.HintOverlayFontSize = 10
+
+	slides := LayoutDeck(hintTestDeck(), nil, pageConfig, measureLength)
+	for _, slide := range slides {
+		if slide.Type == "hint" {
+			t.Fatalf("Expected no hint slide, got %v", slides)
+		}
+	}
+
+	verses := verseSlides(slides)
+	if !reflect.DeepEqual(verses[0].Hint, []string{"Pan kiedyś stanął"}) {
+		t.Errorf("Expected the hint on the first verse, got %v", verses[0].Hint)
+	}
+	if len(verses[1].Hint) > 0 {
+		t.Errorf("Expected no hint on the second verse, got %v", verses[1].Hint)
+	}
+}
+
+func TestHintPosition(t *testing.T) {
+	pageConfig := PageConfig{PageWidth: 768, PageHeight: 432, HintFontSize: 20, LineSpacing: 1.5}
+
+	x, y := pageConfig.HintPosition(Slide{Type: "hint", Hint: []string{"Pan", "kiedyś"}})
+	if x != 10 || y != 372 {
+		t.Errorf("Expected the hint lines to end at the bottom, got (%v, %v)", x, y)
+	}
+
+	x, y = pageConfig.HintPosition(Slide{Type: "verse", Hint: []string{"Pan"}})
+	if x != 10 || y != 10 {
+		t.Errorf("Expected the overlay in the top corner, got (%v, %v)", x, y)
+	}
+}
+
+func TestHintPositionScalesWithPage(t *testing.T) {
+	pageConfig := PageConfig{PageWidth: 216, PageHeight: 864}
+
+	x, y := pageConfig.HintPosition(Slide{Type: "verse", Hint: []string{"Pan"}})
+	if x != 5 || y != 5 {
+		t.Errorf("Expected the overlay closer to the corner of a narrow page, got (%v, %v)", x, y)
+	}
+}
@@ -0,0 +1,336 @@
+package core
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/skip2/go-qrcode"
+)
+
+const htmlHead = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="generator" content="%s">
+<title>%s</title>
+<style>
+%s
+html, body { margin: 0; height: 100%%; overflow: hidden; background: #000; }
+body { display: grid; place-items: center; }
+#stage { position: relative; flex: none; width: %gpx; height: %gpx; overflow: hidden; cursor: pointer; }
+section { position: absolute; inset: 0; overflow: hidden; font-family: deck; white-space: nowrap; background-size: 100%% 100%%; }
+section[hidden] { background: none !important; }
+section div { position: absolute; }
+section img { position: absolute; }
+.bold { font-family: deck-bold; }
+.italic { font-family: deck-italic; }
+.justify { white-space: normal; text-align: justify !important; text-align-last: justify; }
+</style>
+</head>
+<body>
+<main id="stage" aria-roledescription="slideshow">
+`
+
+// htmlScript switches the slides with the keyboard or a click, and scales the stage to the window.
+// The hidden slides stay searchable, and finding text in one of them shows it.
+const htmlScript = `</main>
+<script>
+(function () {
+  var stage = document.getElementById("stage");
+  var slides = stage.querySelectorAll("section");
+  var current = 0;
+
+  function show(index) {
+    index = Math.max(0, Math.min(slides.length - 1, index));
+    slides[current].setAttribute("hidden", "until-found");
+    slides[index].removeAttribute("hidden");
+    current = index;
+    history.replaceState(null, "", "#" + (index + 1));
+  }
+
+  function fit() {
+    var scale = Math.min(window.innerWidth / stage.offsetWidth, window.innerHeight / stage.offsetHeight);
+    stage.style.transform = "scale(" + scale + ")";
+  }
+
+  document.addEventListener("keydown", function (event) {
+    if (event.ctrlKey || event.metaKey || event.altKey) {
+      return;
+    }
+
+    switch (event.key) {
+    case "ArrowRight": case "ArrowDown": case "PageDown": case " ": case "Enter":
+      show(current + 1);
+      break;
+    case "ArrowLeft": case "ArrowUp": case "PageUp": case "Backspace":
+      show(current - 1);
+      break;
+    case "Home":
+      show(0);
+      break;
+    case "End":
+      show(slides.length - 1);
+      break;
+    case "f":
+      if (document.fullscreenElement) {
+        document.exitFullscreen();
+      } else {
+        document.documentElement.requestFullscreen();
+      }
+      break;
+    default:
+      return;
+    }
+    event.preventDefault();
+  });
+
+  stage.addEventListener("click", function () { show(current + 1); });
+  slides.forEach(function (slide, index) {
+    slide.addEventListener("beforematch", function () { show(index); });
+  });
+  window.addEventListener("resize", fit);
+
+  fit();
+  show((parseInt(location.hash.slice(1), 10) || 1) - 1);
+})();
+</script>
+</body>
+</html>
+`
+
+// htmlWriter writes the slides as positioned lines of text, using the same
+// layout and positions in points as the PDF, with one CSS pixel per point.
+type htmlWriter struct {
+	pageConfig PageConfig
+	slides     strings.Builder
+	// every distinct image is written once as a CSS class, which the slides refer to
+	images map[string]string
+	styles strings.Builder
+}
+
+func (w *htmlWriter) Initialize(pageConfig PageConfig) {
+	w.pageConfig = pageConfig
+	w.images = make(map[string]string)
+
+	fonts := []struct {
+		family string
+		data   []byte
+	}{
+		{"deck", pageConfig.Font},
+		{"deck-bold", pageConfig.BoldFont},
+		{"deck-italic", pageConfig.ItalicFont},
+	}
+
+	for _, font := range fonts {
+		if len(font.data) == 0 {
+			continue
+		}
+		fmt.Fprintf(&w.styles, "@font-face { font-family: %s; src: url(data:font/ttf;base64,%s); }\n", font.family, base64.StdEncoding.EncodeToString(font.data))
+	}
+}
+
+func dataURL(data []byte) string {
+	return "data:" + http.DetectContentType(data) + ";base64," + base64.StdEncoding.EncodeToString(data)
+}
+
+func cssColor(c Color) string {
+	return "#" + c.Hex()
+}
+
+// cssLength rounds a length in points to a hundredth of a CSS pixel.
+func cssLength(points float64) string {
+	return strconv.FormatFloat(math.Round(points*100)/100, 'f', -1, 64) + "px"
+}
+
+func classAttribute(class string) string {
+	if class == "" {
+		return ""
+	}
+	return ` class="` + class + `"`
+}
+
+// imageClass returns the CSS class with the image as its background.
+func (w *htmlWriter) imageClass(data []byte) string {
+	key := string(data)
+	if class, ok := w.images[key]; ok {
+		return class
+	}
+
+	class := fmt.Sprintf("image%d", len(w.images)+1)
+	fmt.Fprintf(&w.styles, ".%s { background-image: url(%s); }\n", class, dataURL(data))
+	w.images[key] = class
+	return class
+}
+
+// sectionStyle sets the colors and the text effects, which the lines inherit.
+func (w *htmlWriter) sectionStyle() string {
+	style := "color: " + cssColor(w.pageConfig.TextColor) + ";"
+	if len(w.pageConfig.BackgroundImage) == 0 {
+		style += " background-color: " + cssColor(w.pageConfig.BackgroundColor) + ";"
+	}
+
+	if outline := w.pageConfig.TextOutline; outline != nil && outline.Width > 0 {
+		// the stroke is centered on the outline of the glyphs and painted below the fill,
+		// so it's twice as wide as the visible outline
+		style += fmt.Sprintf(" -webkit-text-stroke: %s %s; paint-order: stroke fill;", cssLength(2*outline.Width), cssColor(outline.Color))
+	}
+
+	if shadow := w.pageConfig.TextShadow; shadow != nil {
+		c := shadow.Color
+		style += fmt.Sprintf(" text-shadow: %s %s %s rgba(%d, %d, %d, %g);", cssLength(shadow.Offset), cssLength(shadow.Offset), cssLength(shadow.Blur), c.R, c.G, c.B, shadow.Opacity)
+	}
+
+	return style
+}
+
+func (w *htmlWriter) writeRuns(runs []TextRun) {
+	for _, run := range runs {
+		text := html.EscapeString(run.Text)
+		if family := w.pageConfig.fontFamily(run); family != "default" {
+			fmt.Fprintf(&w.slides, `<span class="%s">%s</span>`, family, text)
+		} else {
+			w.slides.WriteString(text)
+		}
+	}
+}
+
+// writeLine writes a line in a box of the given position and width, which is as tall
+// as the line, so that the text sits in the middle of the line spacing like in the PDF.
+func (w *htmlWriter) writeLine(runs []TextRun, x float64, y float64, width float64, fontSize int, class string, style string) {
+	lineHeight := w.pageConfig.LineHeightAt(fontSize)
+	fmt.Fprintf(&w.slides, `<div%s style="left: %s; top: %s; width: %s; font-size: %dpx; line-height: %s; %s">`, classAttribute(class), cssLength(x), cssLength(y), cssLength(width), fontSize, cssLength(lineHeight), style)
+	w.writeRuns(runs)
+	w.slides.WriteString("</div>\n")
+}
+
+func (w *htmlWriter) writeVerseLine(line SlideLine, y float64, fontSize int, left float64, width float64) error {
+	color := w.pageConfig.TextColor
+	if line.Translation {
+		color = w.pageConfig.Translation.Color
+	}
+
+	indent := 0.0
+	if line.Response {
+		color = w.pageConfig.ResponseColor
+		indent = w.pageConfig.ResponseIndentAt(fontSize)
+	}
+
+	class := ""
+	style := "color: " + cssColor(color) + ";"
+	switch w.pageConfig.HorizontalAlign {
+	case AlignLeft:
+		style += " text-align: left; padding-left: " + cssLength(indent) + ";"
+		width -= indent
+	case AlignRight:
+		style += " text-align: right;"
+	case AlignJustify:
+		style += " text-align: left; padding-left: " + cssLength(indent) + ";"
+		width -= indent
+		if !line.ParagraphEnd && strings.Contains(line.Text, " ") {
+			class = "justify"
+		}
+	default:
+		style += " text-align: center;"
+	}
+
+	w.writeLine(ParseEmphasis(line.Text), left, y, width, fontSize, class, style)
+	return nil
+}
+
+func (w *htmlWriter) writeCenteredRuns(runs []TextRun, y float64, fontSize int, color Color) error {
+	w.writeLine(runs, 0, y, w.pageConfig.PageWidth, fontSize, "", "text-align: center; color: "+cssColor(color)+";")
+	return nil
+}
+
+// writeHintLine writes a line of the hint with its top edge at y, like the PDF cell.
+func (w *htmlWriter) writeHintLine(text string, x float64, y float64, fontSize int, color Color) error {
+	fmt.Fprintf(&w.slides, `<div style="left: %s; top: %s; font-size: %dpx; line-height: 1; color: %s;">%s</div>`+"\n", cssLength(x), cssLength(y), fontSize, cssColor(color), html.EscapeString(text))
+	return nil
+}
+
+func (w *htmlWriter) writeQrCode(content string) error {
+	qrSize := w.pageConfig.qrCodeSize()
+	qr, err := qrcode.Encode(content, qrcode.Medium, 2*qrCodeResolution)
+	if err != nil {
+		return err
+	}
+
+	x := (w.pageConfig.PageWidth - qrSize) / 2
+	y := (w.pageConfig.PageHeight - qrSize) / 2
+	fmt.Fprintf(&w.slides, `<img src="%s" alt="%s" style="left: %s; top: %s; width: %s; height: %s;">`+"\n", dataURL(qr), html.EscapeString(content), cssLength(x), cssLength(y), cssLength(qrSize), cssLength(qrSize))
+
+	fontSize := w.pageConfig.FontSize
+	fmt.Fprintf(&w.slides, `<div aria-hidden="true" style="left: 0; top: %s; width: %s; font-size: %dpx; line-height: 1; text-align: center;">%s</div>`+"\n", cssLength(w.pageConfig.PageHeight-y+(y-float64(fontSize))/2), cssLength(w.pageConfig.PageWidth), fontSize, html.EscapeString(content))
+	return nil
+}
+
+func (w *htmlWriter) writeLogo() error {
+	if len(w.pageConfig.Logo) == 0 {
+		return nil
+	}
+
+	width, height, err := fitImage(w.pageConfig.Logo, w.pageConfig.PageWidth/2, w.pageConfig.PageHeight/3)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(&w.slides, `<div class="%s" aria-hidden="true" style="left: %s; top: %s; width: %s; height: %s; background-size: contain;"></div>`+"\n", w.imageClass(w.pageConfig.Logo), cssLength((w.pageConfig.PageWidth-width)/2), cssLength((w.pageConfig.PageHeight-height)/2), cssLength(width), cssLength(height))
+	return nil
+}
+
+func (w *htmlWriter) writeSlide(slide Slide, index int, count int) error {
+	class := ""
+	if len(w.pageConfig.BackgroundImage) > 0 {
+		class = w.imageClass(w.pageConfig.BackgroundImage)
+	}
+
+	fmt.Fprintf(&w.slides, `<section%s style="%s" aria-roledescription="slide" aria-label="%d / %d" hidden="until-found">`+"\n", classAttribute(class), w.sectionStyle(), index+1, count)
+
+	var err error
+	switch slide.Type {
+	case "blank":
+		err = w.writeLogo()
+	case "qr":
+		err = w.writeQrCode(slide.Text)
+	default:
+		err = slideText{w.pageConfig, w}.write(slide)
+	}
+
+	w.slides.WriteString("</section>\n")
+	return err
+}
+
+// BuildHTML writes the deck as a single HTML file which can be presented offline in a browser.
+// The slides are text in the embedded fonts, so they can be searched and read by screen readers.
+func BuildHTML(textDeck [][]string, items []ItemInfo, pageConfig PageConfig) (*bytes.Buffer, []ContentSlide, error) {
+	measure, err := NewFontMeasurer(pageConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	writer := htmlWriter{}
+	writer.Initialize(pageConfig)
+
+	slides := LayoutDeck(textDeck, items, pageConfig, measure)
+	for i, slide := range slides {
+		writer.pageConfig = SlidePageConfig(slide, items, pageConfig)
+		err = writer.writeSlide(slide, i, len(slides))
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, htmlHead, producer, html.EscapeString(pageConfig.DocumentTitle), writer.styles.String(), pageConfig.PageWidth, pageConfig.PageHeight)
+	buf.WriteString(writer.slides.String())
+	buf.WriteString(htmlScript)
+
+	return buf, ContentSlides(slides), nil
+}
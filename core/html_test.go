@@ -0,0 +1,34 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildHTML(t *testing.T) {
+	pageConfig := testPageConfig(t)
+	pageConfig.DocumentTitle = "2024-12-24"
+	textDeck := [][]string{{"Pan kiedyś stanął nad brzegiem", "Szukał ludzi gotowych pójść za Nim & <innymi>"}}
+
+	buf, contents, err := BuildHTML(textDeck, nil, pageConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	document := buf.String()
+	if count := strings.Count(document, "<section "); count != len(contents) {
+		t.Errorf("Expected a section per slide, got %d sections for %d slides", count, len(contents))
+	}
+
+	for _, expected := range []string{
+		"<title>2024-12-24</title>",
+		"@font-face { font-family: deck; src: url(data:font/ttf;base64,",
+		"Pan kiedyś stanął nad brzegiem",
+		"pójść za Nim &amp; &lt;innymi&gt;",
+		"color: #FFFFFF;",
+	} {
+		if !strings.Contains(document, expected) {
+			t.Errorf("Expected the document to contain %q", expected)
+		}
+	}
+}
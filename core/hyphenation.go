@@ -0,0 +1,153 @@
+package core
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Hyphenator finds the places where a word may be hyphenated,
+// using Liang's algorithm (the one known from TeX).
+type Hyphenator struct {
+	patterns      map[string][]int
+	maxPatternLen int
+	vowels        string
+	leftMin       int
+	rightMin      int
+}
+
+// NewHyphenator builds a hyphenator from Liang patterns such as "1ba" or "c2z".
+// When vowels are given, a word is never split into a part without a vowel.
+func NewHyphenator(patterns []string, vowels string) *Hyphenator {
+	h := &Hyphenator{
+		patterns: make(map[string][]int),
+		vowels:   vowels,
+		leftMin:  2,
+		rightMin: 2,
+	}
+
+	for _, pattern := range patterns {
+		letters := make([]rune, 0)
+		levels := []int{0}
+		for _, r := range pattern {
+			if r >= '0' && r <= '9' {
+				levels[len(levels)-1] = int(r - '0')
+			} else {
+				letters = append(letters, r)
+				levels = append(levels, 0)
+			}
+		}
+
+		h.patterns[string(letters)] = levels
+		h.maxPatternLen = max(h.maxPatternLen, len(letters))
+	}
+
+	return h
+}
+
+var hyphenators = map[string]*Hyphenator{}
+
+func RegisterHyphenator(language string, hyphenator *Hyphenator) {
+	hyphenators[language] = hyphenator
+}
+
+func GetHyphenator(language string) (*Hyphenator, bool) {
+	hyphenator, ok := hyphenators[language]
+	return hyphenator, ok
+}
+
+func (h *Hyphenator) hasVowel(word []rune) bool {
+	if h.vowels == "" {
+		return true
+	}
+
+	for _, r := range word {
+		if strings.ContainsRune(h.vowels, unicode.ToLower(r)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Points returns the rune offsets at which the word may be hyphenated.
+func (h *Hyphenator) Points(word string) []int {
+	runes := []rune(word)
+	points := make([]int, 0)
+	if len(runes) < h.leftMin+h.rightMin {
+		return points
+	}
+
+	text := []rune("." + strings.ToLower(word) + ".")
+	levels := make([]int, len(text)+1)
+	for start := range text {
+		for end := start + 1; end <= len(text) && end-start <= h.maxPatternLen; end++ {
+			pattern, ok := h.patterns[string(text[start:end])]
+			if !ok {
+				continue
+			}
+
+			for i, level := range pattern {
+				levels[start+i] = max(levels[start+i], level)
+			}
+		}
+	}
+
+	for i := h.leftMin; i <= len(runes)-h.rightMin; i++ {
+		// levels are indexed in the dotted text, shifted by one
+		if levels[i+1]%2 == 1 && h.hasVowel(runes[:i]) && h.hasVowel(runes[i:]) {
+			points = append(points, i)
+		}
+	}
+
+	return points
+}
+
+// Hyphenate splits the word into the parts between its hyphenation points.
+// Punctuation around the word is kept with the first and last part.
+func (h *Hyphenator) Hyphenate(word string) []string {
+	runes := []rune(word)
+	start := 0
+	for start < len(runes) && !unicode.IsLetter(runes[start]) {
+		start++
+	}
+	end := len(runes)
+	for end > start && !unicode.IsLetter(runes[end-1]) {
+		end--
+	}
+
+	parts := make([]string, 0)
+	last := 0
+	for _, point := range h.Points(string(runes[start:end])) {
+		parts = append(parts, string(runes[last:start+point]))
+		last = start + point
+	}
+
+	return append(parts, string(runes[last:]))
+}
+
+const polishVowels = "aąeęioóuy"
+
+var polishConsonants = []string{
+	"b", "c", "ć", "d", "f", "g", "h", "j", "k", "l", "ł", "m", "n", "ń",
+	"p", "r", "s", "ś", "t", "w", "z", "ź", "ż",
+	"ch", "cz", "dz", "dź", "dż", "rz", "sz",
+}
+
+// polishPatterns follow the basic rule of Polish syllabification: a word can
+// be broken before a consonant (or a digraph) that is followed by a vowel.
+// Consonant clusters may be divided anywhere, so no other rules are needed
+// besides keeping the digraphs together.
+func polishPatterns() []string {
+	patterns := []string{"c2h", "c2z", "d2z", "d2ź", "d2ż", "r2z", "s2z"}
+	for _, consonant := range polishConsonants {
+		for _, vowel := range polishVowels {
+			patterns = append(patterns, "1"+consonant+string(vowel))
+		}
+	}
+
+	return patterns
+}
+
+func init() {
+	RegisterHyphenator("pl", NewHyphenator(polishPatterns(), polishVowels))
+}
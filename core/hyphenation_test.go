@@ -0,0 +1,33 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHyphenatePolish(t *testing.T) {
+	hyphenator, ok := GetHyphenator("pl")
+	if !ok {
+		t.Fatal("Expected the Polish hyphenator to be registered")
+	}
+
+	testCases := []struct {
+		word     string
+		expected []string
+	}{
+		{"Betlejem", []string{"Bet", "le", "jem"}},
+		{"wszystko", []string{"wszyst", "ko"}},
+		{"Chrystus", []string{"Chrys", "tus"}},
+		{"szczęście", []string{"szczęś", "cie"}},
+		{"kochamy", []string{"ko", "cha", "my"}},
+		{"zbawić,", []string{"zba", "wić,"}},
+		{"Pan", []string{"Pan"}},
+	}
+
+	for _, tc := range testCases {
+		result := hyphenator.Hyphenate(tc.word)
+		if !reflect.DeepEqual(result, tc.expected) {
+			t.Errorf("Hyphenating %s: expected %v, got %v", tc.word, tc.expected, result)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	_ "image/png"
+
+	"golang.org/x/image/draw"
+)
+
+const BackgroundCover = "cover"
+const BackgroundContain = "contain"
+
+// MaxImagePixels limits the images that are decoded, which takes 4 bytes per pixel.
+const MaxImagePixels = 25_000_000
+
+// PrepareBackgroundImage scales the image to the slide size, either cropping it
+// (cover) or leaving bars in the fill color (contain), darkens it with a black
+// overlay of the given opacity and encodes it as a JPEG.
+func PrepareBackgroundImage(data []byte, width int, height int, fit string, overlay float64, fill Color) ([]byte, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Width*config.Height > MaxImagePixels {
+		return nil, fmt.Errorf("image is too large: %dx%d", config.Width, config.Height)
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.RGBA{fill.R, fill.G, fill.B, 255}), image.Point{}, draw.Src)
+
+	srcWidth := float64(src.Bounds().Dx())
+	srcHeight := float64(src.Bounds().Dy())
+	scale := min(float64(width)/srcWidth, float64(height)/srcHeight)
+	if fit != BackgroundContain {
+		scale = max(float64(width)/srcWidth, float64(height)/srcHeight)
+	}
+
+	scaledWidth := int(srcWidth * scale)
+	scaledHeight := int(srcHeight * scale)
+	x := (width - scaledWidth) / 2
+	y := (height - scaledHeight) / 2
+	draw.CatmullRom.Scale(dst, image.Rect(x, y, x+scaledWidth, y+scaledHeight), src, src.Bounds(), draw.Over, nil)
+
+	if overlay > 0 {
+		overlayColor := color.RGBA{0, 0, 0, uint8(min(overlay, 1) * 255)}
+		draw.Draw(dst, dst.Bounds(), image.NewUniform(overlayColor), image.Point{}, draw.Over)
+	}
+
+	buf := new(bytes.Buffer)
+	err = jpeg.Encode(buf, dst, &jpeg.Options{Quality: 85})
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// fitImage returns the size of an image scaled down to fit in the given box.
+func fitImage(data []byte, maxWidth float64, maxHeight float64) (float64, float64, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	scale := min(maxWidth/float64(config.Width), maxHeight/float64(config.Height), 1)
+	return float64(config.Width) * scale, float64(config.Height) * scale, nil
+}
+
+// imageFormat returns the format of an image, like "jpeg" or "png".
+func imageFormat(data []byte) (string, error) {
+	_, format, err := image.DecodeConfig(bytes.NewReader(data))
+	return format, err
+}
+
+// qrCodeSizeScale makes the QR code 400pt large on a page of the default size.
+const qrCodeSizeScale = 400.0 / 432
+
+// qrCodeResolution is the size in pixels of the QR code images embedded in the documents.
+const qrCodeResolution = 400
+
+// qrCodeSize is the size of the QR code slides, which it takes most of the short side of.
+func (c PageConfig) qrCodeSize() float64 {
+	return min(c.PageWidth, c.PageHeight) * qrCodeSizeScale
+}
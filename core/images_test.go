@@ -0,0 +1,95 @@
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func testImage(width int, height int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, color.RGBA{255, 255, 255, 255})
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	png.Encode(buf, img)
+	return buf.Bytes()
+}
+
+func decodeJPEG(t *testing.T, data []byte) image.Image {
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Expected a JPEG image: %v", err)
+	}
+	return img
+}
+
+func TestPrepareBackgroundImageCover(t *testing.T) {
+	result, err := PrepareBackgroundImage(testImage(100, 100), 160, 90, BackgroundCover, 0, Color{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img := decodeJPEG(t, result)
+	if img.Bounds().Dx() != 160 || img.Bounds().Dy() != 90 {
+		t.Errorf("Expected a 160x90 image, got %v", img.Bounds())
+	}
+
+	if r, _, _, _ := img.At(2, 45).RGBA(); r>>8 < 240 {
+		t.Errorf("Expected the image to cover the whole slide")
+	}
+}
+
+func TestPrepareBackgroundImageContainWithOverlay(t *testing.T) {
+	result, err := PrepareBackgroundImage(testImage(100, 100), 160, 90, BackgroundContain, 0.5, Color{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img := decodeJPEG(t, result)
+	if r, _, _, _ := img.At(2, 45).RGBA(); r>>8 > 15 {
+		t.Errorf("Expected a bar in the fill color on the side")
+	}
+
+	if r, _, _, _ := img.At(80, 45).RGBA(); r>>8 < 110 || r>>8 > 145 {
+		t.Errorf("Expected the image to be darkened by half, got %d", r>>8)
+	}
+}
+
+func TestPrepareBackgroundImageTooLarge(t *testing.T) {
+	// only the header is read, so a small image is given the size of a huge one
+	data := testImage(1, 1)
+	header := data[12:29]
+	binary.BigEndian.PutUint32(header[4:], 8000)
+	binary.BigEndian.PutUint32(header[8:], 8000)
+	binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(header))
+
+	if _, err := PrepareBackgroundImage(data, 160, 90, BackgroundCover, 0, Color{}); err == nil {
+		t.Error("Expected an error for an image with too many pixels")
+	}
+}
+
+func TestQrCodeSize(t *testing.T) {
+	for _, pageConfig := range []PageConfig{
+		{PageWidth: 768, PageHeight: 432},
+		{PageWidth: 432, PageHeight: 768},
+		{PageWidth: 256, PageHeight: 64},
+	} {
+		size := pageConfig.qrCodeSize()
+		if size > pageConfig.PageWidth || size > pageConfig.PageHeight {
+			t.Errorf("Expected the QR code to fit on a %vx%v page, got %v", pageConfig.PageWidth, pageConfig.PageHeight, size)
+		}
+	}
+
+	if size := (PageConfig{PageWidth: 768, PageHeight: 432}).qrCodeSize(); size != 400 {
+		t.Errorf("Expected a 400pt QR code on the default page, got %v", size)
+	}
+}
@@ -0,0 +1,422 @@
+package core
+
+import (
+	"strings"
+
+	"github.com/signintech/gopdf"
+)
+
+// ItemInfo describes a deck item, for outputs that show more than its lyrics.
+type ItemInfo struct {
+	Title      string
+	Subtitle   string
+	Author     string
+	Copyright  string
+	CCLINumber string
+	// Language and TranslationLanguage are the languages of the lyrics and their
+	// translation, which are hyphenated in them. Empty for the language of the deck.
+	Language            string
+	TranslationLanguage string
+	// PageConfig overrides the look of the deck for the slides of the item.
+	PageConfig *PageConfig
+}
+
+// itemPageConfig is the page config of the slides of an item. The items keep
+// the fonts and the logo of the deck, and its background image unless they change its color.
+// When the deck is hyphenated, the items are hyphenated in their own languages.
+func itemPageConfig(items []ItemInfo, itemIndex int, pageConfig PageConfig) PageConfig {
+	if itemIndex < 0 || itemIndex >= len(items) {
+		return pageConfig
+	}
+
+	item := items[itemIndex]
+	itemConfig := pageConfig
+	if item.PageConfig != nil {
+		itemConfig = *item.PageConfig
+		itemConfig.Logo = pageConfig.Logo
+		itemConfig.Font = pageConfig.Font
+		itemConfig.BoldFont = pageConfig.BoldFont
+		itemConfig.ItalicFont = pageConfig.ItalicFont
+		itemConfig.FontFamily = pageConfig.FontFamily
+		if itemConfig.BackgroundColor == pageConfig.BackgroundColor {
+			itemConfig.BackgroundImage = pageConfig.BackgroundImage
+		}
+	}
+
+	if pageConfig.LineBreak.Language != "" {
+		if item.Language != "" {
+			itemConfig.LineBreak.Language = item.Language
+		}
+		itemConfig.Translation.Language = item.TranslationLanguage
+	}
+
+	return itemConfig
+}
+
+// SlidePageConfig is the page config a slide is rendered with.
+// The blank slides between the items always look like the rest of the deck.
+func SlidePageConfig(slide Slide, items []ItemInfo, pageConfig PageConfig) PageConfig {
+	if slide.Type == "blank" {
+		return pageConfig
+	}
+
+	return itemPageConfig(items, slide.ItemIndex, pageConfig)
+}
+
+// SlideLine is a line of a verse, after breaking the long lines.
+type SlideLine struct {
+	Text string
+	// Response marks the lines sung by the congregation.
+	Response bool
+	// ParagraphEnd marks the last part of a line of the lyrics,
+	// which isn't stretched when the text is justified.
+	ParagraphEnd bool
+	// Translation marks the lines of the secondary language.
+	Translation bool
+}
+
+type Slide struct {
+	Type       string
+	ItemIndex  int
+	VerseIndex int
+	ChunkIndex int
+	Lines      []SlideLine
+	Text       string
+	FontSize   int
+	// Credits are the lines of the footer with the copyright of the song.
+	Credits []string
+	// Hint holds the lines of the hint, either on its own slide or overlaid on a verse.
+	Hint []string
+}
+
+const ResponseMark = "{R}"
+
+// ParseResponseLine removes the response mark from the beginning of a line
+// and tells whether it was there.
+func ParseResponseLine(line string) (string, bool) {
+	text, isResponse := strings.CutPrefix(line, ResponseMark)
+	if !isResponse {
+		return line, false
+	}
+
+	return strings.TrimLeft(text, " "), true
+}
+
+// StripResponseMarks removes the response marks from every line of the text,
+// for outputs that don't distinguish the responses.
+func StripResponseMarks(text string) string {
+	if !strings.Contains(text, ResponseMark) {
+		return text
+	}
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i], _ = ParseResponseLine(line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// PlainVerse removes the markup of the slides from a verse and puts its
+// translation between the lines, for the outputs that only have plain text.
+func PlainVerse(verse string) string {
+	return StripEmphasis(StripResponseMarks(StackTranslation(verse)))
+}
+
+func (s Slide) Content() ContentSlide {
+	return ContentSlide{
+		Type:       s.Type,
+		ItemIndex:  s.ItemIndex,
+		VerseIndex: s.VerseIndex,
+		ChunkIndex: s.ChunkIndex,
+	}
+}
+
+func ContentSlides(slides []Slide) []ContentSlide {
+	contents := make([]ContentSlide, len(slides))
+	for i, slide := range slides {
+		contents[i] = slide.Content()
+	}
+
+	return contents
+}
+
+func (c PageConfig) LineHeight() float64 {
+	return c.LineHeightAt(c.FontSize)
+}
+
+func (c PageConfig) LineHeightAt(fontSize int) float64 {
+	return float64(fontSize) * c.LineSpacing
+}
+
+func (c PageConfig) MaxLines() int {
+	return c.MaxLinesAt(c.FontSize)
+}
+
+func (c PageConfig) MaxLinesAt(fontSize int) int {
+	return int(c.PageHeight / c.LineHeightAt(fontSize))
+}
+
+func (c PageConfig) ContentWidth() float64 {
+	return c.PageWidth - 2*c.Margin
+}
+
+// ResponseIndentAt scales the indent of the response lines to a shrunk font size.
+func (c PageConfig) ResponseIndentAt(fontSize int) float64 {
+	return c.ResponseIndent * float64(fontSize) / float64(c.FontSize)
+}
+
+// NewFontMeasurer measures text set in the page config's fonts, for outputs
+// that need the same line breaks as the PDF without rendering one.
+func NewFontMeasurer(pageConfig PageConfig) (Measurer, error) {
+	goPdf := &gopdf.GoPdf{}
+	goPdf.Start(gopdf.Config{PageSize: gopdf.Rect{W: pageConfig.PageWidth, H: pageConfig.PageHeight}})
+
+	err := addFonts(goPdf, pageConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return emphasisMeasurer(goPdf, pageConfig), nil
+}
+
+// fontFamily picks the registered font for a run. Bold italic text is set
+// in the bold font, and the styles without a font fall back to the regular one.
+func (c PageConfig) fontFamily(run TextRun) string {
+	if run.Bold && len(c.BoldFont) > 0 {
+		return "bold"
+	}
+
+	if run.Italic && len(c.ItalicFont) > 0 {
+		return "italic"
+	}
+
+	return "default"
+}
+
+// emphasisMeasurer measures every run of a line in its own font,
+// at the page config's font size.
+func emphasisMeasurer(goPdf *gopdf.GoPdf, pageConfig PageConfig) Measurer {
+	return func(text string) (float64, error) {
+		width := 0.0
+		for _, run := range ParseEmphasis(text) {
+			err := goPdf.SetFont(pageConfig.fontFamily(run), "", pageConfig.FontSize)
+			if err != nil {
+				return 0, err
+			}
+
+			runWidth, err := goPdf.MeasureTextWidth(run.Text)
+			if err != nil {
+				return 0, err
+			}
+			width += runWidth
+		}
+
+		return width, nil
+	}
+}
+
+// scaleMeasurer measures text at a different size than the measurer was set up
+// for. Glyph widths grow linearly with the font size.
+func scaleMeasurer(measure Measurer, fromSize int, toSize int) Measurer {
+	if fromSize == toSize {
+		return measure
+	}
+
+	return func(text string) (float64, error) {
+		width, err := measure(text)
+		return width * float64(toSize) / float64(fromSize), err
+	}
+}
+
+// breakVerse breaks the long lines of a verse set in the given font size.
+// Response lines are narrower by their indent, emphasized words keep their marks
+// on every part of a line. Along with the lines, it returns which of them are responses.
+func breakVerse(lines []string, pageConfig PageConfig, measure Measurer, fontSize int, contentWidth float64) ([]string, []bool) {
+	measure = scaleMeasurer(measure, pageConfig.FontSize, fontSize)
+	brokenLines := make([]string, 0)
+	responses := make([]bool, 0)
+
+	for _, line := range lines {
+		text, isResponse := ParseResponseLine(line)
+		lineWidth := contentWidth
+		if isResponse {
+			lineWidth -= pageConfig.ResponseIndentAt(fontSize)
+		}
+
+		parts := BreakLongLinesWithOptions([]string{spreadEmphasis(text)}, measure, lineWidth, pageConfig.LineBreak)
+		for _, brokenLine := range balanceEmphasis(parts) {
+			brokenLines = append(brokenLines, brokenLine)
+			responses = append(responses, isResponse)
+		}
+	}
+
+	return brokenLines, responses
+}
+
+// fitVerse picks the font size of a verse according to the fit mode and breaks
+// its lines for that size. Shrinking looks for the largest size at which the
+// whole verse fits on one slide, the "shrink" mode doesn't stop until it does.
+func fitVerse(lines []string, pageConfig PageConfig, measure Measurer) (int, []string, []bool) {
+	fontSize := pageConfig.FontSize
+	brokenLines, responses := breakVerse(lines, pageConfig, measure, fontSize, pageConfig.ContentWidth())
+
+	if pageConfig.FitMode != FitShrink && pageConfig.FitMode != FitShrinkThenSplit {
+		return fontSize, brokenLines, responses
+	}
+
+	for len(brokenLines) > pageConfig.MaxLinesAt(fontSize) && fontSize > pageConfig.minFitFontSize() {
+		fontSize--
+		brokenLines, responses = breakVerse(lines, pageConfig, measure, fontSize, pageConfig.ContentWidth())
+	}
+
+	return fontSize, brokenLines, responses
+}
+
+// minFitFontSize is the size at which shrinking a verse stops.
+func (c PageConfig) minFitFontSize() int {
+	if c.FitMode == FitShrinkThenSplit && c.MinFontSize > 0 {
+		return c.MinFontSize
+	}
+
+	return 1
+}
+
+func isURL(text string) bool {
+	return strings.HasPrefix(text, "https://") || strings.HasPrefix(text, "http://")
+}
+
+// LayoutDeck decides what goes on each slide of the deck. Every output format
+// renders the slides it returns, so they all share one ContentSlide manifest.
+func LayoutDeck(textDeck [][]string, items []ItemInfo, deckConfig PageConfig, deckMeasure Measurer) []Slide {
+	slides := make([]Slide, 0)
+	slides = append(slides, Slide{Type: "blank", ItemIndex: -1})
+
+	for itemIndex, song := range textDeck {
+		info := ItemInfo{}
+		if itemIndex < len(items) {
+			info = items[itemIndex]
+		}
+
+		// the measurer takes the font size of the deck, an item may have its own
+		pageConfig := itemPageConfig(items, itemIndex, deckConfig)
+		measure := scaleMeasurer(deckMeasure, deckConfig.FontSize, pageConfig.FontSize)
+
+		// the verses of a song with credits leave room for them at the bottom
+		credits := creditsLines(info, pageConfig, measure)
+		verseConfig := pageConfig
+		verseConfig.PageHeight -= pageConfig.CreditsHeight(credits)
+		firstVerseSlide := -1
+
+		hint := ""
+		overlay := ""
+		needsTitle := pageConfig.TitleSlides && info.Title != ""
+		for verseIndex, verse := range song {
+			if strings.HasPrefix(verse, HintStartTag) && strings.HasSuffix(verse, HintEndTag) {
+				hint = verse[len(HintStartTag) : len(verse)-len(HintEndTag)]
+				continue
+			}
+
+			if hint != "" && pageConfig.HintPlacement == HintOverlay {
+				overlay = hint
+			} else if hint != "" {
+				slides = append(slides, Slide{
+					Type:      "hint",
+					ItemIndex: itemIndex,
+					Text:      hint,
+					Hint:      hintLines(hint, pageConfig, measure, pageConfig.HintFontSize),
+				})
+			}
+			hint = ""
+
+			if needsTitle {
+				slides = append(slides, titleSlide(itemIndex, info, pageConfig, measure))
+				needsTitle = false
+			}
+
+			if firstVerseSlide < 0 {
+				firstVerseSlide = len(slides)
+			}
+
+			if isURL(verse) {
+				slides = append(slides, Slide{Type: "qr", ItemIndex: itemIndex, Text: verse})
+				continue
+			}
+
+			if _, _, ok := SplitTranslation(verse); ok {
+				fontSize, pages := fitTranslation(verse, verseConfig, measure)
+				for chunkIndex, lines := range pages {
+					slides = append(slides, Slide{
+						Type:       "verse",
+						ItemIndex:  itemIndex,
+						VerseIndex: verseIndex,
+						ChunkIndex: chunkIndex,
+						Lines:      lines,
+						FontSize:   fontSize,
+					})
+				}
+				continue
+			}
+
+			fontSize, lines, responses := fitVerse(strings.Split(verse, "\n"), verseConfig, measure)
+
+			// the chunks keep the order of the lines, so they are matched by a running index
+			lineIndex := 0
+			for chunkIndex, chunk := range SplitLongSlide(lines, verseConfig.MaxLinesAt(fontSize)) {
+				slideLines := make([]SlideLine, len(chunk))
+				for i, text := range chunk {
+					slideLines[i] = SlideLine{
+						Text:         strings.TrimRight(text, " "),
+						Response:     responses[lineIndex],
+						ParagraphEnd: strings.HasSuffix(lines[lineIndex], LineEndMark),
+					}
+					lineIndex++
+				}
+
+				slides = append(slides, Slide{
+					Type:       "verse",
+					ItemIndex:  itemIndex,
+					VerseIndex: verseIndex,
+					ChunkIndex: chunkIndex,
+					Lines:      slideLines,
+					FontSize:   fontSize,
+				})
+			}
+		}
+
+		if firstVerseSlide >= 0 {
+			addCredits(slides[firstVerseSlide:], credits, pageConfig.Credits)
+			if overlay != "" {
+				addHintOverlay(slides[firstVerseSlide:], hintLines(overlay, pageConfig, measure, pageConfig.HintOverlayFontSize))
+			}
+		}
+
+		slides = append(slides, Slide{Type: "blank", ItemIndex: itemIndex})
+	}
+
+	return slides
+}
+
+// addHintOverlay shows the hint in the corner of the first verse slide of a song.
+func addHintOverlay(slides []Slide, hint []string) {
+	for i := range slides {
+		if slides[i].Type == "verse" {
+			slides[i].Hint = hint
+			return
+		}
+	}
+}
+
+// addCredits puts the credits on every verse slide of a song, or only on its last one.
+func addCredits(slides []Slide, credits []string, mode string) {
+	for i := len(slides) - 1; i >= 0 && len(credits) > 0; i-- {
+		if slides[i].Type != "verse" {
+			continue
+		}
+
+		slides[i].Credits = credits
+		if mode == CreditsLast {
+			return
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package core
+
+import (
+	"testing"
+)
+
+func fitTestDeck() [][]string {
+	return [][]string{{"Pierwsza\nDruga\nTrzecia\nCzwarta\nPiąta\nSzósta"}}
+}
+
+func fitTestPageConfig(fitMode string) PageConfig {
+	return PageConfig{
+		PageWidth:   1000,
+		PageHeight:  100,
+		FontSize:    20,
+		MinFontSize: 16,
+		LineSpacing: 1.25,
+		FitMode:     fitMode,
+	}
+}
+
+func measureLength(s string) (float64, error) {
+	return float64(len(s)), nil
+}
+
+func verseSlides(slides []Slide) []Slide {
+	result := make([]Slide, 0)
+	for _, slide := range slides {
+		if slide.Type == "verse" {
+			result = append(result, slide)
+		}
+	}
+	return result
+}
+
+func TestLayoutDeckSplitsLongVerse(t *testing.T) {
+	slides := verseSlides(LayoutDeck(fitTestDeck(), nil, fitTestPageConfig(FitSplit), measureLength))
+
+	if len(slides) != 2 {
+		t.Fatalf("Expected the verse to be split into 2 slides, got %d", len(slides))
+	}
+
+	if slides[0].FontSize != 20 || slides[1].FontSize != 20 {
+		t.Errorf("Expected the font size to stay unchanged")
+	}
+}
+
+func TestLayoutDeckShrinksLongVerse(t *testing.T) {
+	slides := verseSlides(LayoutDeck(fitTestDeck(), nil, fitTestPageConfig(FitShrink), measureLength))
+
+	if len(slides) != 1 {
+		t.Fatalf("Expected the verse to fit on 1 slide, got %d", len(slides))
+	}
+
+	if slides[0].FontSize != 13 {
+		t.Errorf("Expected the largest fitting font size 13, got %d", slides[0].FontSize)
+	}
+
+	if len(slides[0].Lines) != 6 {
+		t.Errorf("Expected all 6 lines on the slide, got %d", len(slides[0].Lines))
+	}
+}
+
+func TestLayoutDeckShrinksThenSplits(t *testing.T) {
+	slides := verseSlides(LayoutDeck(fitTestDeck(), nil, fitTestPageConfig(FitShrinkThenSplit), measureLength))
+
+	if len(slides) != 2 {
+		t.Fatalf("Expected the verse to be split into 2 slides, got %d", len(slides))
+	}
+
+	for i, slide := range slides {
+		if slide.FontSize != 16 {
+			t.Errorf("Expected the minimum font size 16, got %d", slide.FontSize)
+		}
+		if slide.ChunkIndex != i {
+			t.Errorf("Expected chunk index %d, got %d", i, slide.ChunkIndex)
+		}
+	}
+}
+
+func TestParseResponseLine(t *testing.T) {
+	text, isResponse := ParseResponseLine("{R} Amen")
+	if text != "Amen" || !isResponse {
+		t.Errorf("Expected a response line \"Amen\", got %q (%v)", text, isResponse)
+	}
+
+	text, isResponse = ParseResponseLine("Alleluja")
+	if text != "Alleluja" || isResponse {
+		t.Errorf("Expected a regular line \"Alleluja\", got %q (%v)", text, isResponse)
+	}
+}
+
+func TestLayoutDeckIndentsResponses(t *testing.T) {
+	pageConfig := PageConfig{
+		PageWidth:       30,
+		PageHeight:      1000,
+		FontSize:        20,
+		LineSpacing:     1.25,
+		HorizontalAlign: AlignLeft,
+		ResponseIndent:  10,
+	}
+	textDeck := [][]string{{"aaaa bbbb cccc dddd eeee\n{R} aaaa bbbb cccc dddd eeee"}}
+
+	slides := verseSlides(LayoutDeck(textDeck, nil, pageConfig, measureLength))
+	if len(slides) != 1 {
+		t.Fatalf("Expected 1 slide, got %d", len(slides))
+	}
+
+	expected := []SlideLine{
+		{Text: "aaaa bbbb cccc dddd eeee", ParagraphEnd: true},
+		{Text: "aaaa bbbb cccc", Response: true},
+		{Text: "dddd eeee", Response: true, ParagraphEnd: true},
+	}
+	if len(slides[0].Lines) != len(expected) {
+		t.Fatalf("Expected %d lines, got %v", len(expected), slides[0].Lines)
+	}
+
+	for i, line := range slides[0].Lines {
+		if line != expected[i] {
+			t.Errorf("Expected line %d to be %v, got %v", i, expected[i], line)
+		}
+	}
+}
+
+func TestLayoutDeckUsesItemPageConfig(t *testing.T) {
+	pageConfig := fitTestPageConfig(FitSplit)
+	itemConfig := pageConfig
+	itemConfig.FontSize = 10
+	items := []ItemInfo{{}, {PageConfig: &itemConfig}}
+
+	slides := verseSlides(LayoutDeck([][]string{{"Pierwsza"}, {"Druga"}}, items, pageConfig, measureLength))
+	if len(slides) != 2 {
+		t.Fatalf("Expected 2 slides, got %d", len(slides))
+	}
+
+	if slides[0].FontSize != 20 || slides[1].FontSize != 10 {
+		t.Errorf("Expected the font sizes 20 and 10, got %d and %d", slides[0].FontSize, slides[1].FontSize)
+	}
+}
+
+func TestSlidePageConfig(t *testing.T) {
+	pageConfig := PageConfig{BackgroundColor: Color{R: 0, G: 0, B: 0}, BackgroundImage: []byte{1}}
+	red := PageConfig{BackgroundColor: Color{R: 200, G: 0, B: 0}}
+	smaller := PageConfig{FontSize: 36}
+	items := []ItemInfo{{PageConfig: &red}, {PageConfig: &smaller}}
+
+	if config := SlidePageConfig(Slide{Type: "blank", ItemIndex: 0}, items, pageConfig); config.BackgroundColor != pageConfig.BackgroundColor {
+		t.Errorf("Expected the blank slide to keep the deck's background, got %v", config.BackgroundColor)
+	}
+
+	if config := SlidePageConfig(Slide{Type: "verse", ItemIndex: 0}, items, pageConfig); config.BackgroundColor != red.BackgroundColor || len(config.BackgroundImage) > 0 {
+		t.Errorf("Expected the item's own background without the image, got %v", config.BackgroundColor)
+	}
+
+	if config := SlidePageConfig(Slide{Type: "verse", ItemIndex: 1}, items, pageConfig); config.FontSize != 36 || len(config.BackgroundImage) == 0 {
+		t.Errorf("Expected the item's font size with the deck's background image, got %d", config.FontSize)
+	}
+}
+
+func TestSlidePageConfigLanguage(t *testing.T) {
+	pageConfig := PageConfig{LineBreak: LineBreakOptions{Language: "pl"}}
+	smaller := PageConfig{FontSize: 36, LineBreak: LineBreakOptions{Language: "pl"}}
+	items := []ItemInfo{{Language: "en", TranslationLanguage: "pl"}, {}, {Language: "uk", PageConfig: &smaller}}
+
+	if config := SlidePageConfig(Slide{Type: "verse", ItemIndex: 0}, items, pageConfig); config.LineBreak.Language != "en" || config.Translation.Language != "pl" {
+		t.Errorf("Expected the item to be hyphenated in its languages, got %q and %q", config.LineBreak.Language, config.Translation.Language)
+	}
+
+	if config := SlidePageConfig(Slide{Type: "verse", ItemIndex: 1}, items, pageConfig); config.LineBreak.Language != "pl" {
+		t.Errorf("Expected the item to be hyphenated in the language of the deck, got %q", config.LineBreak.Language)
+	}
+
+	if config := SlidePageConfig(Slide{Type: "verse", ItemIndex: 2}, items, pageConfig); config.LineBreak.Language != "uk" || config.FontSize != 36 {
+		t.Errorf("Expected the item's own config in its language, got %q", config.LineBreak.Language)
+	}
+
+	pageConfig.LineBreak.Language = ""
+	if config := SlidePageConfig(Slide{Type: "verse", ItemIndex: 0}, items, pageConfig); config.LineBreak.Language != "" || config.Translation.Language != "" {
+		t.Errorf("Expected no hyphenation in a deck without it, got %q and %q", config.LineBreak.Language, config.Translation.Language)
+	}
+}
@@ -0,0 +1,225 @@
+package core
+
+import (
+	"math"
+	"strings"
+)
+
+const LineBreakBalanced = "balanced"
+const LineBreakOptimal = "optimal"
+
+type LineBreakOptions struct {
+	// Mode is either LineBreakBalanced (the default) or LineBreakOptimal.
+	Mode string
+	// Language selects the hyphenation patterns, words aren't hyphenated when it's empty
+	// or there are no patterns for it.
+	Language string
+}
+
+type breakKind int
+
+const (
+	breakNone breakKind = iota
+	breakSpace
+	breakHyphen
+)
+
+// linePiece is a fragment of a line that is never broken, along with
+// the kind of break that is allowed after it.
+type linePiece struct {
+	text       string
+	breakAfter breakKind
+}
+
+const overfullDemerits = 1e6
+const hyphenDemerits = 50.0
+
+// hyphenates tells if the words are hyphenated, which they aren't
+// in the languages without hyphenation patterns.
+func (o LineBreakOptions) hyphenates() bool {
+	_, ok := GetHyphenator(o.Language)
+	return ok
+}
+
+// BreakLongLinesWithOptions is BreakLongLines with a choice of the line
+// breaking algorithm and hyphenation. Words joined by preventAwkwardLineBreaks
+// are only hyphenated, never separated.
+func BreakLongLinesWithOptions(lines []string, measure Measurer, contentWidth float64, options LineBreakOptions) []string {
+	hyphenator, _ := GetHyphenator(options.Language)
+
+	if options.Mode != LineBreakOptimal && hyphenator == nil {
+		return BreakLongLines(lines, measure, contentWidth)
+	}
+
+	result := make([]string, 0)
+	for _, line := range lines {
+		trimmedLine := strings.Trim(line, " ")
+		escapedLine := preventAwkwardLineBreaks(trimmedLine)
+
+		if !strings.Contains(escapedLine, " ") {
+			escapedLine = trimmedLine
+		}
+
+		var brokenLines []string
+		if options.Mode == LineBreakOptimal {
+			brokenLines = breakLineOptimally(escapedLine, measure, contentWidth, hyphenator)
+		} else {
+			brokenLines = breakLineHyphenating(escapedLine, measure, contentWidth, hyphenator)
+		}
+
+		for _, brokenLine := range brokenLines {
+			result = append(result, strings.ReplaceAll(brokenLine, "~", " "))
+		}
+	}
+
+	return result
+}
+
+// breakLineHyphenating breaks a line on spaces like BreakOnSpaces does,
+// and hyphenates the words that still don't fit.
+func breakLineHyphenating(line string, measure Measurer, contentWidth float64, hyphenator *Hyphenator) []string {
+	result := make([]string, 0)
+
+	for _, brokenLine := range BreakOnSpaces(line, measure, contentWidth) {
+		brokenLine, isLineEnd := strings.CutSuffix(strings.Trim(brokenLine, " "), LineEndMark)
+		if width, _ := measureEscaped(brokenLine, measure); width > contentWidth {
+			result = append(result, removeLineEndMarks(breakLineOptimally(brokenLine, measure, contentWidth, hyphenator))...)
+		} else {
+			result = append(result, brokenLine)
+		}
+
+		if isLineEnd {
+			result[len(result)-1] += LineEndMark
+		}
+	}
+
+	return result
+}
+
+func measureEscaped(text string, measure Measurer) (float64, error) {
+	return measure(strings.ReplaceAll(text, "~", " "))
+}
+
+func splitIntoPieces(line string, measure Measurer, contentWidth float64, hyphenator *Hyphenator) []linePiece {
+	pieces := make([]linePiece, 0)
+	words := strings.Split(line, " ")
+
+	for i, word := range words {
+		parts := []string{word}
+		// the last word is only hyphenated when it doesn't fit on its own,
+		// so that a line doesn't end with a fragment of a word
+		canHyphenate := i < len(words)-1
+		if !canHyphenate {
+			wordWidth, _ := measureEscaped(word, measure)
+			canHyphenate = wordWidth > contentWidth
+		}
+
+		if hyphenator != nil && canHyphenate {
+			parts = hyphenateJoinedWords(word, hyphenator)
+		}
+
+		for j, part := range parts {
+			breakAfter := breakHyphen
+			if j == len(parts)-1 {
+				breakAfter = breakSpace
+			}
+			pieces = append(pieces, linePiece{text: part, breakAfter: breakAfter})
+		}
+	}
+
+	pieces[len(pieces)-1].breakAfter = breakNone
+	return pieces
+}
+
+// hyphenateJoinedWords hyphenates each of the words joined with a tilde.
+func hyphenateJoinedWords(word string, hyphenator *Hyphenator) []string {
+	parts := make([]string, 0)
+	for i, joinedWord := range strings.Split(word, "~") {
+		wordParts := hyphenator.Hyphenate(joinedWord)
+		if i > 0 {
+			parts[len(parts)-1] += "~" + wordParts[0]
+			wordParts = wordParts[1:]
+		}
+		parts = append(parts, wordParts...)
+	}
+
+	return parts
+}
+
+func joinPieces(pieces []linePiece) string {
+	text := ""
+	for i, piece := range pieces {
+		text += piece.text
+		if i < len(pieces)-1 && piece.breakAfter == breakSpace {
+			text += " "
+		}
+	}
+
+	if pieces[len(pieces)-1].breakAfter == breakHyphen {
+		text += "-"
+	}
+
+	return text
+}
+
+// lineDemerits rates a line the way Knuth and Plass do, by how much room
+// is left in it. Since the lines are centered rather than justified,
+// the last line counts as well, which balances the whole verse.
+func lineDemerits(width float64, contentWidth float64, hyphenated bool) float64 {
+	if width > contentWidth {
+		return overfullDemerits * (1 + (width-contentWidth)/contentWidth)
+	}
+
+	slack := (contentWidth - width) / contentWidth
+	demerits := 1 + 100*slack*slack
+	if hyphenated {
+		demerits += hyphenDemerits
+	}
+
+	return demerits
+}
+
+// breakLineOptimally finds the line breaks that minimize the total demerits
+// of all the lines at once, instead of filling them one by one.
+// The last line ends with a LineEndMark, just like in BreakOnSpaces.
+func breakLineOptimally(line string, measure Measurer, contentWidth float64, hyphenator *Hyphenator) []string {
+	lineWidth, _ := measureEscaped(line, measure)
+	if lineWidth <= contentWidth {
+		return []string{line + LineEndMark}
+	}
+
+	pieces := splitIntoPieces(line, measure, contentWidth, hyphenator)
+	numPieces := len(pieces)
+
+	totalDemerits := make([]float64, numPieces+1)
+	previousBreak := make([]int, numPieces+1)
+	for i := 1; i <= numPieces; i++ {
+		totalDemerits[i] = math.Inf(+1)
+	}
+
+	for end := 1; end <= numPieces; end++ {
+		for start := end - 1; start >= 0; start-- {
+			linePieces := pieces[start:end]
+			width, _ := measureEscaped(joinPieces(linePieces), measure)
+			demerits := totalDemerits[start] + lineDemerits(width, contentWidth, linePieces[len(linePieces)-1].breakAfter == breakHyphen)
+			if demerits < totalDemerits[end] {
+				totalDemerits[end] = demerits
+				previousBreak[end] = start
+			}
+
+			// adding more pieces would only make the line wider
+			if width > contentWidth {
+				break
+			}
+		}
+	}
+
+	result := make([]string, 0)
+	for end := numPieces; end > 0; end = previousBreak[end] {
+		start := previousBreak[end]
+		result = append([]string{joinPieces(pieces[start:end])}, result...)
+	}
+
+	result[len(result)-1] += LineEndMark
+	return result
+}
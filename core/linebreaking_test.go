@@ -0,0 +1,47 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBreakLongLinesHyphenatesLongWords(t *testing.T) {
+	result := BreakLongLinesWithOptions([]string{"Najprzenajświętszy"}, measureLength, 14, LineBreakOptions{Language: "pl"})
+
+	if len(result) != 2 {
+		t.Fatalf("Expected the word to be hyphenated into 2 lines, got %q", result)
+	}
+
+	if result[0] != "Najprzenajś-" || result[1] != "więtszy"+LineEndMark {
+		t.Errorf("Unexpected hyphenation: %q", result)
+	}
+}
+
+func TestBreakLongLinesWithoutPatterns(t *testing.T) {
+	result := BreakLongLinesWithOptions([]string{"Najprzenajświętszy"}, measureLength, 14, LineBreakOptions{Language: "en"})
+
+	if expected := BreakLongLines([]string{"Najprzenajświętszy"}, measureLength, 14); !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected a language without patterns not to be hyphenated, got %q", result)
+	}
+}
+
+func TestBreakLongLinesOptimal(t *testing.T) {
+	line := "aaaa bbbb cccc dddd eeee ffff gggggggggggggggggg"
+	result := BreakLongLinesWithOptions([]string{line}, measureLength, 30, LineBreakOptions{Mode: LineBreakOptimal})
+
+	expected := []string{"aaaa bbbb cccc dddd eeee", "ffff gggggggggggggggggg" + LineEndMark}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestBreakLongLinesOptimalKeepsShortWordsTogether(t *testing.T) {
+	line := "Ojcze nasz, który jesteś w niebie, święć się imię Twoje"
+	result := BreakLongLinesWithOptions([]string{line}, measureLength, 30, LineBreakOptions{Mode: LineBreakOptimal})
+
+	for _, brokenLine := range result {
+		if brokenLine[len(brokenLine)-2:] == " w" {
+			t.Errorf("Expected a single-letter word not to end a line: %q", result)
+		}
+	}
+}
@@ -0,0 +1,603 @@
+package core
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/skip2/go-qrcode"
+)
+
+const odpMimeType = "application/vnd.oasis.opendocument.presentation"
+
+const odpManifest = `<?xml version="1.0" encoding="UTF-8"?>
+<manifest:manifest xmlns:manifest="urn:oasis:names:tc:opendocument:xmlns:manifest:1.0" manifest:version="1.2">
+<manifest:file-entry manifest:full-path="/" manifest:version="1.2" manifest:media-type="application/vnd.oasis.opendocument.presentation"/>
+<manifest:file-entry manifest:full-path="content.xml" manifest:media-type="text/xml"/>
+<manifest:file-entry manifest:full-path="styles.xml" manifest:media-type="text/xml"/>
+<manifest:file-entry manifest:full-path="meta.xml" manifest:media-type="text/xml"/>
+%s</manifest:manifest>`
+
+const odpMeta = `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-meta xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:meta="urn:oasis:names:tc:opendocument:xmlns:meta:1.0" office:version="1.2">
+<office:meta><meta:generator>goslides</meta:generator></office:meta>
+</office:document-meta>`
+
+const odpStyles = `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-styles xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:style="urn:oasis:names:tc:opendocument:xmlns:style:1.0" xmlns:fo="urn:oasis:names:tc:opendocument:xmlns:xsl-fo-compatible:1.0" xmlns:draw="urn:oasis:names:tc:opendocument:xmlns:drawing:1.0" xmlns:svg="urn:oasis:names:tc:opendocument:xmlns:svg-compatible:1.0" xmlns:xlink="http://www.w3.org/1999/xlink" office:version="1.2">
+<office:font-face-decls><style:font-face style:name="%[1]s" svg:font-family="'%[1]s'"/></office:font-face-decls>
+<office:styles>%[5]s</office:styles>
+<office:automatic-styles>
+<style:page-layout style:name="PM1"><style:page-layout-properties fo:margin-top="0pt" fo:margin-bottom="0pt" fo:margin-left="0pt" fo:margin-right="0pt" fo:page-width="%[2].2fpt" fo:page-height="%[3].2fpt"/></style:page-layout>
+<style:style style:name="Mdp1" style:family="drawing-page"><style:drawing-page-properties %[4]s/></style:style>
+</office:automatic-styles>
+<office:master-styles><style:master-page style:name="Default" style:page-layout-name="PM1" draw:style-name="Mdp1"/></office:master-styles>
+</office:document-styles>`
+
+const odpContent = `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:style="urn:oasis:names:tc:opendocument:xmlns:style:1.0" xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0" xmlns:fo="urn:oasis:names:tc:opendocument:xmlns:xsl-fo-compatible:1.0" xmlns:draw="urn:oasis:names:tc:opendocument:xmlns:drawing:1.0" xmlns:svg="urn:oasis:names:tc:opendocument:xmlns:svg-compatible:1.0" xmlns:presentation="urn:oasis:names:tc:opendocument:xmlns:presentation:1.0" xmlns:xlink="http://www.w3.org/1999/xlink" office:version="1.2">
+<office:automatic-styles>
+<style:style style:name="dp1" style:family="drawing-page"><style:drawing-page-properties %[1]s presentation:background-visible="true"/></style:style>
+<style:style style:name="grVerse" style:family="graphic"><style:graphic-properties draw:stroke="none" draw:fill="none" draw:textarea-vertical-align="%[2]s" draw:textarea-horizontal-align="center" draw:auto-grow-height="false" draw:auto-grow-width="false" fo:padding="0pt" fo:wrap-option="no-wrap"/></style:style>
+<style:style style:name="grHint" style:family="graphic"><style:graphic-properties draw:stroke="none" draw:fill="none" draw:textarea-vertical-align="top" draw:auto-grow-height="false" fo:padding="0pt" fo:wrap-option="no-wrap"/></style:style>
+<style:style style:name="grImage" style:family="graphic"><style:graphic-properties draw:stroke="none" draw:fill="none"/></style:style>
+<style:style style:name="pCenter" style:family="paragraph"><style:paragraph-properties fo:text-align="center" fo:line-height="%[3].2fpt"/></style:style>
+<style:style style:name="pHint" style:family="paragraph"><style:paragraph-properties fo:text-align="start"/></style:style>
+<style:style style:name="tVerse" style:family="text"><style:text-properties fo:font-size="%[4]dpt" fo:color="#%[5]s" style:font-name="%[6]s"/></style:style>
+<style:style style:name="tHint" style:family="text"><style:text-properties fo:font-size="%[7]dpt" fo:color="#%[10]s" style:font-name="%[6]s"/></style:style>
+<style:style style:name="tHintOverlay" style:family="text"><style:text-properties fo:font-size="%[11]dpt" fo:color="#%[10]s" style:font-name="%[6]s"/></style:style>
+%[9]s</office:automatic-styles>
+<office:body><office:presentation>
+%[8]s</office:presentation></office:body>
+</office:document-content>`
+
+const odpTitleStyles = `<style:style style:name="grTitle" style:family="graphic"><style:graphic-properties draw:stroke="none" draw:fill="none" draw:textarea-vertical-align="middle" draw:textarea-horizontal-align="center" draw:auto-grow-height="false" draw:auto-grow-width="false" fo:padding="0pt" fo:wrap-option="no-wrap"/></style:style>
+<style:style style:name="pTitle" style:family="paragraph"><style:paragraph-properties fo:text-align="center" fo:line-height="%[1].2fpt"/></style:style>
+<style:style style:name="pTitleDetail" style:family="paragraph"><style:paragraph-properties fo:text-align="center" fo:line-height="%[2].2fpt"/></style:style>
+<style:style style:name="tTitleDetail" style:family="text"><style:text-properties fo:font-size="%[3]dpt" fo:color="#%[4]s" style:font-name="%[5]s"/></style:style>
+<style:style style:name="pCredits" style:family="paragraph"><style:paragraph-properties fo:text-align="center" fo:line-height="%[6].2fpt"/></style:style>
+<style:style style:name="tCredits" style:family="text"><style:text-properties fo:font-size="%[7]dpt" fo:color="#%[8]s" style:font-name="%[5]s"/></style:style>
+`
+
+const odpVerseFrameStyle = `<style:style style:name="%s" style:family="graphic"><style:graphic-properties draw:stroke="none" draw:fill="none" draw:textarea-vertical-align="%s" draw:textarea-horizontal-align="center" draw:auto-grow-height="false" draw:auto-grow-width="false" fo:padding="0pt" fo:wrap-option="no-wrap"/></style:style>
+`
+
+const odpPageStyle = `<style:style style:name="%s" style:family="drawing-page"><style:drawing-page-properties %s presentation:background-visible="true"/></style:style>
+`
+
+const odpImageFrame = `<draw:frame draw:style-name="grImage" svg:x="%.2fpt" svg:y="%.2fpt" svg:width="%.2fpt" svg:height="%.2fpt"><draw:image xlink:href="%s" xlink:type="simple" xlink:show="embed" xlink:actuate="onLoad"/></draw:frame>`
+
+const odpFillImage = `<draw:fill-image draw:name="%s" xlink:href="%s" xlink:type="simple" xlink:show="embed" xlink:actuate="onLoad"/>`
+
+type odpWriter struct {
+	pageConfig PageConfig
+	// slideConfig is the page config of the slide being written, which may differ for some items
+	slideConfig PageConfig
+	zipWriter   *zip.Writer
+	images      []odpImage
+	// imagesByData are the images written so far, to write every one once
+	imagesByData map[string]odpImage
+	// the images which fill the backgrounds, declared in the styles
+	fillImages []odpImage
+	// the verse styles that differ from the default one, written out with the rest
+	paragraphStyles []odpVerseStyle
+	textStyles      []odpVerseStyle
+	pageStyles      []odpBackground
+	frameStyles     []string
+}
+
+type odpImage struct {
+	name string
+	path string
+}
+
+// odpBackground is the fill of a page, with either a color or a fill image.
+type odpBackground struct {
+	color     Color
+	fillImage string
+}
+
+func (b odpBackground) properties() string {
+	if b.fillImage != "" {
+		return fmt.Sprintf(`draw:fill="bitmap" draw:fill-image-name="%s" style:repeat="stretch"`, b.fillImage)
+	}
+
+	return fmt.Sprintf(`draw:fill="solid" draw:fill-color="#%s"`, b.color.Hex())
+}
+
+func (b odpBackground) styleName() string {
+	if b.fillImage != "" {
+		return "dp" + b.fillImage
+	}
+
+	return "dp" + b.color.Hex()
+}
+
+type odpVerseStyle struct {
+	fontSize     int
+	response     bool
+	paragraphEnd bool
+	bold         bool
+	italic       bool
+	translation  bool
+	color        Color
+}
+
+func verseColor(pageConfig PageConfig, response bool, translation bool) Color {
+	if response {
+		return pageConfig.ResponseColor
+	}
+
+	if translation {
+		return pageConfig.Translation.Color
+	}
+
+	return pageConfig.TextColor
+}
+
+func odpVerticalAlign(verticalAlign string) string {
+	switch verticalAlign {
+	case "top":
+		return "top"
+	case "bottom":
+		return "bottom"
+	}
+
+	return "middle"
+}
+
+func (w *odpWriter) writeFile(name string, content []byte, method uint16) error {
+	f, err := w.zipWriter.CreateHeader(&zip.FileHeader{Name: name, Method: method})
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(content)
+	return err
+}
+
+// addImage writes an image into the Pictures folder once.
+func (w *odpWriter) addImage(prefix string, data []byte) (odpImage, error) {
+	if image, ok := w.imagesByData[string(data)]; ok {
+		return image, nil
+	}
+
+	format, err := imageFormat(data)
+	if err != nil {
+		return odpImage{}, err
+	}
+
+	name := fmt.Sprintf("%s%d", prefix, len(w.images)+1)
+	image := odpImage{name: name, path: fmt.Sprintf("Pictures/%s.%s", name, format)}
+	if err := w.writeFile(image.path, data, zip.Store); err != nil {
+		return odpImage{}, err
+	}
+	w.images = append(w.images, image)
+	w.imagesByData[string(data)] = image
+
+	return image, nil
+}
+
+// background returns the fill of the pages of the given config.
+func (w *odpWriter) background(pageConfig PageConfig) (odpBackground, error) {
+	if len(pageConfig.BackgroundImage) == 0 {
+		return odpBackground{color: pageConfig.BackgroundColor}, nil
+	}
+
+	image, err := w.addImage("background", pageConfig.BackgroundImage)
+	if err != nil {
+		return odpBackground{}, err
+	}
+	if !slices.Contains(w.fillImages, image) {
+		w.fillImages = append(w.fillImages, image)
+	}
+
+	return odpBackground{fillImage: image.name}, nil
+}
+
+func odpSpan(textStyle string, text string) string {
+	return fmt.Sprintf(`<text:span text:style-name="%s">%s</text:span>`, textStyle, escapeXML(text))
+}
+
+func odpParagraph(paragraphStyle string, textStyle string, text string) string {
+	return fmt.Sprintf(`<text:p text:style-name="%s">%s</text:p>`, paragraphStyle, odpSpan(textStyle, text))
+}
+
+func odpFrame(style string, x, y, width, height float64, paragraphs string) string {
+	return fmt.Sprintf(`<draw:frame draw:style-name="%s" svg:x="%.2fpt" svg:y="%.2fpt" svg:width="%.2fpt" svg:height="%.2fpt"><draw:text-box>%s</draw:text-box></draw:frame>`,
+		style, x, y, width, height, paragraphs)
+}
+
+func odpTextBox(style string, paragraphStyle string, textStyle string, x, y, width, height float64, lines []string) string {
+	paragraphs := ""
+	for _, line := range lines {
+		paragraphs += odpParagraph(paragraphStyle, textStyle, line)
+	}
+
+	return odpFrame(style, x, y, width, height, paragraphs)
+}
+
+func (s odpVerseStyle) paragraphStyleName() string {
+	name := fmt.Sprintf("pVerse%d", s.fontSize)
+	if s.response {
+		name += "R"
+	}
+	if s.paragraphEnd {
+		name += "E"
+	}
+
+	return name
+}
+
+func (s odpVerseStyle) textStyleName(pageConfig PageConfig) string {
+	name := fmt.Sprintf("tVerse%d", s.fontSize)
+	if s.response {
+		name += "R"
+	}
+	if s.bold {
+		name += "B"
+	}
+	if s.italic {
+		name += "I"
+	}
+	if s.translation {
+		name += "T"
+	}
+	if s.color != verseColor(pageConfig, s.response, s.translation) {
+		name += "C" + s.color.Hex()
+	}
+
+	return name
+}
+
+// paragraphStyle returns the style of a line of a verse set in the given font size.
+// Lines that don't look like the default centered ones get their own styles,
+// written out with the rest.
+func (w *odpWriter) paragraphStyle(fontSize int, line SlideLine) string {
+	fontSize = w.pageConfig.lineFontSize(line, fontSize)
+	if fontSize == w.pageConfig.FontSize && !line.Response && slices.Contains([]string{"", AlignCenter}, w.pageConfig.HorizontalAlign) {
+		return "pCenter"
+	}
+
+	style := odpVerseStyle{
+		fontSize:     fontSize,
+		response:     line.Response,
+		paragraphEnd: line.ParagraphEnd && w.pageConfig.HorizontalAlign == AlignJustify,
+	}
+	if !slices.Contains(w.paragraphStyles, style) {
+		w.paragraphStyles = append(w.paragraphStyles, style)
+	}
+
+	return style.paragraphStyleName()
+}
+
+// textStyle returns the style of a run of text, like paragraphStyle does for lines.
+func (w *odpWriter) textStyle(fontSize int, line SlideLine, run TextRun) string {
+	style := odpVerseStyle{
+		fontSize:    w.pageConfig.lineFontSize(line, fontSize),
+		response:    line.Response,
+		bold:        run.Bold,
+		italic:      run.Italic,
+		translation: line.Translation && !line.Response,
+		color:       verseColor(w.slideConfig, line.Response, line.Translation),
+	}
+	if style == (odpVerseStyle{fontSize: w.pageConfig.FontSize, color: w.pageConfig.TextColor}) {
+		return "tVerse"
+	}
+
+	if !slices.Contains(w.textStyles, style) {
+		w.textStyles = append(w.textStyles, style)
+	}
+
+	return style.textStyleName(w.pageConfig)
+}
+
+// frameStyle returns the style of the verse frames, which differs
+// for the items aligned vertically in their own way.
+func (w *odpWriter) frameStyle() string {
+	verticalAlign := odpVerticalAlign(w.slideConfig.VerticalAlign)
+	if verticalAlign == odpVerticalAlign(w.pageConfig.VerticalAlign) {
+		return "grVerse"
+	}
+
+	if !slices.Contains(w.frameStyles, verticalAlign) {
+		w.frameStyles = append(w.frameStyles, verticalAlign)
+	}
+
+	return "grVerse" + verticalAlign
+}
+
+// pageStyle returns the style of the page, which differs
+// for the items with their own background.
+func (w *odpWriter) pageStyle() (string, error) {
+	background, err := w.background(w.slideConfig)
+	if err != nil {
+		return "", err
+	}
+
+	deckBackground, err := w.background(w.pageConfig)
+	if err != nil {
+		return "", err
+	}
+
+	if background == deckBackground {
+		return "dp1", nil
+	}
+
+	if !slices.Contains(w.pageStyles, background) {
+		w.pageStyles = append(w.pageStyles, background)
+	}
+
+	return background.styleName(), nil
+}
+
+func (w *odpWriter) verseParagraphs(fontSize int, lines []SlideLine) string {
+	paragraphs := ""
+	for _, line := range lines {
+		spans := ""
+		for _, run := range ParseEmphasis(line.Text) {
+			spans += odpSpan(w.textStyle(fontSize, line, run), run.Text)
+		}
+
+		paragraphs += fmt.Sprintf(`<text:p text:style-name="%s">%s</text:p>`, w.paragraphStyle(fontSize, line), spans)
+	}
+
+	return paragraphs
+}
+
+func (w *odpWriter) titleFrame(slide Slide) string {
+	paragraphs := ""
+	for _, line := range slide.Lines {
+		spans := ""
+		for _, run := range ParseEmphasis(line.Text) {
+			spans += odpSpan(w.textStyle(slide.FontSize, line, run), run.Text)
+		}
+		paragraphs += fmt.Sprintf(`<text:p text:style-name="pTitle">%s</text:p>`, spans)
+	}
+
+	for _, detail := range slide.TitleDetails() {
+		paragraphs += odpParagraph("pTitleDetail", "tTitleDetail", detail)
+	}
+
+	margin := w.pageConfig.Margin
+	return odpFrame("grTitle", margin, margin, w.pageConfig.ContentWidth(), w.pageConfig.PageHeight-2*margin, paragraphs)
+}
+
+func (w *odpWriter) creditsFrame(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+
+	pageConfig := w.pageConfig
+	y := pageConfig.PageHeight - pageConfig.CreditsHeight(lines)
+	height := float64(len(lines)) * pageConfig.LineHeightAt(pageConfig.CreditsFontSize)
+	return odpTextBox("grHint", "pCredits", "tCredits", pageConfig.Margin, y, pageConfig.ContentWidth(), height, lines)
+}
+
+func (w *odpWriter) hintFrame(slide Slide) string {
+	if len(slide.Hint) == 0 {
+		return ""
+	}
+
+	textStyle := "tHint"
+	if slide.Type != "hint" {
+		textStyle = "tHintOverlay"
+	}
+
+	pageConfig := w.pageConfig
+	x, y := pageConfig.HintPosition(slide)
+	height := float64(len(slide.Hint)) * pageConfig.LineHeightAt(pageConfig.HintFontSizeOf(slide))
+	return odpTextBox("grHint", "pHint", textStyle, x, y, pageConfig.hintWidth(), height, slide.Hint)
+}
+
+func (w *odpWriter) titleStyles() string {
+	pageConfig := w.pageConfig
+	return fmt.Sprintf(odpTitleStyles, pageConfig.LineHeight(), pageConfig.LineHeightAt(pageConfig.HintFontSize),
+		pageConfig.HintFontSize, pageConfig.TextColor.Hex(), escapeXML(pageConfig.FontFamily),
+		pageConfig.LineHeightAt(pageConfig.CreditsFontSize), pageConfig.CreditsFontSize, pageConfig.CreditsColor.Hex())
+}
+
+func (w *odpWriter) extraStyles() string {
+	textAlign := "center"
+	switch w.pageConfig.HorizontalAlign {
+	case AlignLeft:
+		textAlign = "start"
+	case AlignRight:
+		textAlign = "end"
+	case AlignJustify:
+		textAlign = "justify"
+	}
+
+	styles := ""
+	for _, style := range w.paragraphStyles {
+		properties := fmt.Sprintf(`fo:text-align="%s" fo:line-height="%.2fpt"`, textAlign, w.pageConfig.LineHeightAt(style.fontSize))
+		// every line is a separate paragraph, so all but the last ones have to stretch their last line
+		if w.pageConfig.HorizontalAlign == AlignJustify && !style.paragraphEnd {
+			properties += ` fo:text-align-last="justify"`
+		}
+		if style.response {
+			properties += fmt.Sprintf(` fo:margin-left="%.2fpt"`, w.pageConfig.ResponseIndentAt(style.fontSize))
+		}
+
+		styles += fmt.Sprintf(`<style:style style:name="%s" style:family="paragraph"><style:paragraph-properties %s/></style:style>
+`, style.paragraphStyleName(), properties)
+	}
+
+	for _, style := range w.textStyles {
+		properties := fmt.Sprintf(`fo:font-size="%dpt" fo:color="#%s" style:font-name="%s"`, style.fontSize, style.color.Hex(), escapeXML(w.pageConfig.FontFamily))
+		if style.bold {
+			properties += ` fo:font-weight="bold"`
+		}
+		if style.italic {
+			properties += ` fo:font-style="italic"`
+		}
+
+		styles += fmt.Sprintf(`<style:style style:name="%s" style:family="text"><style:text-properties %s/></style:style>
+`, style.textStyleName(w.pageConfig), properties)
+	}
+
+	for _, verticalAlign := range w.frameStyles {
+		styles += fmt.Sprintf(odpVerseFrameStyle, "grVerse"+verticalAlign, verticalAlign)
+	}
+
+	for _, background := range w.pageStyles {
+		styles += fmt.Sprintf(odpPageStyle, background.styleName(), background.properties())
+	}
+
+	return styles
+}
+
+func (w *odpWriter) qrFrames(content string) (string, error) {
+	qrSize := w.pageConfig.qrCodeSize()
+	png, err := qrcode.Encode(content, qrcode.Medium, qrCodeResolution)
+	if err != nil {
+		return "", err
+	}
+
+	qrImage, err := w.addImage("qr", png)
+	if err != nil {
+		return "", err
+	}
+
+	x := (w.pageConfig.PageWidth - qrSize) / 2
+	y := (w.pageConfig.PageHeight - qrSize) / 2
+	image := fmt.Sprintf(odpImageFrame, x, y, qrSize, qrSize, qrImage.path)
+
+	textY := w.pageConfig.PageHeight - y + (y-float64(w.pageConfig.FontSize))/2
+	caption := odpTextBox("grHint", "pCenter", "tVerse", 0, textY, w.pageConfig.PageWidth, w.pageConfig.LineHeight(), []string{content})
+
+	return image + caption, nil
+}
+
+func (w *odpWriter) logoFrame() (string, error) {
+	if len(w.pageConfig.Logo) == 0 {
+		return "", nil
+	}
+
+	width, height, err := fitImage(w.pageConfig.Logo, w.pageConfig.PageWidth/2, w.pageConfig.PageHeight/3)
+	if err != nil {
+		return "", err
+	}
+
+	logo, err := w.addImage("logo", w.pageConfig.Logo)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf(odpImageFrame, (w.pageConfig.PageWidth-width)/2, (w.pageConfig.PageHeight-height)/2, width, height, logo.path), nil
+}
+
+func (w *odpWriter) page(number int, slide Slide) (string, error) {
+	pageStyle, err := w.pageStyle()
+	if err != nil {
+		return "", err
+	}
+
+	frames := ""
+	pageConfig := w.pageConfig
+
+	switch slide.Type {
+	case "blank":
+		frames, err = w.logoFrame()
+	case "hint":
+		frames = w.hintFrame(slide)
+	case "qr":
+		frames, err = w.qrFrames(slide.Text)
+	case "title":
+		frames = w.titleFrame(slide)
+	case "verse":
+		margin := pageConfig.Margin
+		height := pageConfig.ContentHeight(slide) - 2*margin
+		original, translation := SplitColumns(slide.Lines)
+		frameStyle := w.frameStyle()
+		if pageConfig.isColumnLayout() && len(translation) > 0 {
+			columnWidth := pageConfig.TranslationColumnWidth()
+			frames = odpFrame(frameStyle, margin, margin, columnWidth, height, w.verseParagraphs(slide.FontSize, original)) +
+				odpFrame(frameStyle, pageConfig.PageWidth-margin-columnWidth, margin, columnWidth, height, w.verseParagraphs(slide.FontSize, translation))
+		} else {
+			frames = odpFrame(frameStyle, margin, margin, pageConfig.ContentWidth(), height, w.verseParagraphs(slide.FontSize, slide.Lines))
+		}
+		frames += w.creditsFrame(slide.Credits) + w.hintFrame(slide)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf(`<draw:page draw:name="page%d" draw:style-name="%s" draw:master-page-name="Default">%s</draw:page>
+`, number, pageStyle, frames), nil
+}
+
+func BuildODP(textDeck [][]string, items []ItemInfo, pageConfig PageConfig) (*bytes.Buffer, []ContentSlide, error) {
+	measure, err := NewFontMeasurer(pageConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	slides := LayoutDeck(textDeck, items, pageConfig, measure)
+
+	buf := new(bytes.Buffer)
+	w := &odpWriter{pageConfig: pageConfig, zipWriter: zip.NewWriter(buf), imagesByData: make(map[string]odpImage)}
+
+	// the mimetype entry has to come first and must not be compressed
+	if err := w.writeFile("mimetype", []byte(odpMimeType), zip.Store); err != nil {
+		return nil, nil, err
+	}
+
+	pages := ""
+	for i, slide := range slides {
+		w.slideConfig = SlidePageConfig(slide, items, pageConfig)
+		page, err := w.page(i+1, slide)
+		if err != nil {
+			return nil, nil, err
+		}
+		pages += page
+	}
+
+	background, err := w.background(pageConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	verticalAlign := odpVerticalAlign(pageConfig.VerticalAlign)
+	fontFamily := escapeXML(pageConfig.FontFamily)
+
+	content := fmt.Sprintf(odpContent, background.properties(), verticalAlign, pageConfig.LineHeight(),
+		pageConfig.FontSize, pageConfig.TextColor.Hex(), fontFamily, pageConfig.HintFontSize, pages, w.titleStyles()+w.extraStyles(),
+		pageConfig.HintColor.Hex(), pageConfig.HintOverlayFontSize)
+	fillImages := ""
+	for _, image := range w.fillImages {
+		fillImages += fmt.Sprintf(odpFillImage, image.name, image.path)
+	}
+	styles := fmt.Sprintf(odpStyles, fontFamily, pageConfig.PageWidth, pageConfig.PageHeight, background.properties(), fillImages)
+
+	imageEntries := ""
+	for _, image := range w.images {
+		imageEntries += fmt.Sprintf(`<manifest:file-entry manifest:full-path="%s" manifest:media-type="image/%s"/>
+`, image.path, strings.TrimPrefix(path.Ext(image.path), "."))
+	}
+
+	files := []struct {
+		name    string
+		content string
+	}{
+		{"content.xml", content},
+		{"styles.xml", styles},
+		{"meta.xml", odpMeta},
+		{"META-INF/manifest.xml", fmt.Sprintf(odpManifest, imageEntries)},
+	}
+
+	for _, file := range files {
+		if err := w.writeFile(file.name, []byte(file.content), zip.Deflate); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := w.zipWriter.Close(); err != nil {
+		return nil, nil, err
+	}
+
+	return buf, ContentSlides(slides), nil
+}
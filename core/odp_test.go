@@ -0,0 +1,113 @@
+package core
+
+import (
+	"archive/zip"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestBuildODP(t *testing.T) {
+	pageConfig := testPageConfig(t)
+	textDeck := [][]string{
+		{"Pan kiedyś stanął nad brzegiem", "Szukał ludzi gotowych pójść za Nim & <innymi>"},
+		{"https://example.com/?a=1&b=2"},
+	}
+
+	buf, contents, err := BuildODP(textDeck, nil, pageConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, files := readArchive(t, buf.Bytes())
+	if entries[0].Name != "mimetype" || entries[0].Method != zip.Store {
+		t.Errorf("Expected the mimetype to come first, uncompressed")
+	}
+	if files["mimetype"] != odpMimeType {
+		t.Errorf("Expected the mimetype %q, got %q", odpMimeType, files["mimetype"])
+	}
+
+	for name, content := range files {
+		if strings.HasSuffix(name, ".xml") {
+			checkWellFormed(t, name, content)
+		}
+	}
+
+	content := files["content.xml"]
+	if count := strings.Count(content, "<draw:page "); count != len(contents) {
+		t.Errorf("Expected a page per content slide, got %d pages for %d content slides", count, len(contents))
+	}
+
+	manifest := files["META-INF/manifest.xml"]
+	for _, entry := range entries {
+		if entry.Name == "mimetype" || entry.Name == "META-INF/manifest.xml" {
+			continue
+		}
+		if !strings.Contains(manifest, fmt.Sprintf(`manifest:full-path="%s"`, entry.Name)) {
+			t.Errorf("Expected the manifest to list %s", entry.Name)
+		}
+	}
+	if _, ok := files["Pictures/qr1.png"]; !ok {
+		t.Error("Expected the QR code image of the link")
+	}
+
+	for _, expected := range []string{"Nim &amp; &lt;innymi&gt;", "?a=1&amp;b=2"} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("Expected the content to contain %q", expected)
+		}
+	}
+}
+
+func TestBuildODPImages(t *testing.T) {
+	background, err := PrepareBackgroundImage(testImage(100, 100), 768, 432, BackgroundCover, 0, Color{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pageConfig := testPageConfig(t)
+	pageConfig.BackgroundImage = background
+	pageConfig.Logo = testImage(40, 20)
+	textDeck := [][]string{{"Pan kiedyś stanął nad brzegiem"}}
+
+	buf, _, err := BuildODP(textDeck, nil, pageConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, files := readArchive(t, buf.Bytes())
+	for name, content := range files {
+		if strings.HasSuffix(name, ".xml") {
+			checkWellFormed(t, name, content)
+		}
+	}
+
+	if files["Pictures/background1.jpeg"] != string(background) || files["Pictures/logo2.png"] != string(pageConfig.Logo) {
+		t.Error("Expected the background image and the logo to be written once")
+	}
+
+	manifest := files["META-INF/manifest.xml"]
+	for _, expected := range []string{
+		`manifest:full-path="Pictures/background1.jpeg" manifest:media-type="image/jpeg"`,
+		`manifest:full-path="Pictures/logo2.png" manifest:media-type="image/png"`,
+	} {
+		if !strings.Contains(manifest, expected) {
+			t.Errorf("Expected the manifest to contain %q", expected)
+		}
+	}
+
+	if !strings.Contains(files["styles.xml"], `<draw:fill-image draw:name="background1" xlink:href="Pictures/background1.jpeg"`) {
+		t.Error("Expected the styles to declare the background image")
+	}
+
+	content := files["content.xml"]
+	if !strings.Contains(content, `<style:style style:name="dp1" style:family="drawing-page"><style:drawing-page-properties draw:fill="bitmap" draw:fill-image-name="background1"`) {
+		t.Error("Expected the pages to be filled with the background image")
+	}
+	pages := strings.Split(content, "<draw:page ")[1:]
+	for i, page := range pages {
+		isBlank := !strings.Contains(page, "<draw:text-box>")
+		if hasLogo := strings.Contains(page, `xlink:href="Pictures/logo2.png"`); hasLogo != isBlank {
+			t.Errorf("Expected the logo on the blank pages only, page %d has it: %v", i+1, hasLogo)
+		}
+	}
+}
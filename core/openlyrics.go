@@ -0,0 +1,318 @@
+package core
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const openLyricsNamespace = "http://openlyrics.info/namespace/2009/song"
+const openLyricsVersion = "0.9"
+
+// OpenLyricsVerse is a verse with its name from the verse order, like "v1" or "c".
+// The lines are separated with line breaks and the chords are marked like in the lyrics.
+type OpenLyricsVerse struct {
+	Name string
+	Text string
+}
+
+type OpenLyricsSong struct {
+	Title          string
+	Subtitle       string
+	Author         string
+	Copyright      string
+	CCLINumber     string
+	Songbook       string
+	SongbookNumber string
+	Language       string
+	// VerseOrder is a list of the verse names separated with spaces.
+	VerseOrder string
+	Verses     []OpenLyricsVerse
+}
+
+type openLyricsTitle struct {
+	Lang  string `xml:"lang,attr,omitempty"`
+	Value string `xml:",chardata"`
+}
+
+type openLyricsSongbook struct {
+	Name  string `xml:"name,attr"`
+	Entry string `xml:"entry,attr,omitempty"`
+}
+
+type openLyricsAuthors struct {
+	Authors []string `xml:"author"`
+}
+
+type openLyricsSongbooks struct {
+	Songbooks []openLyricsSongbook `xml:"songbook"`
+}
+
+type openLyricsLines struct {
+	Content string `xml:",innerxml"`
+}
+
+type openLyricsVerse struct {
+	Name  string            `xml:"name,attr"`
+	Lang  string            `xml:"lang,attr,omitempty"`
+	Lines []openLyricsLines `xml:"lines"`
+}
+
+type openLyricsDocument struct {
+	XMLName      xml.Name `xml:"song"`
+	Xmlns        string   `xml:"xmlns,attr,omitempty"`
+	Version      string   `xml:"version,attr"`
+	CreatedIn    string   `xml:"createdIn,attr,omitempty"`
+	ModifiedIn   string   `xml:"modifiedIn,attr,omitempty"`
+	ModifiedDate string   `xml:"modifiedDate,attr,omitempty"`
+	Properties   struct {
+		Titles     []openLyricsTitle    `xml:"titles>title"`
+		Authors    *openLyricsAuthors   `xml:"authors"`
+		Copyright  string               `xml:"copyright,omitempty"`
+		CCLINumber string               `xml:"ccliNo,omitempty"`
+		VerseOrder string               `xml:"verseOrder,omitempty"`
+		Songbooks  *openLyricsSongbooks `xml:"songbooks"`
+	} `xml:"properties"`
+	Verses []openLyricsVerse `xml:"lyrics>verse"`
+}
+
+var whitespaceRegexp = regexp.MustCompile(`\s+`)
+
+// openLyricsText reads the mixed content of a lines element. The line breaks are
+// the br elements (or the line elements of the older versions), not the whitespace.
+func openLyricsText(content string) (string, error) {
+	decoder := xml.NewDecoder(strings.NewReader("<lines>" + content + "</lines>"))
+	text := ""
+	skipDepth := 0
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return "", err
+		}
+
+		switch token := token.(type) {
+		case xml.StartElement:
+			if skipDepth > 0 || token.Name.Local == "comment" {
+				skipDepth++
+				continue
+			}
+
+			switch token.Name.Local {
+			case "br":
+				text += "\n"
+			case "chord":
+				if chord := openLyricsChord(token); chord != "" {
+					text += "[" + chord + "]"
+				}
+			}
+		case xml.EndElement:
+			if skipDepth > 0 {
+				skipDepth--
+			} else if token.Name.Local == "line" {
+				text += "\n"
+			}
+		case xml.CharData:
+			if skipDepth == 0 {
+				text += whitespaceRegexp.ReplaceAllString(string(token), " ")
+			}
+		}
+	}
+
+	// the empty lines would split the verse in two, since the verses are separated with them
+	lines := make([]string, 0)
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+// openLyricsChord reads the name of a chord, or builds it from the root and the bass
+// when the name is missing.
+func openLyricsChord(element xml.StartElement) string {
+	attributes := make(map[string]string)
+	for _, attr := range element.Attr {
+		attributes[attr.Name.Local] = attr.Value
+	}
+
+	if attributes["name"] != "" || attributes["root"] == "" {
+		return attributes["name"]
+	}
+
+	chord := attributes["root"]
+	if attributes["bass"] != "" {
+		chord += "/" + attributes["bass"]
+	}
+
+	return chord
+}
+
+// ParseOpenLyrics reads a song in the OpenLyrics format. The verses translated to
+// other languages than the first verse are left out.
+func ParseOpenLyrics(data []byte) (OpenLyricsSong, error) {
+	var document openLyricsDocument
+	if err := xml.Unmarshal(data, &document); err != nil {
+		return OpenLyricsSong{}, err
+	}
+
+	titles := make([]string, 0)
+	language := ""
+	for _, title := range document.Properties.Titles {
+		if value := strings.TrimSpace(title.Value); value != "" {
+			titles = append(titles, value)
+			if language == "" {
+				language = title.Lang
+			}
+		}
+	}
+	if len(titles) == 0 {
+		return OpenLyricsSong{}, errors.New("song has no title")
+	}
+
+	authors := make([]string, 0)
+	if document.Properties.Authors != nil {
+		for _, author := range document.Properties.Authors.Authors {
+			if author = strings.TrimSpace(author); author != "" {
+				authors = append(authors, author)
+			}
+		}
+	}
+
+	song := OpenLyricsSong{
+		Title:      titles[0],
+		Author:     strings.Join(authors, ", "),
+		Copyright:  strings.TrimSpace(document.Properties.Copyright),
+		CCLINumber: strings.TrimSpace(document.Properties.CCLINumber),
+		Language:   language,
+		VerseOrder: strings.Join(strings.Fields(document.Properties.VerseOrder), " "),
+		Verses:     make([]OpenLyricsVerse, 0),
+	}
+	if len(titles) > 1 {
+		song.Subtitle = titles[1]
+	}
+
+	if document.Properties.Songbooks != nil {
+		for _, songbook := range document.Properties.Songbooks.Songbooks {
+			if songbook.Entry != "" {
+				song.Songbook = songbook.Name
+				song.SongbookNumber = songbook.Entry
+				break
+			}
+		}
+	}
+
+	versesLanguage := ""
+	for i, verse := range document.Verses {
+		if i == 0 {
+			versesLanguage = verse.Lang
+		} else if verse.Lang != versesLanguage {
+			continue
+		}
+
+		lines := make([]string, 0)
+		for _, verseLines := range verse.Lines {
+			text, err := openLyricsText(verseLines.Content)
+			if err != nil {
+				return OpenLyricsSong{}, err
+			}
+			if text != "" {
+				lines = append(lines, text)
+			}
+		}
+
+		song.Verses = append(song.Verses, OpenLyricsVerse{
+			Name: strings.TrimSpace(verse.Name),
+			Text: strings.Join(lines, "\n"),
+		})
+	}
+
+	if song.Language == "" {
+		song.Language = versesLanguage
+	}
+
+	return song, nil
+}
+
+// openLyricsLinesContent writes the lines of a verse with the chords as chord elements.
+func openLyricsLinesContent(text string) string {
+	lines := strings.Split(text, "\n")
+
+	for i, line := range lines {
+		plainLine, chords := ParseChordLine(StripEmphasis(line))
+		content := ""
+		last := 0
+
+		for _, chord := range chords {
+			content += html.EscapeString(plainLine[last:chord.Position])
+			content += `<chord name="` + html.EscapeString(chord.Chord) + `"/>`
+			last = chord.Position
+		}
+		content += html.EscapeString(plainLine[last:])
+
+		lines[i] = content
+	}
+
+	return strings.Join(lines, "<br/>")
+}
+
+// BuildOpenLyrics writes a song in the OpenLyrics format.
+func BuildOpenLyrics(song OpenLyricsSong) ([]byte, error) {
+	document := openLyricsDocument{
+		Xmlns:        openLyricsNamespace,
+		Version:      openLyricsVersion,
+		CreatedIn:    producer,
+		ModifiedIn:   producer,
+		ModifiedDate: time.Now().UTC().Format(time.RFC3339),
+	}
+
+	document.Properties.Titles = []openLyricsTitle{{Lang: song.Language, Value: song.Title}}
+	if song.Subtitle != "" {
+		document.Properties.Titles = append(document.Properties.Titles, openLyricsTitle{Lang: song.Language, Value: song.Subtitle})
+	}
+
+	if song.Author != "" {
+		document.Properties.Authors = &openLyricsAuthors{[]string{song.Author}}
+	}
+
+	document.Properties.Copyright = song.Copyright
+	document.Properties.CCLINumber = song.CCLINumber
+	document.Properties.VerseOrder = song.VerseOrder
+
+	if song.SongbookNumber != "" {
+		songbook := song.Songbook
+		if songbook == "" {
+			songbook = producer
+		}
+		document.Properties.Songbooks = &openLyricsSongbooks{[]openLyricsSongbook{{Name: songbook, Entry: song.SongbookNumber}}}
+	}
+
+	for _, verse := range song.Verses {
+		document.Verses = append(document.Verses, openLyricsVerse{
+			Name:  verse.Name,
+			Lines: []openLyricsLines{{Content: openLyricsLinesContent(verse.Text)}},
+		})
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+
+	encoder := xml.NewEncoder(&buf)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(document); err != nil {
+		return nil, err
+	}
+	buf.WriteString("\n")
+
+	return buf.Bytes(), nil
+}
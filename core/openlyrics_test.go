@@ -0,0 +1,118 @@
+package core
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const openLyricsExample = `<?xml version="1.0" encoding="UTF-8"?>
+<song xmlns="http://openlyrics.info/namespace/2009/song" version="0.9" createdIn="OpenLP 3.1.2">
+  <properties>
+    <titles>
+      <title lang="pl">Pan kiedyś stanął nad brzegiem</title>
+      <title lang="pl">Barka</title>
+    </titles>
+    <authors>
+      <author>Cesáreo Gabaráin</author>
+    </authors>
+    <copyright>Ediciones Paulinas</copyright>
+    <verseOrder>v1  c v2 c</verseOrder>
+    <songbooks>
+      <songbook name="Śpiewnik" entry="123"/>
+    </songbooks>
+  </properties>
+  <lyrics>
+    <verse name="v1" lang="pl">
+      <lines><chord name="C"/>Pan kiedyś stanął nad brzegiem,<br/><br/>
+        szukał ludzi <comment>cicho</comment>gotowych pójść za Nim</lines>
+    </verse>
+    <verse name="v1" lang="en">
+      <lines>Lord, you have come to the seashore</lines>
+    </verse>
+    <verse name="c" lang="pl">
+      <lines>O Panie, to <tag name="it">Ty</tag> na mnie spojrzałeś</lines>
+    </verse>
+    <verse name="v2" lang="pl">
+      <lines><line>Jestem ubogim człowiekiem,</line><line>moim skarbem są ręce &amp; serce</line></lines>
+    </verse>
+  </lyrics>
+</song>`
+
+func TestParseOpenLyrics(t *testing.T) {
+	song, err := ParseOpenLyrics([]byte(openLyricsExample))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := OpenLyricsSong{
+		Title:          "Pan kiedyś stanął nad brzegiem",
+		Subtitle:       "Barka",
+		Author:         "Cesáreo Gabaráin",
+		Copyright:      "Ediciones Paulinas",
+		Songbook:       "Śpiewnik",
+		SongbookNumber: "123",
+		Language:       "pl",
+		VerseOrder:     "v1 c v2 c",
+		Verses: []OpenLyricsVerse{
+			{Name: "v1", Text: "[C]Pan kiedyś stanął nad brzegiem,\nszukał ludzi gotowych pójść za Nim"},
+			{Name: "c", Text: "O Panie, to Ty na mnie spojrzałeś"},
+			{Name: "v2", Text: "Jestem ubogim człowiekiem,\nmoim skarbem są ręce & serce"},
+		},
+	}
+
+	if !reflect.DeepEqual(song, expected) {
+		t.Errorf("Expected %#v, got %#v", expected, song)
+	}
+}
+
+func TestParseOpenLyricsWithoutTitle(t *testing.T) {
+	_, err := ParseOpenLyrics([]byte(`<song><properties><titles/></properties></song>`))
+	if err == nil {
+		t.Error("Expected an error for a song without a title")
+	}
+}
+
+func TestBuildOpenLyrics(t *testing.T) {
+	song := OpenLyricsSong{
+		Title:          "Pan kiedyś stanął nad brzegiem",
+		Author:         "Cesáreo Gabaráin",
+		SongbookNumber: "123",
+		Language:       "pl",
+		VerseOrder:     "v1 c v1",
+		Verses: []OpenLyricsVerse{
+			{Name: "v1", Text: "[C]Pan kiedyś stanął *nad brzegiem*,\nszukał ludzi [G]gotowych"},
+			{Name: "c", Text: "O Panie, to Ty & <nikt inny>"},
+		},
+	}
+
+	data, err := BuildOpenLyrics(song)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	document := string(data)
+	for _, expected := range []string{
+		`<song xmlns="http://openlyrics.info/namespace/2009/song" version="0.9" createdIn="goslides"`,
+		`<title lang="pl">Pan kiedyś stanął nad brzegiem</title>`,
+		`<verseOrder>v1 c v1</verseOrder>`,
+		`<songbook name="goslides" entry="123"></songbook>`,
+		`<lines><chord name="C"/>Pan kiedyś stanął nad brzegiem,<br/>szukał ludzi <chord name="G"/>gotowych</lines>`,
+		`<lines>O Panie, to Ty &amp; &lt;nikt inny&gt;</lines>`,
+	} {
+		if !strings.Contains(document, expected) {
+			t.Errorf("Expected the document to contain %q", expected)
+		}
+	}
+
+	parsed, err := ParseOpenLyrics(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	song.Songbook = producer
+	song.Verses[0].Text = StripEmphasis(song.Verses[0].Text)
+	if !reflect.DeepEqual(parsed, song) {
+		t.Errorf("Expected the song to be read back as %#v, got %#v", song, parsed)
+	}
+}
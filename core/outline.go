@@ -0,0 +1,31 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// producer names the application in the metadata of the documents.
+const producer = "goslides"
+
+// outlineTitle names the bookmark of a deck item after its title. The items
+// without one, like the texts typed in by hand, are named after their first line.
+func outlineTitle(itemIndex int, textDeck [][]string, items []ItemInfo) string {
+	title := ""
+	if itemIndex < len(items) {
+		title = StripEmphasis(items[itemIndex].Title)
+	}
+
+	if title == "" && itemIndex < len(textDeck) {
+		for _, verse := range textDeck[itemIndex] {
+			if strings.HasPrefix(verse, HintStartTag) {
+				continue
+			}
+
+			title = strings.TrimSpace(strings.SplitN(PlainVerse(StripChords(verse)), "\n", 2)[0])
+			break
+		}
+	}
+
+	return strings.TrimSpace(fmt.Sprintf("%d. %s", itemIndex+1, title))
+}
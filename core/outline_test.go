@@ -0,0 +1,26 @@
+package core
+
+import "testing"
+
+func TestOutlineTitle(t *testing.T) {
+	textDeck := [][]string{
+		{HintStartTag + "Bar" + HintEndTag, "Pan kiedyś stanął nad brzegiem"},
+		{HintStartTag + "Chw" + HintEndTag, "**Chwała** na wysokości Bogu\na na ziemi pokój"},
+	}
+	items := []ItemInfo{{Title: "Barka"}, {}}
+
+	testCases := []struct {
+		itemIndex int
+		expected  string
+	}{
+		{0, "1. Barka"},
+		{1, "2. Chwała na wysokości Bogu"},
+	}
+
+	for _, tc := range testCases {
+		result := outlineTitle(tc.itemIndex, textDeck, items)
+		if result != tc.expected {
+			t.Errorf("Expected %q, got %q", tc.expected, result)
+		}
+	}
+}
@@ -1,10 +1,11 @@
 package core
 
 import (
-	"strings"
+	"math"
 
 	"github.com/signintech/gopdf"
 	"github.com/skip2/go-qrcode"
+	"golang.org/x/image/font/sfnt"
 )
 
 type Color struct {
@@ -13,31 +14,76 @@ type Color struct {
 	B uint8
 }
 
+// Mix blends the color with another one, taking the given share of it.
+func (c Color) Mix(other Color, weight float64) Color {
+	mix := func(a uint8, b uint8) uint8 {
+		return uint8(math.Round(float64(a)*(1-weight) + float64(b)*weight))
+	}
+
+	return Color{R: mix(c.R, other.R), G: mix(c.G, other.G), B: mix(c.B, other.B)}
+}
+
 type PageConfig struct {
-	PageWidth       float64
-	PageHeight      float64
-	Margin          float64
-	FontSize        int
-	HintFontSize    int
-	LineSpacing     float64
-	Font            string
-	VerticalAlign   string
-	TextColor       Color
-	BackgroundColor Color
+	PageWidth           float64
+	PageHeight          float64
+	Margin              float64
+	FontSize            int
+	MinFontSize         int
+	FitMode             string
+	HintFontSize        int
+	HintPlacement       string
+	HintOverlayFontSize int
+	HintColor           Color
+	LineSpacing         float64
+	LineBreak           LineBreakOptions
+	Font                []byte
+	BoldFont            []byte
+	ItalicFont          []byte
+	FontFamily          string
+	VerticalAlign       string
+	HorizontalAlign     string
+	ResponseIndent      float64
+	ResponseColor       Color
+	TextColor           Color
+	BackgroundColor     Color
+	BackgroundImage     []byte
+	Logo                []byte
+	TextOutline         *TextOutline
+	TextShadow          *TextShadow
+	Translation         TranslationOptions
+	TitleSlides         bool
+	Credits             string
+	CreditsFontSize     int
+	CreditsColor        Color
+	CCLILicense         string
+	DocumentTitle       string
 }
 
+const AlignLeft = "left"
+const AlignCenter = "center"
+const AlignRight = "right"
+const AlignJustify = "justify"
+
+const FitSplit = "split"
+const FitShrink = "shrink"
+const FitShrinkThenSplit = "shrinkThenSplit"
+
 type ContentSlide struct {
 	Type       string `json:"t"`
 	ItemIndex  int    `json:"i"`
 	VerseIndex int    `json:"v"`
 	ChunkIndex int    `json:"c"`
+	// Image is the file name of the slide in the archive of slide images.
+	Image string `json:"f,omitempty"`
 }
 
 type PdfSlides struct {
-	pageConfig PageConfig
-	goPdf      *gopdf.GoPdf
-	lineHeight float64
-	maxLines   int
+	pageConfig      PageConfig
+	goPdf           *gopdf.GoPdf
+	backgroundImage gopdf.ImageHolder
+	// the glyphs are only read for the text outline
+	fonts map[string]sfntFont
+	buf   sfnt.Buffer
 }
 
 const HintStartTag = "<hint>"
@@ -47,18 +93,29 @@ func (pdf *PdfSlides) Initialize(pageConfig PageConfig) error {
 	pdf.pageConfig = pageConfig
 	pdf.goPdf = &gopdf.GoPdf{}
 
-	pdf.lineHeight = float64(pdf.pageConfig.FontSize) * pdf.pageConfig.LineSpacing
-	pdf.maxLines = int(pageConfig.PageHeight / pdf.lineHeight)
-
 	pageSize := gopdf.Rect{W: pageConfig.PageWidth, H: pageConfig.PageHeight}
 
 	pdf.goPdf.Start(gopdf.Config{PageSize: pageSize})
 
-	err := pdf.goPdf.AddTTFFont("default", pageConfig.Font)
+	err := addFonts(pdf.goPdf, pageConfig)
 	if err != nil {
 		return err
 	}
 
+	if outline := pageConfig.TextOutline; outline != nil && outline.Width > 0 {
+		pdf.fonts, err = parseFonts(pageConfig)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(pageConfig.BackgroundImage) > 0 {
+		pdf.backgroundImage, err = gopdf.ImageHolderByBytes(pageConfig.BackgroundImage)
+		if err != nil {
+			return err
+		}
+	}
+
 	pdf.addPage()
 
 	return nil
@@ -67,155 +124,123 @@ func (pdf *PdfSlides) Initialize(pageConfig PageConfig) error {
 func (pdf *PdfSlides) addPage() {
 	pdf.goPdf.AddPage()
 
+	if pdf.backgroundImage != nil && len(pdf.pageConfig.BackgroundImage) > 0 {
+		// the image holder is embedded once and referenced from every page
+		rect := &gopdf.Rect{W: pdf.pageConfig.PageWidth, H: pdf.pageConfig.PageHeight}
+		pdf.goPdf.ImageByHolder(pdf.backgroundImage, 0, 0, rect)
+		return
+	}
+
 	bgColor := pdf.pageConfig.BackgroundColor
 	pdf.goPdf.SetFillColor(bgColor.R, bgColor.G, bgColor.B)
 	pdf.goPdf.RectFromUpperLeftWithStyle(0, 0, pdf.pageConfig.PageWidth, pdf.pageConfig.PageHeight, "F")
 }
 
-func (pdf *PdfSlides) writeCenteredLine(text string) error {
-	pdf.goPdf.SetFont("default", "", pdf.pageConfig.FontSize)
+func (pdf *PdfSlides) writeCenteredLine(text string, fontSize int) error {
+	pdf.goPdf.SetFont("default", "", fontSize)
 	textWidth, err := pdf.goPdf.MeasureTextWidth(text)
 	if err != nil {
 		return err
 	}
 
-	pdf.goPdf.SetX((pdf.pageConfig.PageWidth - textWidth) / 2)
-
-	textColor := pdf.pageConfig.TextColor
-	pdf.goPdf.SetFillColor(textColor.R, textColor.G, textColor.B)
-
-	return pdf.goPdf.Cell(nil, text)
+	x := (pdf.pageConfig.PageWidth - textWidth) / 2
+	return pdf.writeTextWithEffects(text, x, pdf.goPdf.GetY(), "default", fontSize, pdf.pageConfig.TextColor)
 }
 
-func (pdf *PdfSlides) writeVerse(text string) (int, error) {
-	contentWidth := pdf.pageConfig.PageWidth - 2*pdf.pageConfig.Margin
-	pdf.goPdf.SetFont("default", "", pdf.pageConfig.FontSize)
-	lines := strings.Split(text, "\n")
-	lines = BreakLongLines(lines, pdf.goPdf.MeasureTextWidth, contentWidth)
-
-	subPages := SplitLongSlide(lines, pdf.maxLines)
-
-	for i, linesSlice := range subPages {
-		if i > 0 {
-			pdf.addPage()
-		}
-
-		err := pdf.writeAlignedParagraph(linesSlice)
-		if err != nil {
-			return 0, err
-		}
+func (pdf *PdfSlides) measureText(text string, family string, fontSize int) (float64, error) {
+	err := pdf.goPdf.SetFont(family, "", fontSize)
+	if err != nil {
+		return 0, err
 	}
 
-	return len(subPages), nil
+	return pdf.goPdf.MeasureTextWidth(text)
 }
 
-func (pdf *PdfSlides) writeAlignedParagraph(lines []string) error {
-	paragraphHeight := float64(len(lines)) * pdf.lineHeight
-	var y0 float64
-
-	switch pdf.pageConfig.VerticalAlign {
-	case "top":
-		y0 = pdf.pageConfig.Margin
-	case "bottom":
-		y0 = pdf.pageConfig.PageHeight - paragraphHeight - pdf.pageConfig.Margin
-	default:
-		y0 = (pdf.pageConfig.PageHeight - paragraphHeight) / 2
+func (pdf *PdfSlides) writeText(text string, x float64, y float64, family string, fontSize int, color Color) error {
+	err := pdf.goPdf.SetFont(family, "", fontSize)
+	if err != nil {
+		return err
 	}
 
-	return pdf.writeParagraph(lines, y0)
+	return pdf.writeTextWithEffects(text, x, y, family, fontSize, color)
 }
 
-func (pdf *PdfSlides) writeParagraph(lines []string, y0 float64) error {
-	offset := float64(pdf.pageConfig.FontSize) * (pdf.pageConfig.LineSpacing - 1) / 2
-	for index, line := range lines {
-		y := y0 + float64(index)*pdf.lineHeight + offset
-		pdf.goPdf.SetY(y)
-		err := pdf.writeCenteredLine(line)
-		if err != nil {
-			return err
-		}
+func (pdf *PdfSlides) drawQrCode(content string) error {
+	qrSize := pdf.pageConfig.qrCodeSize()
+	png, err := qrcode.Encode(content, qrcode.Medium, qrCodeResolution)
+	if err != nil {
+		return err
 	}
-	return nil
+	imageHolder, err := gopdf.ImageHolderByBytes(png)
+	if err != nil {
+		return err
+	}
+	x := (pdf.pageConfig.PageWidth - qrSize) / 2
+	y := (pdf.pageConfig.PageHeight - qrSize) / 2
+	rect := &gopdf.Rect{W: qrSize, H: qrSize}
+	if err := pdf.goPdf.ImageByHolder(imageHolder, x, y, rect); err != nil {
+		return err
+	}
+	pdf.goPdf.SetY(pdf.pageConfig.PageHeight - y + (y-float64(pdf.pageConfig.FontSize))/2)
+	return pdf.writeCenteredLine(content, pdf.pageConfig.FontSize)
 }
 
-func (pdf *PdfSlides) writeHint(text string) error {
-	pdf.goPdf.SetFont("default", "", pdf.pageConfig.HintFontSize)
-
-	pdf.goPdf.SetX(10)
-	pdf.goPdf.SetY(pdf.pageConfig.PageHeight - float64(pdf.pageConfig.HintFontSize) - 10)
-	pdf.goPdf.SetFillColor(120, 120, 120)
-	return pdf.goPdf.Cell(nil, text)
-}
+func (pdf *PdfSlides) drawLogo() error {
+	if len(pdf.pageConfig.Logo) == 0 {
+		return nil
+	}
 
-func (pdf *PdfSlides) drawQrCode(content string) {
-	qrSize := 400
-	var png []byte
-	png, err := qrcode.Encode(content, qrcode.Medium, qrSize)
+	width, height, err := fitImage(pdf.pageConfig.Logo, pdf.pageConfig.PageWidth/2, pdf.pageConfig.PageHeight/3)
 	if err != nil {
-		return
+		return err
 	}
-	imageHolder, err := gopdf.ImageHolderByBytes(png)
+
+	imageHolder, err := gopdf.ImageHolderByBytes(pdf.pageConfig.Logo)
 	if err != nil {
-		return
+		return err
 	}
-	x := (pdf.pageConfig.PageWidth - float64(qrSize)) / 2
-	y := (pdf.pageConfig.PageHeight - float64(qrSize)) / 2
-	rect := &gopdf.Rect{W: float64(qrSize), H: float64(qrSize)}
-	pdf.goPdf.ImageByHolder(imageHolder, x, y, rect)
-	pdf.goPdf.SetY(pdf.pageConfig.PageHeight - y + (y-float64(pdf.pageConfig.FontSize))/2)
-	pdf.writeCenteredLine(content)
+
+	x := (pdf.pageConfig.PageWidth - width) / 2
+	y := (pdf.pageConfig.PageHeight - height) / 2
+	return pdf.goPdf.ImageByHolder(imageHolder, x, y, &gopdf.Rect{W: width, H: height})
 }
 
-func BuildPDF(textDeck [][]string, pageConfig PageConfig) (*gopdf.GoPdf, []ContentSlide, error) {
+func BuildPDF(textDeck [][]string, items []ItemInfo, pageConfig PageConfig) (*gopdf.GoPdf, []ContentSlide, error) {
 	pdf := PdfSlides{}
 	err := pdf.Initialize(pageConfig)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	contents := make([]ContentSlide, 0)
-	contents = append(contents, ContentSlide{Type: "blank", ItemIndex: -1})
+	slides := LayoutDeck(textDeck, items, pageConfig, emphasisMeasurer(pdf.goPdf, pageConfig))
 
-	for itemIndex, song := range textDeck {
-		hint := ""
-		for verseIndex, verse := range song {
-			if strings.HasPrefix(verse, HintStartTag) && strings.HasSuffix(verse, HintEndTag) {
-				hint = verse[len(HintStartTag) : len(verse)-len(HintEndTag)]
-				continue
-			}
+	pdf.goPdf.SetInfo(gopdf.PdfInfo{Title: pageConfig.DocumentTitle, Producer: producer})
+	lastOutlined := -1
 
+	for i, slide := range slides {
+		pdf.pageConfig = SlidePageConfig(slide, items, pageConfig)
+		if i > 0 {
 			pdf.addPage()
-			if hint != "" {
-				pdf.writeHint(hint)
-				contents = append(contents, ContentSlide{Type: "hint", ItemIndex: itemIndex})
-				hint = ""
-				pdf.addPage()
-			}
-
-			isUrl := strings.HasPrefix(verse, "https://") || strings.HasPrefix(verse, "http://")
-			if isUrl {
-				contents = append(contents, ContentSlide{Type: "qr", ItemIndex: itemIndex})
-				pdf.drawQrCode(verse)
-				continue
-			}
-
-			numChunks, err := pdf.writeVerse(verse)
-			if err != nil {
-				return nil, nil, err
-			}
-
-			for i := 0; i < numChunks; i++ {
-				contents = append(contents, ContentSlide{
-					Type:       "verse",
-					ItemIndex:  itemIndex,
-					VerseIndex: verseIndex,
-					ChunkIndex: i,
-				})
-			}
 		}
-		pdf.addPage()
-		contents = append(contents, ContentSlide{Type: "blank", ItemIndex: itemIndex})
+
+		if slide.Type != "blank" && slide.ItemIndex > lastOutlined {
+			pdf.goPdf.AddOutline(outlineTitle(slide.ItemIndex, textDeck, items))
+			lastOutlined = slide.ItemIndex
+		}
+
+		switch slide.Type {
+		case "blank":
+			err = pdf.drawLogo()
+		case "qr":
+			err = pdf.drawQrCode(slide.Text)
+		default:
+			err = drawnSlideText(pdf.pageConfig, &pdf).write(slide)
+		}
+
+		if err != nil {
+			return nil, nil, err
+		}
 	}
 
-	return pdf.goPdf, contents, nil
+	return pdf.goPdf, ContentSlides(slides), nil
 }
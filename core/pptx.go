@@ -0,0 +1,524 @@
+package core
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/skip2/go-qrcode"
+)
+
+const emuPerPoint = 12700
+
+const pptxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Default Extension="png" ContentType="image/png"/>
+<Default Extension="jpeg" ContentType="image/jpeg"/>
+<Override PartName="/ppt/presentation.xml" ContentType="application/vnd.openxmlformats-officedocument.presentationml.presentation.main+xml"/>
+<Override PartName="/ppt/presProps.xml" ContentType="application/vnd.openxmlformats-officedocument.presentationml.presProps+xml"/>
+<Override PartName="/ppt/viewProps.xml" ContentType="application/vnd.openxmlformats-officedocument.presentationml.viewProps+xml"/>
+<Override PartName="/ppt/tableStyles.xml" ContentType="application/vnd.openxmlformats-officedocument.presentationml.tableStyles+xml"/>
+<Override PartName="/ppt/slideMasters/slideMaster1.xml" ContentType="application/vnd.openxmlformats-officedocument.presentationml.slideMaster+xml"/>
+<Override PartName="/ppt/slideLayouts/slideLayout1.xml" ContentType="application/vnd.openxmlformats-officedocument.presentationml.slideLayout+xml"/>
+<Override PartName="/ppt/theme/theme1.xml" ContentType="application/vnd.openxmlformats-officedocument.theme+xml"/>
+<Override PartName="/docProps/core.xml" ContentType="application/vnd.openxmlformats-package.core-properties+xml"/>
+<Override PartName="/docProps/app.xml" ContentType="application/vnd.openxmlformats-officedocument.extended-properties+xml"/>
+%s</Types>`
+
+const pptxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="ppt/presentation.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/package/2006/relationships/metadata/core-properties" Target="docProps/core.xml"/>
+<Relationship Id="rId3" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/extended-properties" Target="docProps/app.xml"/>
+</Relationships>`
+
+const pptxCoreProps = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:dcterms="http://purl.org/dc/terms/" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">
+<dc:creator>goslides</dc:creator>
+</cp:coreProperties>`
+
+const pptxAppProps = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Properties xmlns="http://schemas.openxmlformats.org/officeDocument/2006/extended-properties">
+<Application>goslides</Application>
+<Slides>%d</Slides>
+</Properties>`
+
+const pptxPresentation = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<p:presentation xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships" xmlns:p="http://schemas.openxmlformats.org/presentationml/2006/main">
+<p:sldMasterIdLst><p:sldMasterId id="2147483648" r:id="rId1"/></p:sldMasterIdLst>
+<p:sldIdLst>%s</p:sldIdLst>
+<p:sldSz cx="%d" cy="%d"/>
+<p:notesSz cx="6858000" cy="9144000"/>
+</p:presentation>`
+
+const pptxPresentationRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/slideMaster" Target="slideMasters/slideMaster1.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/theme" Target="theme/theme1.xml"/>
+<Relationship Id="rId3" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/presProps" Target="presProps.xml"/>
+<Relationship Id="rId4" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/viewProps" Target="viewProps.xml"/>
+<Relationship Id="rId5" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/tableStyles" Target="tableStyles.xml"/>
+%s</Relationships>`
+
+const pptxPresProps = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<p:presentationPr xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships" xmlns:p="http://schemas.openxmlformats.org/presentationml/2006/main"/>`
+
+const pptxViewProps = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<p:viewPr xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships" xmlns:p="http://schemas.openxmlformats.org/presentationml/2006/main"/>`
+
+const pptxTableStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<a:tblStyleLst xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" def="{5C22544A-7EE6-4342-B048-85BDC9FD1C3A}"/>`
+
+const pptxSlideMaster = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<p:sldMaster xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships" xmlns:p="http://schemas.openxmlformats.org/presentationml/2006/main">
+<p:cSld><p:bg><p:bgRef idx="1001"><a:schemeClr val="bg1"/></p:bgRef></p:bg><p:spTree><p:nvGrpSpPr><p:cNvPr id="1" name=""/><p:cNvGrpSpPr/><p:nvPr/></p:nvGrpSpPr><p:grpSpPr/></p:spTree></p:cSld>
+<p:clrMap bg1="lt1" tx1="dk1" bg2="lt2" tx2="dk2" accent1="accent1" accent2="accent2" accent3="accent3" accent4="accent4" accent5="accent5" accent6="accent6" hlink="hlink" folHlink="folHlink"/>
+<p:sldLayoutIdLst><p:sldLayoutId id="2147483649" r:id="rId1"/></p:sldLayoutIdLst>
+<p:txStyles><p:titleStyle/><p:bodyStyle/><p:otherStyle/></p:txStyles>
+</p:sldMaster>`
+
+const pptxSlideMasterRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/slideLayout" Target="../slideLayouts/slideLayout1.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/theme" Target="../theme/theme1.xml"/>
+</Relationships>`
+
+const pptxSlideLayout = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<p:sldLayout xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships" xmlns:p="http://schemas.openxmlformats.org/presentationml/2006/main" type="blank" preserve="1">
+<p:cSld name="Blank"><p:spTree><p:nvGrpSpPr><p:cNvPr id="1" name=""/><p:cNvGrpSpPr/><p:nvPr/></p:nvGrpSpPr><p:grpSpPr/></p:spTree></p:cSld>
+<p:clrMapOvr><a:masterClrMapping/></p:clrMapOvr>
+</p:sldLayout>`
+
+const pptxSlideLayoutRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/slideMaster" Target="../slideMasters/slideMaster1.xml"/>
+</Relationships>`
+
+const pptxTheme = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<a:theme xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" name="goslides">
+<a:themeElements>
+<a:clrScheme name="goslides">
+<a:dk1><a:srgbClr val="000000"/></a:dk1><a:lt1><a:srgbClr val="FFFFFF"/></a:lt1>
+<a:dk2><a:srgbClr val="44546A"/></a:dk2><a:lt2><a:srgbClr val="E7E6E6"/></a:lt2>
+<a:accent1><a:srgbClr val="4472C4"/></a:accent1><a:accent2><a:srgbClr val="ED7D31"/></a:accent2>
+<a:accent3><a:srgbClr val="A5A5A5"/></a:accent3><a:accent4><a:srgbClr val="FFC000"/></a:accent4>
+<a:accent5><a:srgbClr val="5B9BD5"/></a:accent5><a:accent6><a:srgbClr val="70AD47"/></a:accent6>
+<a:hlink><a:srgbClr val="0563C1"/></a:hlink><a:folHlink><a:srgbClr val="954F72"/></a:folHlink>
+</a:clrScheme>
+<a:fontScheme name="goslides">
+<a:majorFont><a:latin typeface="%[1]s"/><a:ea typeface=""/><a:cs typeface=""/></a:majorFont>
+<a:minorFont><a:latin typeface="%[1]s"/><a:ea typeface=""/><a:cs typeface=""/></a:minorFont>
+</a:fontScheme>
+<a:fmtScheme name="goslides">
+<a:fillStyleLst><a:solidFill><a:schemeClr val="phClr"/></a:solidFill><a:solidFill><a:schemeClr val="phClr"/></a:solidFill><a:solidFill><a:schemeClr val="phClr"/></a:solidFill></a:fillStyleLst>
+<a:lnStyleLst><a:ln w="6350"><a:solidFill><a:schemeClr val="phClr"/></a:solidFill></a:ln><a:ln w="12700"><a:solidFill><a:schemeClr val="phClr"/></a:solidFill></a:ln><a:ln w="19050"><a:solidFill><a:schemeClr val="phClr"/></a:solidFill></a:ln></a:lnStyleLst>
+<a:effectStyleLst><a:effectStyle><a:effectLst/></a:effectStyle><a:effectStyle><a:effectLst/></a:effectStyle><a:effectStyle><a:effectLst/></a:effectStyle></a:effectStyleLst>
+<a:bgFillStyleLst><a:solidFill><a:schemeClr val="phClr"/></a:solidFill><a:solidFill><a:schemeClr val="phClr"/></a:solidFill><a:solidFill><a:schemeClr val="phClr"/></a:solidFill></a:bgFillStyleLst>
+</a:fmtScheme>
+</a:themeElements>
+</a:theme>`
+
+const pptxSlide = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<p:sld xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships" xmlns:p="http://schemas.openxmlformats.org/presentationml/2006/main">
+<p:cSld><p:bg><p:bgPr>%s<a:effectLst/></p:bgPr></p:bg><p:spTree><p:nvGrpSpPr><p:cNvPr id="1" name=""/><p:cNvGrpSpPr/><p:nvPr/></p:nvGrpSpPr><p:grpSpPr/>%s</p:spTree></p:cSld>
+<p:clrMapOvr><a:masterClrMapping/></p:clrMapOvr>
+</p:sld>`
+
+const pptxSlideRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/slideLayout" Target="../slideLayouts/slideLayout1.xml"/>
+%s</Relationships>`
+
+const pptxTextBox = `<p:sp><p:nvSpPr><p:cNvPr id="%d" name="%s"/><p:cNvSpPr txBox="1"/><p:nvPr/></p:nvSpPr><p:spPr><a:xfrm><a:off x="%d" y="%d"/><a:ext cx="%d" cy="%d"/></a:xfrm><a:prstGeom prst="rect"><a:avLst/></a:prstGeom><a:noFill/></p:spPr><p:txBody><a:bodyPr wrap="%s" lIns="0" tIns="0" rIns="0" bIns="0" anchor="%s"><a:noAutofit/></a:bodyPr><a:lstStyle/>%s</p:txBody></p:sp>`
+
+const pptxPicture = `<p:pic><p:nvPicPr><p:cNvPr id="%d" name="%s"/><p:cNvPicPr><a:picLocks noChangeAspect="1"/></p:cNvPicPr><p:nvPr/></p:nvPicPr><p:blipFill><a:blip r:embed="%s"/><a:stretch><a:fillRect/></a:stretch></p:blipFill><p:spPr><a:xfrm><a:off x="%d" y="%d"/><a:ext cx="%d" cy="%d"/></a:xfrm><a:prstGeom prst="rect"><a:avLst/></a:prstGeom></p:spPr></p:pic>`
+
+const pptxImageRel = `<Relationship Id="%s" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/image" Target="../media/%s"/>
+`
+
+func escapeXML(text string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(text))
+	return b.String()
+}
+
+func toEMU(points float64) int {
+	return int(points * emuPerPoint)
+}
+
+func (c Color) Hex() string {
+	return fmt.Sprintf("%02X%02X%02X", c.R, c.G, c.B)
+}
+
+type pptxWriter struct {
+	pageConfig PageConfig
+	zipWriter  *zip.Writer
+	numImages  int
+	// media are the names of the images written so far, by their data
+	media map[string]string
+}
+
+func (w *pptxWriter) writeFile(name string, content string) error {
+	f, err := w.zipWriter.Create(name)
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write([]byte(content))
+	return err
+}
+
+// addImage writes an image into the media folder once and returns its name.
+func (w *pptxWriter) addImage(prefix string, data []byte) (string, error) {
+	if name, ok := w.media[string(data)]; ok {
+		return name, nil
+	}
+
+	format, err := imageFormat(data)
+	if err != nil {
+		return "", err
+	}
+
+	w.numImages++
+	name := fmt.Sprintf("%s%d.%s", prefix, w.numImages, format)
+	if err := w.writeFile("ppt/media/"+name, string(data)); err != nil {
+		return "", err
+	}
+	w.media[string(data)] = name
+
+	return name, nil
+}
+
+func (w *pptxWriter) textParagraph(runs []TextRun, fontSize int, color Color, align string, marginLeft float64) string {
+	lineSpacing := int(float64(fontSize) * w.pageConfig.LineSpacing * 100)
+
+	// an empty run keeps the line height of empty lines
+	if len(runs) == 0 {
+		runs = []TextRun{{}}
+	}
+
+	textRuns := ""
+	for _, run := range runs {
+		style := ""
+		if run.Bold {
+			style += ` b="1"`
+		}
+		if run.Italic {
+			style += ` i="1"`
+		}
+
+		textRuns += fmt.Sprintf(`<a:r><a:rPr lang="pl-PL" sz="%d"%s dirty="0"><a:solidFill><a:srgbClr val="%s"/></a:solidFill><a:latin typeface="%s"/></a:rPr><a:t>%s</a:t></a:r>`,
+			fontSize*100, style, color.Hex(), escapeXML(w.pageConfig.FontFamily), escapeXML(run.Text))
+	}
+
+	return fmt.Sprintf(`<a:p><a:pPr marL="%d" algn="%s"><a:lnSpc><a:spcPts val="%d"/></a:lnSpc></a:pPr>%s</a:p>`,
+		toEMU(marginLeft), align, lineSpacing, textRuns)
+}
+
+func (w *pptxWriter) textParagraphs(lines []string, fontSize int, color Color, align string) string {
+	paragraphs := ""
+	for _, line := range lines {
+		paragraphs += w.textParagraph([]TextRun{{Text: line}}, fontSize, color, align, 0)
+	}
+
+	return paragraphs
+}
+
+// verseParagraphs writes every line as a separate paragraph. Justified lines
+// are joined back into paragraphs though, because PowerPoint doesn't stretch
+// the last line of a paragraph, so the text box wraps them on its own.
+func (w *pptxWriter) verseParagraphs(lines []SlideLine, fontSize int) string {
+	align := "ctr"
+	switch w.pageConfig.HorizontalAlign {
+	case AlignLeft:
+		align = "l"
+	case AlignRight:
+		align = "r"
+	case AlignJustify:
+		align = "just"
+	}
+
+	paragraphs := ""
+	runs := make([]TextRun, 0)
+	for i, line := range lines {
+		for _, run := range ParseEmphasis(line.Text) {
+			runs = appendRun(runs, run)
+		}
+
+		isParagraphEnd := align != "just" || line.ParagraphEnd || i == len(lines)-1 ||
+			lines[i+1].Response != line.Response || lines[i+1].Translation != line.Translation
+		if !isParagraphEnd {
+			// words split by the hyphenation are joined back, the text box may break them elsewhere
+			last := len(runs) - 1
+			if last >= 0 && strings.HasSuffix(runs[last].Text, "-") && w.pageConfig.LineBreak.hyphenates() {
+				runs[last].Text = strings.TrimSuffix(runs[last].Text, "-")
+			} else {
+				runs = appendRun(runs, TextRun{Text: " "})
+			}
+			continue
+		}
+
+		lineFontSize := w.pageConfig.lineFontSize(line, fontSize)
+		color := w.pageConfig.TextColor
+		if line.Translation {
+			color = w.pageConfig.Translation.Color
+		}
+
+		indent := 0.0
+		if line.Response {
+			color = w.pageConfig.ResponseColor
+			indent = w.pageConfig.ResponseIndentAt(lineFontSize)
+		}
+		if align == "ctr" || align == "r" {
+			indent = 0
+		}
+
+		paragraphs += w.textParagraph(runs, lineFontSize, color, align, indent)
+		runs = make([]TextRun, 0)
+	}
+
+	return paragraphs
+}
+
+func (w *pptxWriter) verseShapes(slide Slide) string {
+	margin := w.pageConfig.Margin
+	height := w.pageConfig.ContentHeight(slide) - 2*margin
+
+	if w.pageConfig.isColumnLayout() {
+		original, translation := SplitColumns(slide.Lines)
+		if len(translation) > 0 {
+			columnWidth := w.pageConfig.TranslationColumnWidth()
+			return w.verseShape(2, "Verse", original, slide.FontSize, margin, columnWidth, height) +
+				w.verseShape(3, "Translation", translation, slide.FontSize, w.pageConfig.PageWidth-margin-columnWidth, columnWidth, height)
+		}
+	}
+
+	return w.verseShape(2, "Verse", slide.Lines, slide.FontSize, margin, w.pageConfig.ContentWidth(), height)
+}
+
+func (w *pptxWriter) verseShape(id int, name string, lines []SlideLine, fontSize int, x float64, width float64, height float64) string {
+	anchor := "ctr"
+	switch w.pageConfig.VerticalAlign {
+	case "top":
+		anchor = "t"
+	case "bottom":
+		anchor = "b"
+	}
+
+	margin := w.pageConfig.Margin
+	paragraphs := w.verseParagraphs(lines, fontSize)
+
+	wrap := "none"
+	if w.pageConfig.HorizontalAlign == AlignJustify {
+		wrap = "square"
+	}
+
+	return fmt.Sprintf(pptxTextBox, id, name,
+		toEMU(x), toEMU(margin), toEMU(width), toEMU(height),
+		wrap, anchor, paragraphs)
+}
+
+func (w *pptxWriter) titleShape(slide Slide) string {
+	paragraphs := ""
+	for _, line := range slide.Lines {
+		paragraphs += w.textParagraph(ParseEmphasis(line.Text), slide.FontSize, w.pageConfig.TextColor, "ctr", 0)
+	}
+	paragraphs += w.textParagraphs(slide.TitleDetails(), w.pageConfig.HintFontSize, w.pageConfig.TextColor, "ctr")
+
+	margin := w.pageConfig.Margin
+	return fmt.Sprintf(pptxTextBox, 2, "Title",
+		toEMU(margin), toEMU(margin), toEMU(w.pageConfig.ContentWidth()), toEMU(w.pageConfig.PageHeight-2*margin),
+		"none", "ctr", paragraphs)
+}
+
+func (w *pptxWriter) creditsShape(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+
+	fontSize := w.pageConfig.CreditsFontSize
+	paragraphs := w.textParagraphs(lines, fontSize, w.pageConfig.CreditsColor, "ctr")
+	y := w.pageConfig.PageHeight - w.pageConfig.CreditsHeight(lines)
+
+	return fmt.Sprintf(pptxTextBox, 4, "Credits",
+		toEMU(w.pageConfig.Margin), toEMU(y), toEMU(w.pageConfig.ContentWidth()), toEMU(float64(len(lines))*w.pageConfig.LineHeightAt(fontSize)),
+		"none", "t", paragraphs)
+}
+
+func (w *pptxWriter) hintShape(slide Slide) string {
+	if len(slide.Hint) == 0 {
+		return ""
+	}
+
+	hintFontSize := w.pageConfig.HintFontSizeOf(slide)
+	paragraphs := w.textParagraphs(slide.Hint, hintFontSize, w.pageConfig.HintColor, "l")
+	x, y := w.pageConfig.HintPosition(slide)
+
+	return fmt.Sprintf(pptxTextBox, 5, "Hint",
+		toEMU(x), toEMU(y), toEMU(w.pageConfig.hintWidth()), toEMU(float64(len(slide.Hint))*w.pageConfig.LineHeightAt(hintFontSize)),
+		"none", "t", paragraphs)
+}
+
+func (w *pptxWriter) qrShapes(content string) (string, string, error) {
+	qrSize := w.pageConfig.qrCodeSize()
+	png, err := qrcode.Encode(content, qrcode.Medium, qrCodeResolution)
+	if err != nil {
+		return "", "", err
+	}
+
+	imageName, err := w.addImage("qr", png)
+	if err != nil {
+		return "", "", err
+	}
+
+	x := (w.pageConfig.PageWidth - qrSize) / 2
+	y := (w.pageConfig.PageHeight - qrSize) / 2
+	picture := fmt.Sprintf(pptxPicture, 2, "QR code", "rId2",
+		toEMU(x), toEMU(y), toEMU(qrSize), toEMU(qrSize))
+
+	textY := w.pageConfig.PageHeight - y + (y-float64(w.pageConfig.FontSize))/2
+	paragraphs := w.textParagraphs([]string{content}, w.pageConfig.FontSize, w.pageConfig.TextColor, "ctr")
+	caption := fmt.Sprintf(pptxTextBox, 3, "URL",
+		0, toEMU(textY), toEMU(w.pageConfig.PageWidth), toEMU(w.pageConfig.LineHeight()),
+		"none", "t", paragraphs)
+
+	return picture + caption, fmt.Sprintf(pptxImageRel, "rId2", imageName), nil
+}
+
+func (w *pptxWriter) logoShape() (string, string, error) {
+	if len(w.pageConfig.Logo) == 0 {
+		return "", "", nil
+	}
+
+	width, height, err := fitImage(w.pageConfig.Logo, w.pageConfig.PageWidth/2, w.pageConfig.PageHeight/3)
+	if err != nil {
+		return "", "", err
+	}
+
+	imageName, err := w.addImage("logo", w.pageConfig.Logo)
+	if err != nil {
+		return "", "", err
+	}
+
+	x := (w.pageConfig.PageWidth - width) / 2
+	y := (w.pageConfig.PageHeight - height) / 2
+	picture := fmt.Sprintf(pptxPicture, 2, "Logo", "rIdLogo", toEMU(x), toEMU(y), toEMU(width), toEMU(height))
+
+	return picture, fmt.Sprintf(pptxImageRel, "rIdLogo", imageName), nil
+}
+
+// background returns the fill of the slide background, and its relationship for a background image.
+func (w *pptxWriter) background() (string, string, error) {
+	if len(w.pageConfig.BackgroundImage) == 0 {
+		return fmt.Sprintf(`<a:solidFill><a:srgbClr val="%s"/></a:solidFill>`, w.pageConfig.BackgroundColor.Hex()), "", nil
+	}
+
+	imageName, err := w.addImage("background", w.pageConfig.BackgroundImage)
+	if err != nil {
+		return "", "", err
+	}
+
+	fill := `<a:blipFill dpi="0" rotWithShape="1"><a:blip r:embed="rIdBackground"/><a:srcRect/><a:stretch><a:fillRect/></a:stretch></a:blipFill>`
+	return fill, fmt.Sprintf(pptxImageRel, "rIdBackground", imageName), nil
+}
+
+func (w *pptxWriter) writeSlide(number int, slide Slide) error {
+	background, rels, err := w.background()
+	if err != nil {
+		return err
+	}
+
+	shapes := ""
+	shapeRels := ""
+
+	switch slide.Type {
+	case "blank":
+		shapes, shapeRels, err = w.logoShape()
+	case "hint":
+		shapes = w.hintShape(slide)
+	case "qr":
+		shapes, shapeRels, err = w.qrShapes(slide.Text)
+	case "title":
+		shapes = w.titleShape(slide)
+	case "verse":
+		shapes = w.verseShapes(slide) + w.creditsShape(slide.Credits) + w.hintShape(slide)
+	}
+	if err != nil {
+		return err
+	}
+	rels += shapeRels
+
+	content := fmt.Sprintf(pptxSlide, background, shapes)
+	if err := w.writeFile(fmt.Sprintf("ppt/slides/slide%d.xml", number), content); err != nil {
+		return err
+	}
+
+	return w.writeFile(fmt.Sprintf("ppt/slides/_rels/slide%d.xml.rels", number), fmt.Sprintf(pptxSlideRels, rels))
+}
+
+func BuildPPTX(textDeck [][]string, items []ItemInfo, pageConfig PageConfig) (*bytes.Buffer, []ContentSlide, error) {
+	measure, err := NewFontMeasurer(pageConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	slides := LayoutDeck(textDeck, items, pageConfig, measure)
+
+	buf := new(bytes.Buffer)
+	w := &pptxWriter{pageConfig: pageConfig, zipWriter: zip.NewWriter(buf), media: make(map[string]string)}
+
+	slideOverrides := ""
+	slideIDs := ""
+	slideRels := ""
+	for i := range slides {
+		number := i + 1
+		slideOverrides += fmt.Sprintf(`<Override PartName="/ppt/slides/slide%d.xml" ContentType="application/vnd.openxmlformats-officedocument.presentationml.slide+xml"/>
+`, number)
+		slideIDs += fmt.Sprintf(`<p:sldId id="%d" r:id="rIdSlide%d"/>`, 255+number, number)
+		slideRels += fmt.Sprintf(`<Relationship Id="rIdSlide%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/slide" Target="slides/slide%d.xml"/>
+`, number, number)
+	}
+
+	files := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", fmt.Sprintf(pptxContentTypes, slideOverrides)},
+		{"_rels/.rels", pptxRootRels},
+		{"docProps/core.xml", pptxCoreProps},
+		{"docProps/app.xml", fmt.Sprintf(pptxAppProps, len(slides))},
+		{"ppt/presentation.xml", fmt.Sprintf(pptxPresentation, slideIDs, toEMU(pageConfig.PageWidth), toEMU(pageConfig.PageHeight))},
+		{"ppt/_rels/presentation.xml.rels", fmt.Sprintf(pptxPresentationRels, slideRels)},
+		{"ppt/presProps.xml", pptxPresProps},
+		{"ppt/viewProps.xml", pptxViewProps},
+		{"ppt/tableStyles.xml", pptxTableStyles},
+		{"ppt/slideMasters/slideMaster1.xml", pptxSlideMaster},
+		{"ppt/slideMasters/_rels/slideMaster1.xml.rels", pptxSlideMasterRels},
+		{"ppt/slideLayouts/slideLayout1.xml", pptxSlideLayout},
+		{"ppt/slideLayouts/_rels/slideLayout1.xml.rels", pptxSlideLayoutRels},
+		{"ppt/theme/theme1.xml", fmt.Sprintf(pptxTheme, escapeXML(pageConfig.FontFamily))},
+	}
+
+	for _, file := range files {
+		if err := w.writeFile(file.name, file.content); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	for i, slide := range slides {
+		w.pageConfig = SlidePageConfig(slide, items, pageConfig)
+		if err := w.writeSlide(i+1, slide); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := w.zipWriter.Close(); err != nil {
+		return nil, nil, err
+	}
+
+	return buf, ContentSlides(slides), nil
+}
@@ -0,0 +1,164 @@
+package core
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+// readArchive reads all the files of a zip archive, in their order.
+func readArchive(t *testing.T, data []byte) ([]*zip.File, map[string]string) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := make(map[string]string)
+	for _, file := range archive.File {
+		reader, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[file.Name] = string(content)
+	}
+
+	return archive.File, files
+}
+
+func checkWellFormed(t *testing.T, name string, content string) {
+	decoder := xml.NewDecoder(strings.NewReader(content))
+	for {
+		_, err := decoder.Token()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			t.Errorf("Expected %s to be well-formed XML, got %v", name, err)
+			return
+		}
+	}
+}
+
+// testPageConfig is the page config the outputs are tested with, set in the bundled font.
+func testPageConfig(t *testing.T) PageConfig {
+	t.Helper()
+
+	font, err := os.ReadFile("../fonts/source-sans-pro.ttf")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return PageConfig{PageWidth: 768, PageHeight: 432, Margin: 8, FontSize: 52, HintFontSize: 34, LineSpacing: 1.3, Font: font, FontFamily: "Source Sans Pro", TextColor: Color{255, 255, 255}}
+}
+
+func TestBuildPPTX(t *testing.T) {
+	pageConfig := testPageConfig(t)
+	textDeck := [][]string{
+		{"Pan kiedyś stanął nad brzegiem", "Szukał ludzi gotowych pójść za Nim & <innymi>"},
+		{"https://example.com/?a=1&b=2"},
+	}
+
+	buf, contents, err := BuildPPTX(textDeck, nil, pageConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, files := readArchive(t, buf.Bytes())
+	for name, content := range files {
+		if strings.HasSuffix(name, ".xml") || strings.HasSuffix(name, ".rels") {
+			checkWellFormed(t, name, content)
+		}
+	}
+
+	numSlides := 0
+	for name := range files {
+		if strings.HasPrefix(name, "ppt/slides/slide") {
+			numSlides++
+		}
+	}
+	if numSlides != len(contents) {
+		t.Errorf("Expected a slide per content slide, got %d slides for %d content slides", numSlides, len(contents))
+	}
+
+	contentTypes := files["[Content_Types].xml"]
+	for i := range contents {
+		override := fmt.Sprintf(`<Override PartName="/ppt/slides/slide%d.xml" `, i+1)
+		if !strings.Contains(contentTypes, override) {
+			t.Errorf("Expected the content types to list slide %d", i+1)
+		}
+		if _, ok := files[fmt.Sprintf("ppt/slides/_rels/slide%d.xml.rels", i+1)]; !ok {
+			t.Errorf("Expected the relationships of slide %d", i+1)
+		}
+	}
+	if !strings.Contains(files["docProps/app.xml"], fmt.Sprintf("<Slides>%d</Slides>", len(contents))) {
+		t.Errorf("Expected the properties to count %d slides", len(contents))
+	}
+
+	if _, ok := files["ppt/media/qr1.png"]; !ok {
+		t.Error("Expected the QR code image of the link")
+	}
+
+	slides := ""
+	for i := range contents {
+		slides += files[fmt.Sprintf("ppt/slides/slide%d.xml", i+1)]
+	}
+	for _, expected := range []string{"Nim &amp; &lt;innymi&gt;", "?a=1&amp;b=2"} {
+		if !strings.Contains(slides, expected) {
+			t.Errorf("Expected the slides to contain %q", expected)
+		}
+	}
+}
+
+func TestBuildPPTXImages(t *testing.T) {
+	background, err := PrepareBackgroundImage(testImage(100, 100), 768, 432, BackgroundCover, 0, Color{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pageConfig := testPageConfig(t)
+	pageConfig.BackgroundImage = background
+	pageConfig.Logo = testImage(40, 20)
+	textDeck := [][]string{{"Pan kiedyś stanął nad brzegiem"}}
+
+	buf, _, err := BuildPPTX(textDeck, nil, pageConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, files := readArchive(t, buf.Bytes())
+	if files["ppt/media/background1.jpeg"] != string(background) {
+		t.Error("Expected the background image to be written once")
+	}
+	if files["ppt/media/logo2.png"] != string(pageConfig.Logo) {
+		t.Error("Expected the logo to be written")
+	}
+	if !strings.Contains(files["[Content_Types].xml"], `<Default Extension="jpeg" ContentType="image/jpeg"/>`) {
+		t.Error("Expected the content types to include JPEG images")
+	}
+
+	// the deck starts with a blank slide, which shows the logo
+	blankSlide := files["ppt/slides/slide1.xml"]
+	blankRels := files["ppt/slides/_rels/slide1.xml.rels"]
+	if !strings.Contains(blankSlide, `<a:blip r:embed="rIdLogo"/>`) || !strings.Contains(blankRels, `Target="../media/logo2.png"`) {
+		t.Error("Expected the blank slide to show the logo")
+	}
+
+	verseSlide := files["ppt/slides/slide2.xml"]
+	verseRels := files["ppt/slides/_rels/slide2.xml.rels"]
+	if !strings.Contains(verseSlide, `<a:blip r:embed="rIdBackground"/>`) || !strings.Contains(verseRels, `Target="../media/background1.jpeg"`) {
+		t.Error("Expected the slide to have the background image")
+	}
+	if strings.Contains(verseSlide, "rIdLogo") {
+		t.Error("Expected only the blank slides to show the logo")
+	}
+}
@@ -0,0 +1,338 @@
+package core
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"time"
+
+	"github.com/skip2/go-qrcode"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+const ImagePNG = "png"
+const ImageJPEG = "jpeg"
+
+// ImageOptions sets the format and the size in pixels of the slide images.
+type ImageOptions struct {
+	Format string
+	Width  int
+	Height int
+}
+
+type rasterFont struct {
+	font *opentype.Font
+	// ascent is the typographic ascender per point of the font size, which places
+	// the text below its top edge the same way as gopdf does
+	ascent float64
+}
+
+type rasterFace struct {
+	family   string
+	fontSize int
+}
+
+// RasterSlides draws the slides as images, using the same layout as the PDF.
+// The positions are in points, like in the PDF, and scaled to pixels when drawing.
+type RasterSlides struct {
+	pageConfig PageConfig
+	scale      float64
+	canvas     *image.RGBA
+	fonts      map[string]rasterFont
+	faces      map[rasterFace]font.Face
+	// the background image is scaled to the canvas once, and reused while it stays the same
+	backgroundData  []byte
+	backgroundImage image.Image
+}
+
+func (r *RasterSlides) Initialize(pageConfig PageConfig, options ImageOptions) error {
+	r.pageConfig = pageConfig
+	r.scale = float64(options.Width) / pageConfig.PageWidth
+	r.canvas = image.NewRGBA(image.Rect(0, 0, options.Width, options.Height))
+	r.fonts = make(map[string]rasterFont)
+	r.faces = make(map[rasterFace]font.Face)
+
+	fonts := map[string][]byte{
+		"default": pageConfig.Font,
+		"bold":    pageConfig.BoldFont,
+		"italic":  pageConfig.ItalicFont,
+	}
+
+	for family, data := range fonts {
+		if len(data) == 0 {
+			continue
+		}
+
+		parsed, err := opentype.Parse(data)
+		if err != nil {
+			return err
+		}
+
+		ascent, err := fontAscent(data)
+		if err != nil {
+			return err
+		}
+
+		r.fonts[family] = rasterFont{font: parsed, ascent: ascent}
+	}
+
+	return nil
+}
+
+func (r *RasterSlides) face(family string, fontSize int) (font.Face, error) {
+	key := rasterFace{family, fontSize}
+	if face, ok := r.faces[key]; ok {
+		return face, nil
+	}
+
+	face, err := opentype.NewFace(r.fonts[family].font, &opentype.FaceOptions{
+		Size:    float64(fontSize) * r.scale,
+		DPI:     72,
+		Hinting: font.HintingNone,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.faces[key] = face
+	return face, nil
+}
+
+func (r *RasterSlides) px(points float64) int {
+	return int(math.Round(points * r.scale))
+}
+
+func (r *RasterSlides) clear() error {
+	background := r.pageConfig.BackgroundImage
+	if len(background) == 0 {
+		bgColor := r.pageConfig.BackgroundColor
+		draw.Draw(r.canvas, r.canvas.Bounds(), image.NewUniform(color.RGBA{bgColor.R, bgColor.G, bgColor.B, 255}), image.Point{}, draw.Src)
+		return nil
+	}
+
+	if !bytes.Equal(background, r.backgroundData) {
+		src, _, err := image.Decode(bytes.NewReader(background))
+		if err != nil {
+			return err
+		}
+
+		scaled := image.NewRGBA(r.canvas.Bounds())
+		draw.CatmullRom.Scale(scaled, scaled.Bounds(), src, src.Bounds(), draw.Src, nil)
+		r.backgroundData = background
+		r.backgroundImage = scaled
+	}
+
+	draw.Draw(r.canvas, r.canvas.Bounds(), r.backgroundImage, image.Point{}, draw.Src)
+	return nil
+}
+
+func (r *RasterSlides) measureText(text string, family string, fontSize int) (float64, error) {
+	face, err := r.face(family, fontSize)
+	if err != nil {
+		return 0, err
+	}
+
+	return float64(font.MeasureString(face, text)) / 64 / r.scale, nil
+}
+
+// drawString draws the text with its top edge at y, like a PDF cell.
+func (r *RasterSlides) drawString(text string, x float64, y float64, family string, fontSize int, c color.Color) error {
+	face, err := r.face(family, fontSize)
+	if err != nil {
+		return err
+	}
+
+	baseline := y + r.fonts[family].ascent*float64(fontSize)
+	drawer := font.Drawer{
+		Dst:  r.canvas,
+		Src:  image.NewUniform(c),
+		Face: face,
+		Dot:  fixed.Point26_6{X: fixed.Int26_6(x * r.scale * 64), Y: fixed.Int26_6(baseline * r.scale * 64)},
+	}
+	drawer.DrawString(text)
+
+	return nil
+}
+
+// writeText draws the text over its shadow and outline, made of shifted
+// copies of the text.
+func (r *RasterSlides) writeText(text string, x float64, y float64, family string, fontSize int, textColor Color) error {
+	if shadow := r.pageConfig.TextShadow; shadow != nil {
+		offsets := [][2]float64{{0, 0}}
+		if shadow.Blur > 0 {
+			offsets = append(offsets, ringOffsets(shadow.Blur/2, textEffectDirections)...)
+			offsets = append(offsets, ringOffsets(shadow.Blur, textEffectDirections)...)
+		}
+
+		alpha := 1 - math.Pow(1-shadow.Opacity, 1/float64(len(offsets)))
+		shadowColor := color.NRGBA{shadow.Color.R, shadow.Color.G, shadow.Color.B, uint8(math.Max(1, math.Round(alpha*255)))}
+		for _, offset := range offsets {
+			err := r.drawString(text, x+shadow.Offset+offset[0], y+shadow.Offset+offset[1], family, fontSize, shadowColor)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if outline := r.pageConfig.TextOutline; outline != nil && outline.Width > 0 {
+		outlineColor := color.RGBA{outline.Color.R, outline.Color.G, outline.Color.B, 255}
+		offsets := append(ringOffsets(outline.Width/2, textEffectDirections), ringOffsets(outline.Width, textEffectDirections)...)
+		for _, offset := range offsets {
+			err := r.drawString(text, x+offset[0], y+offset[1], family, fontSize, outlineColor)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return r.drawString(text, x, y, family, fontSize, color.RGBA{textColor.R, textColor.G, textColor.B, 255})
+}
+
+// drawImage scales an image into a box given in points.
+func (r *RasterSlides) drawImage(src image.Image, x float64, y float64, width float64, height float64) {
+	rect := image.Rect(r.px(x), r.px(y), r.px(x+width), r.px(y+height))
+	draw.CatmullRom.Scale(r.canvas, rect, src, src.Bounds(), draw.Over, nil)
+}
+
+func (r *RasterSlides) drawQrCode(content string) error {
+	qrSize := r.pageConfig.qrCodeSize()
+	qr, err := qrcode.New(content, qrcode.Medium)
+	if err != nil {
+		return err
+	}
+
+	x := (r.pageConfig.PageWidth - qrSize) / 2
+	y := (r.pageConfig.PageHeight - qrSize) / 2
+	r.drawImage(qr.Image(r.px(qrSize)), x, y, qrSize, qrSize)
+
+	fontSize := r.pageConfig.FontSize
+	textWidth, err := r.measureText(content, "default", fontSize)
+	if err != nil {
+		return err
+	}
+
+	return r.writeText(content, (r.pageConfig.PageWidth-textWidth)/2, r.pageConfig.PageHeight-y+(y-float64(fontSize))/2, "default", fontSize, r.pageConfig.TextColor)
+}
+
+func (r *RasterSlides) drawLogo() error {
+	if len(r.pageConfig.Logo) == 0 {
+		return nil
+	}
+
+	width, height, err := fitImage(r.pageConfig.Logo, r.pageConfig.PageWidth/2, r.pageConfig.PageHeight/3)
+	if err != nil {
+		return err
+	}
+
+	logo, _, err := image.Decode(bytes.NewReader(r.pageConfig.Logo))
+	if err != nil {
+		return err
+	}
+
+	r.drawImage(logo, (r.pageConfig.PageWidth-width)/2, (r.pageConfig.PageHeight-height)/2, width, height)
+	return nil
+}
+
+func (r *RasterSlides) drawSlide(slide Slide) error {
+	err := r.clear()
+	if err != nil {
+		return err
+	}
+
+	switch slide.Type {
+	case "blank":
+		return r.drawLogo()
+	case "qr":
+		return r.drawQrCode(slide.Text)
+	default:
+		return drawnSlideText(r.pageConfig, r).write(slide)
+	}
+}
+
+func (r *RasterSlides) encode(buf *bytes.Buffer, format string) error {
+	if format == ImageJPEG {
+		return jpeg.Encode(buf, r.canvas, &jpeg.Options{Quality: 90})
+	}
+
+	return png.Encode(buf, r.canvas)
+}
+
+// BuildImages draws every slide as a PNG or JPEG image and packs them into a ZIP
+// archive, along with the manifest of the slides which names their images.
+func BuildImages(textDeck [][]string, items []ItemInfo, pageConfig PageConfig, options ImageOptions) (*bytes.Buffer, []ContentSlide, error) {
+	measure, err := NewFontMeasurer(pageConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	raster := RasterSlides{}
+	err = raster.Initialize(pageConfig, options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	extension := ".png"
+	if options.Format == ImageJPEG {
+		extension = ".jpg"
+	}
+
+	slides := LayoutDeck(textDeck, items, pageConfig, measure)
+	contents := ContentSlides(slides)
+
+	buf := new(bytes.Buffer)
+	archive := zip.NewWriter(buf)
+	now := time.Now()
+
+	for i, slide := range slides {
+		raster.pageConfig = SlidePageConfig(slide, items, pageConfig)
+		err = raster.drawSlide(slide)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		contents[i].Image = fmt.Sprintf("slide-%03d%s", i+1, extension)
+
+		// the images are compressed already
+		file, err := archive.CreateHeader(&zip.FileHeader{Name: contents[i].Image, Method: zip.Store, Modified: now})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		imageBuf := new(bytes.Buffer)
+		err = raster.encode(imageBuf, options.Format)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		_, err = file.Write(imageBuf.Bytes())
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	manifest, err := archive.CreateHeader(&zip.FileHeader{Name: "manifest.json", Method: zip.Deflate, Modified: now})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = json.NewEncoder(manifest).Encode(contents)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = archive.Close()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return buf, contents, nil
+}
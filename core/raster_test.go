@@ -0,0 +1,49 @@
+package core
+
+import (
+	"archive/zip"
+	"bytes"
+	"image"
+	_ "image/png"
+	"testing"
+)
+
+func TestBuildImages(t *testing.T) {
+	pageConfig := testPageConfig(t)
+	textDeck := [][]string{{"Pan kiedyś stanął nad brzegiem"}}
+
+	buf, contents, err := BuildImages(textDeck, nil, pageConfig, ImageOptions{Format: ImagePNG, Width: 384, Height: 216})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(archive.File) != len(contents)+1 {
+		t.Fatalf("Expected an image per slide and a manifest, got %d files for %d slides", len(archive.File), len(contents))
+	}
+
+	for i, slide := range contents {
+		file := archive.File[i]
+		if file.Name != slide.Image {
+			t.Errorf("Expected slide %d to name its image %q, got %q", i, file.Name, slide.Image)
+		}
+
+		reader, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		config, format, err := image.DecodeConfig(reader)
+		reader.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if format != "png" || config.Width != 384 || config.Height != 216 {
+			t.Errorf("Expected a 384x216 PNG, got a %dx%d %s", config.Width, config.Height, format)
+		}
+	}
+}
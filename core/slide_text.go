@@ -0,0 +1,254 @@
+package core
+
+import "strings"
+
+// textDrawer measures and draws text in the formats which place every word themselves.
+type textDrawer interface {
+	measureText(text string, family string, fontSize int) (float64, error)
+	// writeText draws the text with its effects, with its top edge at y like a PDF cell.
+	writeText(text string, x float64, y float64, family string, fontSize int, color Color) error
+}
+
+// lineWriter writes the lines of text of a slide. The lines are placed by the top edge
+// of their box, which is as tall as the line height, and the hints by the top edge of the text.
+type lineWriter interface {
+	writeVerseLine(line SlideLine, y float64, fontSize int, left float64, width float64) error
+	writeCenteredRuns(runs []TextRun, y float64, fontSize int, color Color) error
+	writeHintLine(text string, x float64, y float64, fontSize int, color Color) error
+}
+
+// slideText positions the text of the slides, the same way in all the formats.
+type slideText struct {
+	pageConfig PageConfig
+	lines      lineWriter
+}
+
+// write writes the text of a hint, a title or a verse slide.
+func (t slideText) write(slide Slide) error {
+	switch slide.Type {
+	case "hint":
+		return t.writeHint(slide)
+	case "title":
+		return t.writeTitle(slide)
+	case "verse":
+		err := t.writeAlignedParagraph(slide.Lines, slide.FontSize, t.pageConfig.ContentHeight(slide))
+		if err == nil {
+			err = t.writeCredits(slide.Credits)
+		}
+		if err == nil {
+			err = t.writeHint(slide)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (t slideText) writeAlignedParagraph(lines []SlideLine, fontSize int, height float64) error {
+	paragraphHeight := t.pageConfig.LinesHeight(lines, fontSize)
+	var y0 float64
+
+	switch t.pageConfig.VerticalAlign {
+	case "top":
+		y0 = t.pageConfig.Margin
+	case "bottom":
+		y0 = height - paragraphHeight - t.pageConfig.Margin
+	default:
+		y0 = (height - paragraphHeight) / 2
+	}
+
+	if t.pageConfig.isColumnLayout() {
+		original, translation := SplitColumns(lines)
+		if len(translation) > 0 {
+			columnWidth := t.pageConfig.TranslationColumnWidth()
+			err := t.writeParagraph(original, y0, fontSize, t.pageConfig.Margin, columnWidth)
+			if err != nil {
+				return err
+			}
+
+			return t.writeParagraph(translation, y0, fontSize, t.pageConfig.PageWidth-t.pageConfig.Margin-columnWidth, columnWidth)
+		}
+	}
+
+	return t.writeParagraph(lines, y0, fontSize, t.pageConfig.Margin, t.pageConfig.ContentWidth())
+}
+
+func (t slideText) writeParagraph(lines []SlideLine, y0 float64, fontSize int, left float64, width float64) error {
+	y := y0
+	for _, line := range lines {
+		lineFontSize := t.pageConfig.lineFontSize(line, fontSize)
+		err := t.lines.writeVerseLine(line, y, lineFontSize, left, width)
+		if err != nil {
+			return err
+		}
+		y += t.pageConfig.LineHeightAt(lineFontSize)
+	}
+	return nil
+}
+
+func (t slideText) writeTitle(slide Slide) error {
+	y := (t.pageConfig.PageHeight - t.pageConfig.TitleHeight(slide)) / 2
+	for _, line := range slide.Lines {
+		err := t.lines.writeCenteredRuns(ParseEmphasis(line.Text), y, slide.FontSize, t.pageConfig.TextColor)
+		if err != nil {
+			return err
+		}
+		y += t.pageConfig.LineHeightAt(slide.FontSize)
+	}
+
+	for _, detail := range slide.TitleDetails() {
+		err := t.lines.writeCenteredRuns([]TextRun{{Text: detail}}, y, t.pageConfig.HintFontSize, t.pageConfig.TextColor)
+		if err != nil {
+			return err
+		}
+		y += t.pageConfig.LineHeightAt(t.pageConfig.HintFontSize)
+	}
+
+	return nil
+}
+
+func (t slideText) writeCredits(lines []string) error {
+	fontSize := t.pageConfig.CreditsFontSize
+	y := t.pageConfig.PageHeight - t.pageConfig.CreditsHeight(lines)
+	for _, line := range lines {
+		err := t.lines.writeCenteredRuns([]TextRun{{Text: line}}, y, fontSize, t.pageConfig.CreditsColor)
+		if err != nil {
+			return err
+		}
+		y += t.pageConfig.LineHeightAt(fontSize)
+	}
+
+	return nil
+}
+
+func (t slideText) writeHint(slide Slide) error {
+	fontSize := t.pageConfig.HintFontSizeOf(slide)
+	x, y := t.pageConfig.HintPosition(slide)
+	for _, line := range slide.Hint {
+		err := t.lines.writeHintLine(line, x, y, fontSize, t.pageConfig.HintColor)
+		if err != nil {
+			return err
+		}
+		y += t.pageConfig.LineHeightAt(fontSize)
+	}
+
+	return nil
+}
+
+// drawnLines writes the lines word by word with a textDrawer, which puts the text
+// in the middle of the line spacing.
+type drawnLines struct {
+	pageConfig PageConfig
+	drawer     textDrawer
+}
+
+// drawnSlideText positions the text of the slides for a format which draws every word.
+func drawnSlideText(pageConfig PageConfig, drawer textDrawer) slideText {
+	return slideText{pageConfig, drawnLines{pageConfig, drawer}}
+}
+
+func (d drawnLines) measureRuns(runs []TextRun, fontSize int) (float64, error) {
+	width := 0.0
+	for _, run := range runs {
+		runWidth, err := d.drawer.measureText(run.Text, d.pageConfig.fontFamily(run), fontSize)
+		if err != nil {
+			return 0, err
+		}
+		width += runWidth
+	}
+
+	return width, nil
+}
+
+// writeRuns draws the runs one after another, in their own fonts.
+// Justified lines widen every space by the word spacing.
+func (d drawnLines) writeRuns(runs []TextRun, x float64, y float64, fontSize int, color Color, wordSpacing float64) error {
+	for _, run := range runs {
+		family := d.pageConfig.fontFamily(run)
+
+		words := []string{run.Text}
+		if wordSpacing > 0 {
+			words = strings.Split(run.Text, " ")
+		}
+
+		for i, word := range words {
+			if i > 0 {
+				spaceWidth, err := d.drawer.measureText(" ", family, fontSize)
+				if err != nil {
+					return err
+				}
+				x += spaceWidth + wordSpacing
+			}
+			if word == "" {
+				continue
+			}
+
+			err := d.drawer.writeText(word, x, y, family, fontSize, color)
+			if err != nil {
+				return err
+			}
+
+			wordWidth, err := d.drawer.measureText(word, family, fontSize)
+			if err != nil {
+				return err
+			}
+			x += wordWidth
+		}
+	}
+
+	return nil
+}
+
+// writeVerseLine writes a line aligned within a column of the given left edge and width.
+func (d drawnLines) writeVerseLine(line SlideLine, y float64, fontSize int, left float64, width float64) error {
+	runs := ParseEmphasis(line.Text)
+	textWidth, err := d.measureRuns(runs, fontSize)
+	if err != nil {
+		return err
+	}
+
+	color := d.pageConfig.TextColor
+	if line.Translation {
+		color = d.pageConfig.Translation.Color
+	}
+
+	indent := 0.0
+	if line.Response {
+		color = d.pageConfig.ResponseColor
+		indent = d.pageConfig.ResponseIndentAt(fontSize)
+	}
+
+	wordSpacing := 0.0
+	var x float64
+
+	switch d.pageConfig.HorizontalAlign {
+	case AlignLeft:
+		x = left + indent
+	case AlignRight:
+		x = left + width - textWidth
+	case AlignJustify:
+		x = left + indent
+		if numSpaces := strings.Count(line.Text, " "); !line.ParagraphEnd && numSpaces > 0 {
+			wordSpacing = (width - indent - textWidth) / float64(numSpaces)
+		}
+	default:
+		x = left + (width-textWidth)/2
+	}
+
+	offset := float64(fontSize) * (d.pageConfig.LineSpacing - 1) / 2
+	return d.writeRuns(runs, x, y+offset, fontSize, color, wordSpacing)
+}
+
+func (d drawnLines) writeCenteredRuns(runs []TextRun, y float64, fontSize int, color Color) error {
+	textWidth, err := d.measureRuns(runs, fontSize)
+	if err != nil {
+		return err
+	}
+
+	offset := float64(fontSize) * (d.pageConfig.LineSpacing - 1) / 2
+	return d.writeRuns(runs, (d.pageConfig.PageWidth-textWidth)/2, y+offset, fontSize, color, 0)
+}
+
+func (d drawnLines) writeHintLine(text string, x float64, y float64, fontSize int, color Color) error {
+	return d.writeRuns([]TextRun{{Text: text}}, x, y, fontSize, color, 0)
+}
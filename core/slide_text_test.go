@@ -0,0 +1,71 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+type recordedLine struct {
+	text string
+	x    float64
+	y    float64
+}
+
+// recordingLines remembers where the lines were placed instead of drawing them.
+type recordingLines struct {
+	lines []recordedLine
+}
+
+func (r *recordingLines) writeVerseLine(line SlideLine, y float64, fontSize int, left float64, width float64) error {
+	r.lines = append(r.lines, recordedLine{line.Text, left, y})
+	return nil
+}
+
+func (r *recordingLines) writeCenteredRuns(runs []TextRun, y float64, fontSize int, color Color) error {
+	r.lines = append(r.lines, recordedLine{runs[0].Text, 0, y})
+	return nil
+}
+
+func (r *recordingLines) writeHintLine(text string, x float64, y float64, fontSize int, color Color) error {
+	r.lines = append(r.lines, recordedLine{text, x, y})
+	return nil
+}
+
+func TestSlideTextVerticalAlign(t *testing.T) {
+	testCases := []struct {
+		align    string
+		expected []recordedLine
+	}{
+		{"top", []recordedLine{{"Pan", 10, 10}, {"blisko", 10, 30}}},
+		{"bottom", []recordedLine{{"Pan", 10, 50}, {"blisko", 10, 70}}},
+		{"", []recordedLine{{"Pan", 10, 30}, {"blisko", 10, 50}}},
+	}
+
+	for _, tc := range testCases {
+		pageConfig := PageConfig{PageWidth: 200, PageHeight: 100, Margin: 10, FontSize: 20, LineSpacing: 1, VerticalAlign: tc.align}
+		recorder := &recordingLines{}
+		slide := Slide{Type: "verse", FontSize: 20, Lines: []SlideLine{{Text: "Pan"}, {Text: "blisko"}}}
+
+		if err := (slideText{pageConfig, recorder}).write(slide); err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(recorder.lines, tc.expected) {
+			t.Errorf("Align %q: expected %v, got %v", tc.align, tc.expected, recorder.lines)
+		}
+	}
+}
+
+func TestSlideTextColumns(t *testing.T) {
+	pageConfig := PageConfig{PageWidth: 200, PageHeight: 100, Margin: 10, FontSize: 20, LineSpacing: 1, VerticalAlign: "top", Translation: TranslationOptions{Layout: TranslationColumns}}
+	recorder := &recordingLines{}
+	slide := Slide{Type: "verse", FontSize: 20, Lines: []SlideLine{{Text: "Pan"}, {Text: "Lord", Translation: true}}}
+
+	if err := (slideText{pageConfig, recorder}).write(slide); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(recorder.lines) != 2 || recorder.lines[0].y != recorder.lines[1].y || recorder.lines[1].x <= recorder.lines[0].x {
+		t.Errorf("Expected the translation next to the original, got %v", recorder.lines)
+	}
+}
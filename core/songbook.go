@@ -0,0 +1,431 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/signintech/gopdf"
+)
+
+type SongbookConfig struct {
+	PaperSize string
+	Columns   int
+	Booklet   bool
+	Font      []byte
+	FontSize  int
+}
+
+const songbookLineSpacing = 1.25
+const songbookColumnGap = 18.0
+const songbookTitleScale = 1.4
+const songbookMetaScale = 0.85
+
+var songbookMetaColor = Color{R: 100, G: 100, B: 100}
+
+type songbookText struct {
+	x        float64
+	y        float64
+	text     string
+	fontSize int
+	color    Color
+}
+
+type songbookPage struct {
+	texts []songbookText
+}
+
+type songbookTocEntry struct {
+	title string
+	page  int
+}
+
+// songbookBlock is a piece of text that is kept in a single column if possible.
+type songbookBlock struct {
+	texts        []songbookText
+	height       float64
+	gapBefore    float64
+	keepWithNext bool
+}
+
+type Songbook struct {
+	config     SongbookConfig
+	goPdf      *gopdf.GoPdf
+	pageSize   gopdf.Rect
+	margin     float64
+	pages      []*songbookPage
+	column     int
+	y          float64
+	tocEntries []songbookTocEntry
+}
+
+func (s *Songbook) fontSize(scale float64) int {
+	return int(math.Round(float64(s.config.FontSize) * scale))
+}
+
+func (s *Songbook) lineHeight(scale float64) float64 {
+	return float64(s.fontSize(scale)) * songbookLineSpacing
+}
+
+func (s *Songbook) columnWidth() float64 {
+	columns := float64(s.config.Columns)
+	return (s.pageSize.W - 2*s.margin - (columns-1)*songbookColumnGap) / columns
+}
+
+func (s *Songbook) columnHeight() float64 {
+	// the bottom margin also holds the page number
+	return s.pageSize.H - 2*s.margin - s.lineHeight(1)
+}
+
+func (s *Songbook) columnX() float64 {
+	return s.margin + float64(s.column)*(s.columnWidth()+songbookColumnGap)
+}
+
+func (s *Songbook) measurer(scale float64) Measurer {
+	return func(text string) (float64, error) {
+		s.goPdf.SetFont("default", "", s.fontSize(scale))
+		return s.goPdf.MeasureTextWidth(text)
+	}
+}
+
+func (s *Songbook) newPage() {
+	s.pages = append(s.pages, &songbookPage{})
+	s.column = 0
+	s.y = 0
+}
+
+func (s *Songbook) nextColumn() {
+	if s.column+1 < s.config.Columns {
+		s.column++
+		s.y = 0
+	} else {
+		s.newPage()
+	}
+}
+
+func (s *Songbook) currentPage() *songbookPage {
+	return s.pages[len(s.pages)-1]
+}
+
+func (s *Songbook) textBlock(text string, scale float64, color Color) songbookBlock {
+	lines := strings.Split(PlainVerse(text), "\n")
+	lines = removeLineEndMarks(BreakLongLines(lines, s.measurer(scale), s.columnWidth()))
+
+	block := songbookBlock{}
+	for _, line := range lines {
+		block.texts = append(block.texts, songbookText{
+			y:        block.height,
+			text:     line,
+			fontSize: s.fontSize(scale),
+			color:    color,
+		})
+		block.height += s.lineHeight(scale)
+	}
+
+	return block
+}
+
+func (s *Songbook) place(block songbookBlock) {
+	for _, text := range block.texts {
+		text.x = s.columnX()
+		text.y = s.margin + s.y + text.y
+		s.currentPage().texts = append(s.currentPage().texts, text)
+	}
+	s.y += block.height
+}
+
+// placeBlocks flows the blocks into columns. A block goes to the next column
+// when it doesn't fit, and it is split only when it's taller than a column.
+// Returns the page number on which the first block starts.
+func (s *Songbook) placeBlocks(blocks []songbookBlock) int {
+	firstPage := 0
+	for i, block := range blocks {
+		gap := block.gapBefore
+		if s.y == 0 {
+			gap = 0
+		}
+
+		requiredHeight := block.height
+		if block.keepWithNext && i+1 < len(blocks) {
+			requiredHeight += blocks[i+1].gapBefore + min(blocks[i+1].height, s.lineHeight(1)*2)
+		}
+
+		if s.y > 0 && s.y+gap+requiredHeight > s.columnHeight() {
+			s.nextColumn()
+			gap = 0
+		}
+		s.y += gap
+
+		if i == 0 {
+			firstPage = len(s.pages)
+		}
+
+		for len(block.texts) > 0 {
+			fitting := 0
+			for fitting < len(block.texts) && s.y+block.texts[fitting].y-block.texts[0].y+s.lineHeight(1) <= s.columnHeight() {
+				fitting++
+			}
+			if fitting == 0 {
+				fitting = 1
+			}
+
+			offset := block.texts[0].y
+			part := songbookBlock{}
+			for _, text := range block.texts[:fitting] {
+				text.y -= offset
+				part.texts = append(part.texts, text)
+			}
+			if fitting < len(block.texts) {
+				part.height = block.texts[fitting].y - offset
+			} else {
+				part.height = block.height - offset
+			}
+
+			s.place(part)
+			block.texts = block.texts[fitting:]
+			if len(block.texts) > 0 {
+				s.nextColumn()
+			}
+		}
+	}
+
+	return firstPage
+}
+
+func (s *Songbook) songBlocks(song []string, info ItemInfo) []songbookBlock {
+	blocks := make([]songbookBlock, 0)
+
+	if info.Title != "" {
+		title := s.textBlock(info.Title, songbookTitleScale, Color{})
+		title.gapBefore = s.lineHeight(1) * 1.5
+		title.keepWithNext = true
+		blocks = append(blocks, title)
+
+		meta := make([]string, 0)
+		if info.Subtitle != "" {
+			meta = append(meta, info.Subtitle)
+		}
+		if info.Author != "" {
+			meta = append(meta, info.Author)
+		}
+		if len(meta) > 0 {
+			metaBlock := s.textBlock(strings.Join(meta, " · "), songbookMetaScale, songbookMetaColor)
+			metaBlock.keepWithNext = true
+			blocks = append(blocks, metaBlock)
+		}
+	}
+
+	for _, verse := range song {
+		if strings.HasPrefix(verse, HintStartTag) && strings.HasSuffix(verse, HintEndTag) {
+			continue
+		}
+
+		block := s.textBlock(verse, 1, Color{})
+		block.gapBefore = s.lineHeight(1) * 0.6
+		if len(blocks) == 0 {
+			block.gapBefore = s.lineHeight(1) * 1.5
+		}
+		blocks = append(blocks, block)
+	}
+
+	return blocks
+}
+
+func (s *Songbook) tocLinesPerPage() int {
+	return int((s.pageSize.H - 2*s.margin - s.lineHeight(1)) / s.lineHeight(1))
+}
+
+func (s *Songbook) numTocPages() int {
+	if len(s.tocEntries) == 0 {
+		return 0
+	}
+
+	// the first page also holds the heading
+	numLines := len(s.tocEntries) + 2
+	return (numLines + s.tocLinesPerPage() - 1) / s.tocLinesPerPage()
+}
+
+func (s *Songbook) tocPages() []*songbookPage {
+	numTocPages := s.numTocPages()
+	pages := make([]*songbookPage, 0)
+	if numTocPages == 0 {
+		return pages
+	}
+
+	page := &songbookPage{}
+	pages = append(pages, page)
+	page.texts = append(page.texts, songbookText{
+		x:        s.margin,
+		y:        s.margin,
+		text:     "Spis treści",
+		fontSize: s.fontSize(songbookTitleScale),
+	})
+	line := 2
+
+	measure := s.measurer(1)
+	width := s.pageSize.W - 2*s.margin
+	for _, entry := range s.tocEntries {
+		if line >= s.tocLinesPerPage() {
+			page = &songbookPage{}
+			pages = append(pages, page)
+			line = 0
+		}
+
+		y := s.margin + float64(line)*s.lineHeight(1)
+		pageNumber := fmt.Sprintf("%d", entry.page+numTocPages)
+		pageNumberWidth, _ := measure(pageNumber)
+
+		page.texts = append(page.texts,
+			songbookText{x: s.margin, y: y, text: entry.title, fontSize: s.fontSize(1)},
+			songbookText{x: s.margin + width - pageNumberWidth, y: y, text: pageNumber, fontSize: s.fontSize(1)},
+		)
+		line++
+	}
+
+	return pages
+}
+
+func (s *Songbook) layout(textDeck [][]string, items []ItemInfo) []*songbookPage {
+	s.newPage()
+
+	for itemIndex, song := range textDeck {
+		info := ItemInfo{}
+		if itemIndex < len(items) {
+			info = items[itemIndex]
+		}
+
+		blocks := s.songBlocks(song, info)
+		if len(blocks) == 0 {
+			continue
+		}
+
+		firstPage := s.placeBlocks(blocks)
+		if info.Title != "" {
+			s.tocEntries = append(s.tocEntries, songbookTocEntry{title: info.Title, page: firstPage})
+		}
+	}
+
+	return append(s.tocPages(), s.pages...)
+}
+
+func (s *Songbook) render(pages []*songbookPage) error {
+	s.goPdf = &gopdf.GoPdf{}
+	s.goPdf.Start(gopdf.Config{PageSize: s.pageSize})
+
+	err := addFont(s.goPdf, "default", s.config.Font)
+	if err != nil {
+		return err
+	}
+
+	for pageIndex, page := range pages {
+		s.goPdf.AddPage()
+
+		for _, text := range page.texts {
+			s.goPdf.SetFont("default", "", text.fontSize)
+			s.goPdf.SetFillColor(text.color.R, text.color.G, text.color.B)
+			s.goPdf.SetX(text.x)
+			s.goPdf.SetY(text.y)
+			s.goPdf.Cell(nil, text.text)
+		}
+
+		pageNumber := fmt.Sprintf("%d", pageIndex+1)
+		s.goPdf.SetFont("default", "", s.fontSize(songbookMetaScale))
+		s.goPdf.SetFillColor(songbookMetaColor.R, songbookMetaColor.G, songbookMetaColor.B)
+		pageNumberWidth, _ := s.goPdf.MeasureTextWidth(pageNumber)
+		s.goPdf.SetX((s.pageSize.W - pageNumberWidth) / 2)
+		s.goPdf.SetY(s.pageSize.H - s.margin)
+		s.goPdf.Cell(nil, pageNumber)
+	}
+
+	return nil
+}
+
+// bookletOrder returns the order of pages for saddle-stitch printing, two pages
+// per side of a sheet. Zero stands for a blank page added to fill the last sheet.
+func bookletOrder(numPages int) []int {
+	numSlots := (numPages + 3) / 4 * 4
+	order := make([]int, 0, numSlots)
+
+	page := func(n int) int {
+		if n > numPages {
+			return 0
+		}
+		return n
+	}
+
+	for i := 0; i < numSlots/2; i += 2 {
+		order = append(order,
+			page(numSlots-i), page(i+1),
+			page(i+2), page(numSlots-i-1),
+		)
+	}
+
+	return order
+}
+
+func imposeBooklet(source []byte, numPages int, pageSize gopdf.Rect) (*gopdf.GoPdf, error) {
+	sheetSize := gopdf.Rect{W: gopdf.PageSizeA4.H, H: gopdf.PageSizeA4.W}
+
+	booklet := &gopdf.GoPdf{}
+	booklet.Start(gopdf.Config{PageSize: sheetSize})
+
+	var reader io.ReadSeeker = bytes.NewReader(source)
+	templates := make(map[int]int)
+	for page := 1; page <= numPages; page++ {
+		templates[page] = booklet.ImportPageStream(&reader, page, "/MediaBox")
+	}
+
+	offsetX := (sheetSize.W - 2*pageSize.W) / 2
+	offsetY := (sheetSize.H - pageSize.H) / 2
+
+	for i, page := range bookletOrder(numPages) {
+		if i%2 == 0 {
+			booklet.AddPage()
+		}
+
+		if page == 0 {
+			continue
+		}
+
+		x := offsetX + float64(i%2)*pageSize.W
+		booklet.UseImportedTemplate(templates[page], x, offsetY, pageSize.W, pageSize.H)
+	}
+
+	return booklet, nil
+}
+
+func BuildSongbookPDF(textDeck [][]string, items []ItemInfo, config SongbookConfig) (*gopdf.GoPdf, error) {
+	s := Songbook{config: config, pageSize: *gopdf.PageSizeA4, margin: 48}
+	if config.PaperSize == "A5" {
+		s.pageSize = *gopdf.PageSizeA5
+		s.margin = 36
+	}
+
+	// measuring only needs the font, the pages are rendered after the layout is done
+	s.goPdf = &gopdf.GoPdf{}
+	s.goPdf.Start(gopdf.Config{PageSize: s.pageSize})
+	err := addFont(s.goPdf, "default", config.Font)
+	if err != nil {
+		return nil, err
+	}
+
+	pages := s.layout(textDeck, items)
+
+	err = s.render(pages)
+	if err != nil {
+		return nil, err
+	}
+
+	if !config.Booklet {
+		return s.goPdf, nil
+	}
+
+	source, err := s.goPdf.GetBytesPdfReturnErr()
+	if err != nil {
+		return nil, err
+	}
+
+	return imposeBooklet(source, len(pages), s.pageSize)
+}
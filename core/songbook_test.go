@@ -0,0 +1,24 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBookletOrder(t *testing.T) {
+	result := bookletOrder(8)
+	expected := []int{8, 1, 2, 7, 6, 3, 4, 5}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestBookletOrderFillsLastSheet(t *testing.T) {
+	result := bookletOrder(6)
+	expected := []int{0, 1, 2, 0, 6, 3, 4, 5}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
@@ -0,0 +1,412 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+
+	"github.com/signintech/gopdf"
+)
+
+// StageSlide is what the musicians see of a slide on the stage display,
+// which faces them instead of the congregation.
+type StageSlide struct {
+	ContentSlide
+	Title string   `json:"title"`
+	Label string   `json:"label"`
+	Lines []string `json:"lines"`
+	// Next is the index of the next slide with text, or -1 at the end of the deck.
+	// The blank slides between the items are skipped, so the next song shows up early.
+	Next int `json:"next"`
+}
+
+var stageBackgroundColor = Color{R: 0, G: 0, B: 0}
+var stageTextColor = Color{R: 255, G: 255, B: 255}
+var stageNextColor = Color{R: 160, G: 160, B: 160}
+var stageTitleColor = Color{R: 255, G: 214, B: 102}
+
+// the share of the page taken by the current slide, the next one gets the rest
+const stageCurrentShare = 0.6
+const stageLineSpacing = 1.2
+
+// the title and the label of a slide are set in a fraction of the font size
+const stageHeaderFontScale = 0.4
+
+// stageLabels numbers the verses of every item, like "2/4", and the slides
+// of a verse split in parts, like "2/4 (1/2)".
+func stageLabels(slides []Slide) []string {
+	type verseKey struct {
+		item  int
+		verse int
+	}
+
+	verseNumbers := make(map[verseKey]int)
+	numVerses := make(map[int]int)
+	numChunks := make(map[verseKey]int)
+	for _, slide := range slides {
+		if slide.Type != "verse" {
+			continue
+		}
+
+		key := verseKey{slide.ItemIndex, slide.VerseIndex}
+		if _, ok := verseNumbers[key]; !ok {
+			numVerses[slide.ItemIndex]++
+			verseNumbers[key] = numVerses[slide.ItemIndex]
+		}
+		numChunks[key] = max(numChunks[key], slide.ChunkIndex+1)
+	}
+
+	labels := make([]string, len(slides))
+	for i, slide := range slides {
+		if slide.Type != "verse" {
+			continue
+		}
+
+		key := verseKey{slide.ItemIndex, slide.VerseIndex}
+		labels[i] = fmt.Sprintf("%d/%d", verseNumbers[key], numVerses[slide.ItemIndex])
+		if numChunks[key] > 1 {
+			labels[i] += fmt.Sprintf(" (%d/%d)", slide.ChunkIndex+1, numChunks[key])
+		}
+	}
+
+	return labels
+}
+
+// stageLines is the plain text of a slide, without the translation,
+// which the musicians don't sing.
+func stageLines(slide Slide) []string {
+	lines := make([]string, 0)
+
+	switch slide.Type {
+	case "verse", "title":
+		for _, line := range slide.Lines {
+			if !line.Translation {
+				lines = append(lines, StripEmphasis(line.Text))
+			}
+		}
+		lines = append(lines, slide.TitleDetails()...)
+	case "hint":
+		lines = append(lines, slide.Hint...)
+	case "qr":
+		lines = append(lines, slide.Text)
+	}
+
+	return lines
+}
+
+func stageSlides(slides []Slide, textDeck [][]string, items []ItemInfo) []StageSlide {
+	labels := stageLabels(slides)
+	stage := make([]StageSlide, len(slides))
+	next := -1
+
+	for i := len(slides) - 1; i >= 0; i-- {
+		slide := slides[i]
+		stage[i] = StageSlide{
+			ContentSlide: slide.Content(),
+			Label:        labels[i],
+			Lines:        stageLines(slide),
+			Next:         next,
+		}
+
+		if slide.Type != "blank" {
+			stage[i].Title = outlineTitle(slide.ItemIndex, textDeck, items)
+			next = i
+		}
+	}
+
+	return stage
+}
+
+// StageSlides lays out the deck like the PDF and describes its slides for the stage display,
+// so that the indexes of the slides match the pages of the presentation.
+func StageSlides(textDeck [][]string, items []ItemInfo, pageConfig PageConfig) ([]StageSlide, error) {
+	measure, err := NewFontMeasurer(pageConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	slides := LayoutDeck(textDeck, items, pageConfig, measure)
+	return stageSlides(slides, textDeck, items), nil
+}
+
+type StageSheet struct {
+	goPdf      *gopdf.GoPdf
+	pageConfig PageConfig
+}
+
+func (s *StageSheet) measure(text string, fontSize int) float64 {
+	s.goPdf.SetFont("default", "", fontSize)
+	width, _ := s.goPdf.MeasureTextWidth(text)
+	return width
+}
+
+func (s *StageSheet) writeText(text string, x float64, y float64, fontSize int, color Color) {
+	s.goPdf.SetFont("default", "", fontSize)
+	s.goPdf.SetFillColor(color.R, color.G, color.B)
+	s.goPdf.SetX(x)
+	s.goPdf.SetY(y)
+	s.goPdf.Cell(nil, text)
+}
+
+// fitFontSize shrinks the font until the lines fit in the box.
+func (s *StageSheet) fitFontSize(lines []string, fontSize int, width float64, height float64) int {
+	for ; fontSize > 1; fontSize-- {
+		fits := float64(len(lines))*float64(fontSize)*stageLineSpacing <= height
+		for _, line := range lines {
+			fits = fits && s.measure(line, fontSize) <= width
+		}
+
+		if fits {
+			break
+		}
+	}
+
+	return fontSize
+}
+
+func (s *StageSheet) writeLines(lines []string, y float64, maxFontSize int, height float64, color Color) {
+	margin := s.pageConfig.Margin
+	fontSize := s.fitFontSize(lines, maxFontSize, s.pageConfig.PageWidth-2*margin, height)
+	for _, line := range lines {
+		s.writeText(line, margin, y, fontSize, color)
+		y += float64(fontSize) * stageLineSpacing
+	}
+}
+
+func (s *StageSheet) writePage(slides []StageSlide, index int) {
+	width, height := s.pageConfig.PageWidth, s.pageConfig.PageHeight
+	margin := s.pageConfig.Margin
+	slide := slides[index]
+
+	s.goPdf.AddPage()
+	s.goPdf.SetFillColor(stageBackgroundColor.R, stageBackgroundColor.G, stageBackgroundColor.B)
+	s.goPdf.RectFromUpperLeftWithStyle(0, 0, width, height, "F")
+
+	headerFontSize := int(float64(s.pageConfig.FontSize) * stageHeaderFontScale)
+	s.writeText(slide.Title, margin, margin, headerFontSize, stageTitleColor)
+	s.writeText(slide.Label, width-margin-s.measure(slide.Label, headerFontSize), margin, headerFontSize, stageNextColor)
+
+	top := margin + float64(headerFontSize)*stageLineSpacing + margin
+	split := top + (height-top)*stageCurrentShare
+	s.writeLines(slide.Lines, top, s.pageConfig.FontSize, split-top-margin, stageTextColor)
+
+	s.goPdf.SetStrokeColor(stageNextColor.R, stageNextColor.G, stageNextColor.B)
+	s.goPdf.Line(margin, split, width-margin, split)
+
+	if slide.Next < 0 {
+		return
+	}
+
+	next := slides[slide.Next]
+	y := split + margin
+	if next.ItemIndex != slide.ItemIndex || slide.Type == "blank" {
+		s.writeText(next.Title, margin, y, headerFontSize, stageTitleColor)
+		y += float64(headerFontSize) * stageLineSpacing
+	}
+	s.writeLines(next.Lines, y, s.pageConfig.FontSize*3/5, height-y-margin, stageNextColor)
+}
+
+// BuildStagePDF prints a page of the stage display for every slide of the deck, with the text
+// of the slide large and the text of the next one smaller below. It has no clock, unlike the HTML display.
+func BuildStagePDF(textDeck [][]string, items []ItemInfo, pageConfig PageConfig) (*gopdf.GoPdf, []ContentSlide, error) {
+	s := StageSheet{goPdf: &gopdf.GoPdf{}, pageConfig: pageConfig}
+	s.goPdf.Start(gopdf.Config{PageSize: gopdf.Rect{W: pageConfig.PageWidth, H: pageConfig.PageHeight}})
+
+	// the layout measures the emphasized text in its own fonts
+	err := addFonts(s.goPdf, pageConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	slides := LayoutDeck(textDeck, items, pageConfig, emphasisMeasurer(s.goPdf, pageConfig))
+	stage := stageSlides(slides, textDeck, items)
+	for i := range stage {
+		s.writePage(stage, i)
+	}
+
+	s.goPdf.SetInfo(gopdf.PdfInfo{Title: pageConfig.DocumentTitle, Producer: producer})
+	return s.goPdf, ContentSlides(slides), nil
+}
+
+// StageOptions connect the stage display to a live session, whose slides it loads from
+// SlidesURL and follows the page changes from the event stream at EventsURL.
+// Without them, the display shows the slides it's built with and is switched with the keyboard.
+type StageOptions struct {
+	SlidesURL string
+	EventsURL string
+}
+
+const stageHTML = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="generator" content="%s">
+<title>%s</title>
+<style>
+html, body { margin: 0; height: 100%%; overflow: hidden; background: #%s; color: #%s; font-family: system-ui, sans-serif; }
+body { display: flex; flex-direction: column; padding: 2vh 3vw; box-sizing: border-box; }
+header { display: flex; gap: 2vw; align-items: baseline; font-size: 4vh; }
+#title { flex: 1; color: #%s; overflow: hidden; white-space: nowrap; text-overflow: ellipsis; }
+#label, #next-title { color: #%s; }
+#clock { font-variant-numeric: tabular-nums; }
+#current { flex: 3; overflow: hidden; line-height: 1.2; margin: 2vh 0; }
+hr { width: 100%%; border: 0; border-top: 1px solid #%s; margin: 0; }
+#next-title { font-size: 3vh; margin-top: 2vh; }
+#next { flex: 2; overflow: hidden; line-height: 1.2; color: #%s; }
+</style>
+</head>
+<body data-slides-url="%s" data-events-url="%s">
+<header><div id="title"></div><div id="label"></div><div id="clock" aria-label="clock"></div></header>
+<main id="current" aria-live="polite"></main>
+<hr>
+<div id="next-title"></div>
+<aside id="next"></aside>
+<script type="application/json" id="slides">%s</script>
+<script>
+(function () {
+  var slides = JSON.parse(document.getElementById("slides").textContent);
+  var current = 0;
+
+  function element(id) {
+    return document.getElementById(id);
+  }
+
+  function setLines(node, slide) {
+    node.replaceChildren();
+    (slide ? slide.lines : []).forEach(function (line) {
+      var div = document.createElement("div");
+      div.textContent = line;
+      node.appendChild(div);
+    });
+  }
+
+  // fit shrinks the text until it fits in its box
+  function fit(node, size) {
+    node.style.fontSize = size + "vh";
+    while (size > 1 && (node.scrollHeight > node.clientHeight || node.scrollWidth > node.clientWidth)) {
+      size -= 0.5;
+      node.style.fontSize = size + "vh";
+    }
+  }
+
+  function render() {
+    var slide = slides[current];
+    var next = slide && slide.next >= 0 ? slides[slide.next] : null;
+
+    element("title").textContent = slide ? slide.title : "";
+    element("label").textContent = slide ? slide.label : "";
+    element("next-title").textContent = next && (next.i !== slide.i || slide.t === "blank") ? next.title : "";
+    setLines(element("current"), slide);
+    setLines(element("next"), next);
+    fit(element("current"), 12);
+    fit(element("next"), 7);
+  }
+
+  function show(index) {
+    current = Math.max(0, Math.min(slides.length - 1, index));
+    render();
+  }
+
+  function tick() {
+    element("clock").textContent = new Date().toLocaleTimeString([], { hour: "2-digit", minute: "2-digit" });
+  }
+
+  tick();
+  setInterval(tick, 1000);
+  window.addEventListener("resize", render);
+
+  var eventsURL = document.body.dataset.eventsUrl;
+  if (!eventsURL) {
+    document.addEventListener("keydown", function (event) {
+      switch (event.key) {
+      case "ArrowRight": case "ArrowDown": case "PageDown": case " ":
+        show(current + 1);
+        break;
+      case "ArrowLeft": case "ArrowUp": case "PageUp":
+        show(current - 1);
+        break;
+      default:
+        return;
+      }
+      event.preventDefault();
+    });
+    show(0);
+    return;
+  }
+
+  // the slides are loaded again whenever the session starts over with a new deck
+  function load(page) {
+    fetch(document.body.dataset.slidesUrl).then(function (response) {
+      return response.json();
+    }).then(function (loaded) {
+      slides = loaded;
+      show(page);
+    });
+  }
+
+  var source = new EventSource(eventsURL);
+  source.addEventListener("start", function (event) {
+    load(JSON.parse(event.data).currentPage);
+  });
+  source.addEventListener("changePage", function (event) {
+    show(JSON.parse(event.data).page);
+  });
+  source.addEventListener("delete", function () {
+    source.close();
+    slides = [];
+    render();
+  });
+})();
+</script>
+</body>
+</html>
+`
+
+// BuildStageHTML writes the stage display as a single HTML file with the current slide,
+// the next one and a clock. With live options, it doesn't need the slides.
+func BuildStageHTML(title string, slides []StageSlide, options StageOptions) (*bytes.Buffer, error) {
+	if slides == nil {
+		slides = []StageSlide{}
+	}
+
+	// the encoder escapes the HTML characters, so the slides can't close the script
+	data, err := json.Marshal(slides)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, stageHTML,
+		producer,
+		html.EscapeString(title),
+		stageBackgroundColor.Hex(),
+		stageTextColor.Hex(),
+		stageTitleColor.Hex(),
+		stageNextColor.Hex(),
+		stageNextColor.Hex(),
+		stageNextColor.Hex(),
+		html.EscapeString(options.SlidesURL),
+		html.EscapeString(options.EventsURL),
+		data,
+	)
+
+	return buf, nil
+}
+
+// BuildStageDisplay writes the stage display of the deck as an HTML file, which is switched with the keyboard.
+func BuildStageDisplay(textDeck [][]string, items []ItemInfo, pageConfig PageConfig) (*bytes.Buffer, []ContentSlide, error) {
+	stage, err := StageSlides(textDeck, items, pageConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	contents := make([]ContentSlide, len(stage))
+	for i, slide := range stage {
+		contents[i] = slide.ContentSlide
+	}
+
+	buf, err := BuildStageHTML(pageConfig.DocumentTitle, stage, StageOptions{})
+	return buf, contents, err
+}
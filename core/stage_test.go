@@ -0,0 +1,63 @@
+package core
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStageSlides(t *testing.T) {
+	slides := []Slide{
+		{Type: "blank", ItemIndex: -1},
+		{Type: "verse", ItemIndex: 0, VerseIndex: 0, Lines: []SlideLine{{Text: "Pan *kiedyś* stanął"}}},
+		{Type: "verse", ItemIndex: 0, VerseIndex: 1, ChunkIndex: 0, Lines: []SlideLine{{Text: "O Panie"}, {Text: "O Lord", Translation: true}}},
+		{Type: "verse", ItemIndex: 0, VerseIndex: 1, ChunkIndex: 1, Lines: []SlideLine{{Text: "to Ty"}}},
+		{Type: "blank", ItemIndex: 0},
+		{Type: "verse", ItemIndex: 1, Lines: []SlideLine{{Text: "Alleluja"}}},
+		{Type: "blank", ItemIndex: 1},
+	}
+	items := []ItemInfo{{Title: "Barka"}, {}}
+	textDeck := [][]string{{}, {"Alleluja"}}
+
+	stage := stageSlides(slides, textDeck, items)
+
+	labels := []string{"", "1/2", "2/2 (1/2)", "2/2 (2/2)", "", "1/1", ""}
+	next := []int{1, 2, 3, 5, 5, -1, -1}
+	titles := []string{"", "1. Barka", "1. Barka", "1. Barka", "", "2. Alleluja", ""}
+	for i, slide := range stage {
+		if slide.Label != labels[i] || slide.Next != next[i] || slide.Title != titles[i] {
+			t.Errorf("Slide %d: expected %q, %q and next %d, got %q, %q and %d", i, titles[i], labels[i], next[i], slide.Title, slide.Label, slide.Next)
+		}
+	}
+
+	if expected := []string{"Pan kiedyś stanął"}; !reflect.DeepEqual(stage[1].Lines, expected) {
+		t.Errorf("Expected the lines without emphasis %v, got %v", expected, stage[1].Lines)
+	}
+
+	if expected := []string{"O Panie"}; !reflect.DeepEqual(stage[2].Lines, expected) {
+		t.Errorf("Expected the lines without the translation %v, got %v", expected, stage[2].Lines)
+	}
+}
+
+func TestBuildStageDisplay(t *testing.T) {
+	pageConfig := testPageConfig(t)
+	textDeck := [][]string{{"Pan kiedyś stanął nad brzegiem", "</script>"}}
+
+	_, contents, err := BuildStagePDF(textDeck, nil, pageConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf, htmlContents, err := BuildStageDisplay(textDeck, nil, pageConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(contents, htmlContents) {
+		t.Errorf("Expected the same slides in the PDF and the HTML, got %v and %v", contents, htmlContents)
+	}
+
+	if strings.Count(buf.String(), "</script>") != 2 {
+		t.Errorf("Expected the lyrics to be escaped in the script")
+	}
+}
@@ -0,0 +1,289 @@
+package core
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/skip2/go-qrcode"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// svgSlideGap is the space between the slides stacked in a preview, in points.
+const svgSlideGap = 16.0
+
+type svgGlyph struct {
+	family string
+	index  sfnt.GlyphIndex
+}
+
+// SvgSlides draws the slides as vector graphics, using the same layout as the PDF.
+// The glyphs are drawn as paths, so the previews look right without the fonts.
+// Every glyph and image is defined once, and the slides refer to it.
+type SvgSlides struct {
+	pageConfig PageConfig
+	fonts      map[string]sfntFont
+	buf        sfnt.Buffer
+	glyphs     map[svgGlyph]string
+	images     map[string]string
+	defs       strings.Builder
+	body       strings.Builder
+	numFilters int
+}
+
+func (s *SvgSlides) Initialize(pageConfig PageConfig) error {
+	s.pageConfig = pageConfig
+	s.glyphs = make(map[svgGlyph]string)
+	s.images = make(map[string]string)
+
+	var err error
+	s.fonts, err = parseFonts(pageConfig)
+	return err
+}
+
+// svgNumber rounds a coordinate to a hundredth of a point.
+func svgNumber(value float64) string {
+	return strconv.FormatFloat(math.Round(value*100)/100, 'f', -1, 64)
+}
+
+// glyph returns the ID of the glyph's path, in the units of the font.
+func (s *SvgSlides) glyph(family string, index sfnt.GlyphIndex) (string, error) {
+	key := svgGlyph{family, index}
+	if id, ok := s.glyphs[key]; ok {
+		return id, nil
+	}
+
+	f := s.fonts[family]
+	segments, err := f.font.LoadGlyph(&s.buf, index, fixed.I(int(f.unitsPerEm)), nil)
+	if err != nil {
+		return "", err
+	}
+
+	var path strings.Builder
+	point := func(p fixed.Point26_6) string {
+		return svgNumber(float64(p.X)/64) + " " + svgNumber(float64(p.Y)/64)
+	}
+
+	for _, segment := range segments {
+		switch segment.Op {
+		case sfnt.SegmentOpMoveTo:
+			path.WriteString("M" + point(segment.Args[0]))
+		case sfnt.SegmentOpLineTo:
+			path.WriteString("L" + point(segment.Args[0]))
+		case sfnt.SegmentOpQuadTo:
+			path.WriteString("Q" + point(segment.Args[0]) + " " + point(segment.Args[1]))
+		case sfnt.SegmentOpCubeTo:
+			path.WriteString("C" + point(segment.Args[0]) + " " + point(segment.Args[1]) + " " + point(segment.Args[2]))
+		}
+	}
+
+	id := fmt.Sprintf("g%d", len(s.glyphs)+1)
+	fmt.Fprintf(&s.defs, `<path id="%s" d="%s"/>`+"\n", id, path.String())
+	s.glyphs[key] = id
+	return id, nil
+}
+
+// measureText adds up the advance widths of the glyphs, like gopdf does.
+func (s *SvgSlides) measureText(text string, family string, fontSize int) (float64, error) {
+	f := s.fonts[family]
+	width := 0.0
+	for _, r := range text {
+		index, err := f.font.GlyphIndex(&s.buf, r)
+		if err != nil {
+			return 0, err
+		}
+
+		advance, err := f.font.GlyphAdvance(&s.buf, index, fixed.I(int(f.unitsPerEm)), font.HintingNone)
+		if err != nil {
+			return 0, err
+		}
+		width += float64(advance) / 64
+	}
+
+	return width * float64(fontSize) / f.unitsPerEm, nil
+}
+
+// writeText draws the text with its top edge at y, like a PDF cell. The glyphs are placed
+// in the units of the font, and the group scales them to the font size.
+func (s *SvgSlides) writeText(text string, x float64, y float64, family string, fontSize int, color Color) error {
+	f := s.fonts[family]
+	scale := float64(fontSize) / f.unitsPerEm
+	baseline := y + f.ascent*float64(fontSize)
+
+	fmt.Fprintf(&s.body, `<g fill="#%s" transform="translate(%s %s) scale(%s)"`, color.Hex(), svgNumber(x), svgNumber(baseline), strconv.FormatFloat(scale, 'g', 6, 64))
+	if outline := s.pageConfig.TextOutline; outline != nil && outline.Width > 0 {
+		// the stroke is centered on the outline of the glyphs and painted below the fill,
+		// so it's twice as wide as the visible outline
+		fmt.Fprintf(&s.body, ` stroke="#%s" stroke-width="%s" stroke-linejoin="round" paint-order="stroke"`, outline.Color.Hex(), svgNumber(2*outline.Width/scale))
+	}
+	s.body.WriteString(">")
+
+	advance := 0.0
+	for _, r := range text {
+		index, err := f.font.GlyphIndex(&s.buf, r)
+		if err != nil {
+			return err
+		}
+
+		if r != ' ' {
+			id, err := s.glyph(family, index)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(&s.body, `<use href="#%s" x="%s"/>`, id, svgNumber(advance))
+		}
+
+		glyphAdvance, err := f.font.GlyphAdvance(&s.buf, index, fixed.I(int(f.unitsPerEm)), font.HintingNone)
+		if err != nil {
+			return err
+		}
+		advance += float64(glyphAdvance) / 64
+	}
+
+	s.body.WriteString("</g>\n")
+	return nil
+}
+
+// image returns the ID of an image, which is stretched to the given size.
+func (s *SvgSlides) image(data []byte, width float64, height float64) string {
+	key := fmt.Sprintf("%x %sx%s", sha256.Sum256(data), svgNumber(width), svgNumber(height))
+	if id, ok := s.images[key]; ok {
+		return id
+	}
+
+	id := fmt.Sprintf("image%d", len(s.images)+1)
+	fmt.Fprintf(&s.defs, `<image id="%s" width="%s" height="%s" preserveAspectRatio="none" href="data:%s;base64,%s"/>`+"\n", id, svgNumber(width), svgNumber(height), http.DetectContentType(data), base64.StdEncoding.EncodeToString(data))
+	s.images[key] = id
+	return id
+}
+
+func (s *SvgSlides) drawImage(data []byte, x float64, y float64, width float64, height float64) {
+	fmt.Fprintf(&s.body, `<use href="#%s" x="%s" y="%s"/>`+"\n", s.image(data, width, height), svgNumber(x), svgNumber(y))
+}
+
+func (s *SvgSlides) drawQrCode(content string) error {
+	qrSize := s.pageConfig.qrCodeSize()
+	qr, err := qrcode.Encode(content, qrcode.Medium, qrCodeResolution)
+	if err != nil {
+		return err
+	}
+
+	x := (s.pageConfig.PageWidth - qrSize) / 2
+	y := (s.pageConfig.PageHeight - qrSize) / 2
+	s.drawImage(qr, x, y, qrSize, qrSize)
+
+	fontSize := s.pageConfig.FontSize
+	textWidth, err := s.measureText(content, "default", fontSize)
+	if err != nil {
+		return err
+	}
+
+	return s.writeText(content, (s.pageConfig.PageWidth-textWidth)/2, s.pageConfig.PageHeight-y+(y-float64(fontSize))/2, "default", fontSize, s.pageConfig.TextColor)
+}
+
+func (s *SvgSlides) drawLogo() error {
+	if len(s.pageConfig.Logo) == 0 {
+		return nil
+	}
+
+	width, height, err := fitImage(s.pageConfig.Logo, s.pageConfig.PageWidth/2, s.pageConfig.PageHeight/3)
+	if err != nil {
+		return err
+	}
+
+	s.drawImage(s.pageConfig.Logo, (s.pageConfig.PageWidth-width)/2, (s.pageConfig.PageHeight-height)/2, width, height)
+	return nil
+}
+
+// textShadowFilter defines the shadow of the text of a slide, which is
+// blurred like the shifted copies of the text in the PDF.
+func (s *SvgSlides) textShadowFilter() string {
+	shadow := s.pageConfig.TextShadow
+	if shadow == nil {
+		return ""
+	}
+
+	s.numFilters++
+	id := fmt.Sprintf("shadow%d", s.numFilters)
+	fmt.Fprintf(&s.defs, `<filter id="%s" x="-10%%" y="-10%%" width="120%%" height="120%%"><feDropShadow dx="%s" dy="%s" stdDeviation="%s" flood-color="#%s" flood-opacity="%g"/></filter>`+"\n", id, svgNumber(shadow.Offset), svgNumber(shadow.Offset), svgNumber(shadow.Blur/2), shadow.Color.Hex(), shadow.Opacity)
+	return id
+}
+
+// drawSlide draws a slide as a nested SVG element, which clips it to the page.
+func (s *SvgSlides) drawSlide(slide Slide, y float64) error {
+	fmt.Fprintf(&s.body, `<svg y="%s" width="%s" height="%s">`+"\n", svgNumber(y), svgNumber(s.pageConfig.PageWidth), svgNumber(s.pageConfig.PageHeight))
+	if len(s.pageConfig.BackgroundImage) > 0 {
+		s.drawImage(s.pageConfig.BackgroundImage, 0, 0, s.pageConfig.PageWidth, s.pageConfig.PageHeight)
+	} else {
+		fmt.Fprintf(&s.body, `<rect width="100%%" height="100%%" fill="#%s"/>`+"\n", s.pageConfig.BackgroundColor.Hex())
+	}
+
+	filter := s.textShadowFilter()
+	if filter != "" {
+		fmt.Fprintf(&s.body, `<g filter="url(#%s)">`+"\n", filter)
+	}
+
+	var err error
+	switch slide.Type {
+	case "blank":
+		err = s.drawLogo()
+	case "qr":
+		err = s.drawQrCode(slide.Text)
+	default:
+		err = drawnSlideText(s.pageConfig, s).write(slide)
+	}
+
+	if filter != "" {
+		s.body.WriteString("</g>\n")
+	}
+	s.body.WriteString("</svg>\n")
+
+	return err
+}
+
+// BuildSVG draws the slides of the deck one below another in an SVG image, for previews.
+// The blank slides between the items are left out.
+func BuildSVG(textDeck [][]string, items []ItemInfo, pageConfig PageConfig) (*bytes.Buffer, []ContentSlide, error) {
+	measure, err := NewFontMeasurer(pageConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	svg := SvgSlides{}
+	err = svg.Initialize(pageConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	slides := make([]Slide, 0)
+	for _, slide := range LayoutDeck(textDeck, items, pageConfig, measure) {
+		if slide.Type != "blank" {
+			slides = append(slides, slide)
+		}
+	}
+
+	for i, slide := range slides {
+		svg.pageConfig = SlidePageConfig(slide, items, pageConfig)
+		err = svg.drawSlide(slide, float64(i)*(pageConfig.PageHeight+svgSlideGap))
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	height := max(0, float64(len(slides))*(pageConfig.PageHeight+svgSlideGap)-svgSlideGap)
+
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%s" height="%s" viewBox="0 0 %s %s">`+"\n", svgNumber(pageConfig.PageWidth), svgNumber(height), svgNumber(pageConfig.PageWidth), svgNumber(height))
+	fmt.Fprintf(buf, "<defs>\n%s</defs>\n", svg.defs.String())
+	buf.WriteString(svg.body.String())
+	buf.WriteString("</svg>\n")
+
+	return buf, ContentSlides(slides), nil
+}
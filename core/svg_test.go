@@ -0,0 +1,36 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildSVG(t *testing.T) {
+	pageConfig := testPageConfig(t)
+	textDeck := [][]string{{"Pan kiedyś stanął nad brzegiem", "Szukał ludzi gotowych pójść za Nim"}}
+
+	buf, contents, err := BuildSVG(textDeck, nil, pageConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(contents) != 2 {
+		t.Fatalf("Expected the two verse slides without the blank ones, got %d slides", len(contents))
+	}
+
+	svg := buf.String()
+	if !strings.Contains(svg, `height="880"`) {
+		t.Errorf("Expected two slides with a gap between them to be 880 points tall")
+	}
+
+	letters := make(map[rune]bool)
+	for _, r := range strings.Join(textDeck[0], "") {
+		if r != ' ' {
+			letters[r] = true
+		}
+	}
+
+	if count := strings.Count(svg, "<path "); count != len(letters) {
+		t.Errorf("Expected every letter to be defined once, got %d paths for %d letters", count, len(letters))
+	}
+}
@@ -0,0 +1,235 @@
+package core
+
+import (
+	"math"
+
+	"github.com/signintech/gopdf"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+type TextOutline struct {
+	Color Color
+	Width float64
+}
+
+type TextShadow struct {
+	Color   Color
+	Offset  float64
+	Blur    float64
+	Opacity float64
+}
+
+const textEffectDirections = 16
+
+// pdfShadowDirections is fewer than the other outputs use, every copy of the text adds to the file.
+const pdfShadowDirections = 8
+
+// curveSteps is the number of lines a curve of a glyph is flattened into.
+const curveSteps = 4
+
+// gopdf writes 1.000 for this alpha, but unlike 1 it's not skipped as the default
+const opaqueAlpha = 0.9999
+
+// ringOffsets spreads points evenly on a circle around the text position.
+func ringOffsets(radius float64, directions int) [][2]float64 {
+	offsets := make([][2]float64, directions)
+	for i := range offsets {
+		angle := 2 * math.Pi * float64(i) / float64(directions)
+		offsets[i] = [2]float64{radius * math.Cos(angle), radius * math.Sin(angle)}
+	}
+
+	return offsets
+}
+
+func (pdf *PdfSlides) drawText(text string, x float64, y float64, color Color, transparency *gopdf.Transparency) error {
+	pdf.goPdf.SetX(x)
+	pdf.goPdf.SetY(y)
+	pdf.goPdf.SetFillColor(color.R, color.G, color.B)
+
+	return pdf.goPdf.CellWithOption(nil, text, gopdf.CellOption{
+		Align:        gopdf.Left | gopdf.Top,
+		Float:        gopdf.Right,
+		Transparency: transparency,
+	})
+}
+
+// glyphContours returns the contours of the glyphs as closed polygons on the page,
+// with the top edge of the text at y like a PDF cell. The curves are flattened.
+func glyphContours(f sfntFont, buf *sfnt.Buffer, text string, x float64, y float64, fontSize int) ([][]gopdf.Point, error) {
+	scale := float64(fontSize) / f.unitsPerEm
+	baseline := y + f.ascent*float64(fontSize)
+	ppem := fixed.I(int(f.unitsPerEm))
+
+	contours := make([][]gopdf.Point, 0)
+	var contour []gopdf.Point
+	endContour := func() {
+		// the contours that end where they started are closed by the polygon
+		if len(contour) > 1 && contour[0] == contour[len(contour)-1] {
+			contour = contour[:len(contour)-1]
+		}
+		if len(contour) > 1 {
+			contours = append(contours, contour)
+		}
+		contour = nil
+	}
+
+	advance := 0.0
+	for _, r := range text {
+		index, err := f.font.GlyphIndex(buf, r)
+		if err != nil {
+			return nil, err
+		}
+
+		segments, err := f.font.LoadGlyph(buf, index, ppem, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		point := func(p fixed.Point26_6) gopdf.Point {
+			return gopdf.Point{X: x + (advance+float64(p.X)/64)*scale, Y: baseline + float64(p.Y)/64*scale}
+		}
+		addPoint := func(p gopdf.Point) {
+			if len(contour) == 0 || contour[len(contour)-1] != p {
+				contour = append(contour, p)
+			}
+		}
+
+		for _, segment := range segments {
+			switch segment.Op {
+			case sfnt.SegmentOpMoveTo:
+				endContour()
+				addPoint(point(segment.Args[0]))
+			case sfnt.SegmentOpLineTo:
+				addPoint(point(segment.Args[0]))
+			case sfnt.SegmentOpQuadTo, sfnt.SegmentOpCubeTo:
+				start := contour[len(contour)-1]
+				controls := []gopdf.Point{start, point(segment.Args[0]), point(segment.Args[1])}
+				if segment.Op == sfnt.SegmentOpCubeTo {
+					controls = append(controls, point(segment.Args[2]))
+				}
+				for step := 1; step <= curveSteps; step++ {
+					addPoint(bezierPoint(controls, float64(step)/curveSteps))
+				}
+			}
+		}
+		endContour()
+
+		glyphAdvance, err := f.font.GlyphAdvance(buf, index, ppem, font.HintingNone)
+		if err != nil {
+			return nil, err
+		}
+		advance += float64(glyphAdvance) / 64
+	}
+
+	return contours, nil
+}
+
+// bezierPoint finds a point of a Bézier curve by its control points.
+func bezierPoint(controls []gopdf.Point, t float64) gopdf.Point {
+	points := append([]gopdf.Point(nil), controls...)
+	for n := len(points) - 1; n > 0; n-- {
+		for i := range n {
+			points[i] = gopdf.Point{X: points[i].X + (points[i+1].X-points[i].X)*t, Y: points[i].Y + (points[i+1].Y-points[i].Y)*t}
+		}
+	}
+
+	return points[0]
+}
+
+// sharpCorners finds the points of a contour where it turns by more than a right angle.
+func sharpCorners(contour []gopdf.Point) []int {
+	corners := make([]int, 0)
+	for i, point := range contour {
+		previous := contour[(i+len(contour)-1)%len(contour)]
+		next := contour[(i+1)%len(contour)]
+		if (point.X-previous.X)*(next.X-point.X)+(point.Y-previous.Y)*(next.Y-point.Y) < 0 {
+			corners = append(corners, i)
+		}
+	}
+
+	return corners
+}
+
+// strokeContours draws the outline along the contours of the glyphs, centered on them
+// and below the text, so that the stroke is twice as wide as the visible outline.
+// gopdf can't set the line join, and the default one would stick out of the sharp
+// corners, so the contours are stroked in parts between them and the corners are rounded.
+func (pdf *PdfSlides) strokeContours(contours [][]gopdf.Point, outline TextOutline) {
+	pdf.goPdf.SetLineWidth(2 * outline.Width)
+	pdf.goPdf.SetStrokeColor(outline.Color.R, outline.Color.G, outline.Color.B)
+	pdf.goPdf.SetFillColor(outline.Color.R, outline.Color.G, outline.Color.B)
+
+	for _, contour := range contours {
+		corners := sharpCorners(contour)
+		if len(corners) == 0 {
+			pdf.goPdf.Polygon(contour, "D")
+			continue
+		}
+
+		for i, start := range corners {
+			end := corners[(i+1)%len(corners)]
+			if end <= start {
+				end += len(contour)
+			}
+
+			part := make([]gopdf.Point, 0, end-start+1)
+			for j := start; j <= end; j++ {
+				part = append(part, contour[j%len(contour)])
+			}
+			// the polygon goes back along the part, turning around at its ends without a join
+			for j := len(part) - 2; j > 0; j-- {
+				part = append(part, part[j])
+			}
+			pdf.goPdf.Polygon(part, "D")
+
+			corner := make([]gopdf.Point, 0, textEffectDirections)
+			for _, offset := range ringOffsets(outline.Width, textEffectDirections) {
+				corner = append(corner, gopdf.Point{X: contour[start].X + offset[0], Y: contour[start].Y + offset[1]})
+			}
+			pdf.goPdf.Polygon(corner, "F")
+		}
+	}
+}
+
+// writeTextWithEffects draws the text over its outline and shadow. The outline is a stroke
+// along the glyphs, and the shadow is a few translucent copies of the text shifted around
+// in a circle, so that the overlapping copies fade out like a blur.
+func (pdf *PdfSlides) writeTextWithEffects(text string, x float64, y float64, family string, fontSize int, color Color) error {
+	var transparency *gopdf.Transparency
+
+	if shadow := pdf.pageConfig.TextShadow; shadow != nil {
+		offsets := [][2]float64{{0, 0}}
+		if shadow.Blur > 0 {
+			offsets = append(offsets, ringOffsets(shadow.Blur/2, pdfShadowDirections)...)
+		}
+
+		// the copies overlap in the middle, so that the opacity adds up to the requested one there
+		alpha := 1 - math.Pow(1-shadow.Opacity, 1/float64(len(offsets)))
+		shadowTransparency := &gopdf.Transparency{Alpha: alpha, BlendModeType: gopdf.Normal}
+		for _, offset := range offsets {
+			err := pdf.drawText(text, x+shadow.Offset+offset[0], y+shadow.Offset+offset[1], shadow.Color, shadowTransparency)
+			if err != nil {
+				return err
+			}
+		}
+
+		// the alpha stays in the graphics state, so it has to be reset explicitly,
+		// and only the text sets it, so an empty one does before the outline
+		transparency = &gopdf.Transparency{Alpha: opaqueAlpha, BlendModeType: gopdf.Normal}
+		if err := pdf.drawText("", x, y, color, transparency); err != nil {
+			return err
+		}
+	}
+
+	if outline := pdf.pageConfig.TextOutline; outline != nil && outline.Width > 0 {
+		contours, err := glyphContours(pdf.fonts[family], &pdf.buf, text, x, y, fontSize)
+		if err != nil {
+			return err
+		}
+		pdf.strokeContours(contours, *outline)
+	}
+
+	return pdf.drawText(text, x, y, color, transparency)
+}
@@ -0,0 +1,62 @@
+package core
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/signintech/gopdf"
+	"golang.org/x/image/font/sfnt"
+)
+
+func TestRingOffsets(t *testing.T) {
+	offsets := ringOffsets(2, textEffectDirections)
+
+	if len(offsets) != textEffectDirections {
+		t.Fatalf("Expected %d offsets, got %d", textEffectDirections, len(offsets))
+	}
+
+	for _, offset := range offsets {
+		radius := math.Hypot(offset[0], offset[1])
+		if math.Abs(radius-2) > 1e-9 {
+			t.Errorf("Expected every offset to lie on the circle, got radius %f", radius)
+		}
+	}
+}
+
+func TestGlyphContours(t *testing.T) {
+	pageConfig := testPageConfig(t)
+	fonts, err := parseFonts(pageConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf sfnt.Buffer
+	contours, err := glyphContours(fonts["default"], &buf, "I", 10, 20, 52)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the letter is a single bar standing on the baseline
+	if len(contours) != 1 || len(contours[0]) != 4 {
+		t.Fatalf("Expected a contour of 4 points, got %v", contours)
+	}
+	baseline := 20 + fonts["default"].ascent*52
+	for _, point := range contours[0] {
+		if point.X < 10 || point.X > 10+52 || point.Y < 20 || point.Y > baseline+1e-9 {
+			t.Errorf("Expected the contour within the text, got %v", point)
+		}
+	}
+}
+
+func TestSharpCorners(t *testing.T) {
+	square := []gopdf.Point{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 10, Y: 10}, {X: 0, Y: 10}}
+	if corners := sharpCorners(square); len(corners) != 0 {
+		t.Errorf("Expected no sharp corners in a square, got %v", corners)
+	}
+
+	triangle := []gopdf.Point{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 5, Y: 1}}
+	if corners := sharpCorners(triangle); !reflect.DeepEqual(corners, []int{0, 1}) {
+		t.Errorf("Expected the corners at the base of a flat triangle, got %v", corners)
+	}
+}
@@ -2,54 +2,98 @@ package core
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/rainycape/unidecode"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
 )
 
-const rows = 8
-const cols = 20
+const TugalCP1250 = "cp1250"
+const TugalISO88592 = "iso-8859-2"
+const TugalASCII = "ascii"
+
+// TugalPageMark is replaced with the number of the page in the header.
+const TugalPageMark = "{page}"
+
+// TugalProfile describes an LED board and the text file it reads its pages from.
+type TugalProfile struct {
+	Rows int
+	Cols int
+	// Header opens every page, with the page mark in it.
+	Header    string
+	UpperCase bool
+	Encoding  string
+	// BorderBlank marks the first and the last row of the blank pages with asterisks.
+	BorderBlank bool
+}
+
+// DefaultTugalProfile is the board the output was made for in the first place.
+var DefaultTugalProfile = TugalProfile{
+	Rows:        8,
+	Cols:        20,
+	Header:      "<------------------>\n<- Strona nr: " + TugalPageMark + " ->\n<------------------>",
+	UpperCase:   true,
+	Encoding:    TugalCP1250,
+	BorderBlank: true,
+}
 
-func Tugalize(textDeck [][]string) string {
+// Tugalize writes the deck as pages of an LED board, in the encoding of the board.
+func Tugalize(textDeck [][]string, profile TugalProfile) ([]byte, error) {
 	slides := ""
 
-	slides += pageHeader(0)
-	slides += emptySlide()
+	slides += profile.pageHeader(0)
+	slides += profile.emptySlide()
 
 	slideNo := 1
 	for _, song := range textDeck {
 		for _, verse := range song {
-			verse = strings.ToUpper(verse)
-			for _, slide := range textSlides(verse) {
-				slides += pageHeader(slideNo)
+			for _, slide := range profile.textSlides(verse) {
+				slides += profile.pageHeader(slideNo)
 				slides += slide
 				slideNo++
 			}
 		}
 
-		slides += pageHeader(slideNo)
-		slides += emptySlide()
+		slides += profile.pageHeader(slideNo)
+		slides += profile.emptySlide()
 		slideNo++
 	}
 
-	return slides
-
-	// encoder := charmap.Windows1250.NewEncoder()
-	// encodedBytes, _ := encoder.Bytes([]byte(slides))
+	return profile.encode(slides)
+}
 
-	// os.WriteFile("1208.txt", encodedBytes, 0644)
+// encode converts the text to the charset of the board. The characters
+// that the charset lacks are replaced with a question mark.
+func (p TugalProfile) encode(text string) ([]byte, error) {
+	switch p.Encoding {
+	case TugalASCII:
+		return []byte(unidecode.Unidecode(text)), nil
+	case TugalISO88592:
+		return encoding.ReplaceUnsupported(charmap.ISO8859_2.NewEncoder()).Bytes([]byte(text))
+	default:
+		return encoding.ReplaceUnsupported(charmap.Windows1250.NewEncoder()).Bytes([]byte(text))
+	}
 }
 
-func pageHeader(pageNumber int) string {
-	arrows := "<------------------>"
-	return fmt.Sprintf("%s\n<- Strona nr: %03d ->\n%s\n", arrows, pageNumber, arrows)
+func (p TugalProfile) pageHeader(pageNumber int) string {
+	if p.Header == "" {
+		return ""
+	}
+
+	return strings.ReplaceAll(p.Header, TugalPageMark, fmt.Sprintf("%03d", pageNumber)) + "\n"
 }
 
-func emptySlide() string {
-	emptyLine := strings.Repeat(" ", cols) + "\n"
-	asterisksLine := "*" + strings.Repeat(" ", cols-2) + "*" + "\n"
+func (p TugalProfile) emptySlide() string {
+	emptyLine := strings.Repeat(" ", p.Cols) + "\n"
+	asterisksLine := "*" + strings.Repeat(" ", p.Cols-2) + "*" + "\n"
 	slide := ""
 
-	for i := 0; i < rows; i++ {
-		if i == 0 || i == rows-1 {
+	for i := 0; i < p.Rows; i++ {
+		if p.BorderBlank && (i == 0 || i == p.Rows-1) {
 			slide += asterisksLine
 		} else {
 			slide += emptyLine
@@ -60,23 +104,196 @@ func emptySlide() string {
 	return slide
 }
 
-func textSlides(text string) []string {
+func (p TugalProfile) textSlides(text string) []string {
 	slides := make([]string, 0)
 
+	// the board has a cell for every character, whatever its size in UTF-8,
+	// and the invisible mark of the line end takes none
 	measureText := func(s string) (float64, error) {
-		return float64(len(s)), nil
+		return float64(utf8.RuneCountInString(strings.ReplaceAll(s, LineEndMark, ""))), nil
+	}
+
+	// the tags of the verse are read before the case is changed
+	text = PlainVerse(text)
+	if p.Encoding == TugalASCII {
+		// transliterated before measuring, since "…" takes three cells as "..."
+		text = unidecode.Unidecode(text)
+	}
+	if p.UpperCase {
+		text = strings.ToUpper(text)
 	}
 
 	lines := strings.Split(text, "\n")
-	brokenLines := BreakLongLines(lines, measureText, cols)
-	subPages := SplitLongSlide(brokenLines, rows)
+	brokenLines := BreakLongLines(lines, measureText, float64(p.Cols))
+	subPages := SplitLongSlide(brokenLines, p.Rows)
 
 	for _, subPage := range subPages {
 		currentSlide := strings.Join(subPage, "\n") + "\n"
-		currentSlide += strings.Repeat("\n", rows-len(subPage))
+		currentSlide += strings.Repeat("\n", p.Rows-len(subPage))
 
 		slides = append(slides, currentSlide)
 	}
 
 	return slides
 }
+
+// TugalSong is a song recovered from the pages of an LED board.
+type TugalSong struct {
+	Title  string
+	Verses []string
+}
+
+var tugalHeaderRegexp = regexp.MustCompile(`^<-.*\d+.*->$`)
+var tugalArrowsRegexp = regexp.MustCompile(`^<-+>$`)
+var tugalBorderRegexp = regexp.MustCompile(`^\*\s*\*$`)
+var tugalHintRegexp = regexp.MustCompile(`(?i)^<hint>.*</hint>$`)
+var sentenceEndRegexp = regexp.MustCompile(`[.!?]\s+\pL`)
+var verseEndRegexp = regexp.MustCompile(`[.!?…]["'”»)]*$`)
+
+// decodeTugal reads a file in the charset of the board, unless it's UTF-8 already.
+func decodeTugal(data []byte, charset string) (string, error) {
+	if utf8.Valid(data) {
+		return string(data), nil
+	}
+
+	decoder := charmap.Windows1250.NewDecoder()
+	if charset == TugalISO88592 {
+		decoder = charmap.ISO8859_2.NewDecoder()
+	}
+
+	decoded, err := decoder.Bytes(data)
+	return string(decoded), err
+}
+
+// tugalPages splits a file into the lines of its pages, without the headers.
+func tugalPages(text string) [][]string {
+	pages := make([][]string, 0)
+	var page []string
+
+	// the file ends with a line break, which doesn't start another row
+	text = strings.TrimSuffix(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, " ")
+		trimmed := strings.TrimSpace(line)
+
+		if tugalHeaderRegexp.MatchString(trimmed) {
+			if page != nil {
+				pages = append(pages, page)
+			}
+			page = make([]string, 0)
+			continue
+		}
+
+		if page == nil || tugalArrowsRegexp.MatchString(trimmed) {
+			continue
+		}
+
+		page = append(page, line)
+	}
+
+	if page != nil {
+		pages = append(pages, page)
+	}
+
+	return pages
+}
+
+// isBlankTugalPage tells whether a page has no text, with or without the border.
+func isBlankTugalPage(page []string) bool {
+	for _, line := range page {
+		trimmed := strings.TrimSpace(line)
+		if trimmed != "" && !tugalBorderRegexp.MatchString(trimmed) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// sentenceCase turns the upper case text of the board back into sentences.
+// The text that has lower case letters is left as it is.
+func sentenceCase(text string) string {
+	if strings.ToUpper(text) != text || strings.ToLower(text) == text {
+		return text
+	}
+
+	text = strings.ToLower(text)
+	text = sentenceEndRegexp.ReplaceAllStringFunc(text, strings.ToUpper)
+
+	first, size := utf8.DecodeRuneInString(text)
+	return strings.ToUpper(string(first)) + text[size:]
+}
+
+// continuesVerse tells whether a page goes on with the verse of the full page before it,
+// rather than starting a new one. The board has no mark for that, so the verse is taken
+// as continued unless it ends a sentence, and always when the page starts in lower case.
+func continuesVerse(previous string, page string) bool {
+	first, _ := utf8.DecodeRuneInString(page)
+	return unicode.IsLower(first) || !verseEndRegexp.MatchString(previous)
+}
+
+// ParseTugal recovers the songs from a file of LED board pages. The blank pages
+// separate the songs. A page with every row taken may be continued on the next one,
+// like the verses too long for the board.
+func ParseTugal(data []byte, charset string) ([]TugalSong, error) {
+	text, err := decodeTugal(data, charset)
+	if err != nil {
+		return nil, err
+	}
+
+	pages := tugalPages(text)
+
+	// the blank pages have every row, so the longest page shows the size of the board
+	rows := 0
+	for _, page := range pages {
+		rows = max(rows, len(page))
+	}
+
+	songs := make([]TugalSong, 0)
+	verses := make([]string, 0)
+	continued := false
+
+	endSong := func() {
+		if len(verses) > 0 {
+			for i, verse := range verses {
+				verses[i] = sentenceCase(verse)
+			}
+
+			title, _, _ := strings.Cut(verses[0], "\n")
+			songs = append(songs, TugalSong{
+				Title:  strings.TrimRight(title, ",.;:!? "),
+				Verses: verses,
+			})
+		}
+		verses = make([]string, 0)
+		continued = false
+	}
+
+	for _, page := range pages {
+		if isBlankTugalPage(page) {
+			endSong()
+			continue
+		}
+
+		lines := make([]string, 0)
+		for _, line := range page {
+			lines = append(lines, strings.TrimSpace(line))
+		}
+		verse := strings.Trim(strings.Join(lines, "\n"), "\n")
+
+		if tugalHintRegexp.MatchString(verse) {
+			continue
+		}
+
+		if continued && continuesVerse(verses[len(verses)-1], verse) {
+			verses[len(verses)-1] += "\n" + verse
+		} else {
+			verses = append(verses, verse)
+		}
+
+		continued = len(strings.Split(verse, "\n")) == rows
+	}
+	endSong()
+
+	return songs, nil
+}
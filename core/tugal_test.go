@@ -0,0 +1,134 @@
+package core
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTugalize(t *testing.T) {
+	textDeck := [][]string{{"Zróbcie Mu miejsce, Pan się przybliża"}}
+
+	data, err := Tugalize(textDeck, DefaultTugalProfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// "Ó" and "Ż" in Windows-1250
+	if !bytes.Contains(data, []byte("ZR\xd3BCIE")) || !bytes.Contains(data, []byte("PRZYBLI\xafA")) {
+		t.Errorf("Expected upper case text in Windows-1250, got %q", data)
+	}
+
+	if !bytes.Contains(data, []byte("<- Strona nr: 001 ->")) {
+		t.Errorf("Expected the numbered page headers, got %q", data)
+	}
+
+	bilingual := [][]string{{JoinTranslation("Barka", "Fishers of men")}}
+	data, err = Tugalize(bilingual, DefaultTugalProfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Contains(data, []byte("BARKA\nFISHERS OF MEN\n")) || bytes.Contains(data, []byte("TRANSLATION")) {
+		t.Errorf("Expected the translation below the original, got %q", data)
+	}
+}
+
+func TestTugalizeProfile(t *testing.T) {
+	profile := TugalProfile{Rows: 2, Cols: 12, Header: "# " + TugalPageMark, Encoding: TugalASCII}
+	textDeck := [][]string{{"Zróbcie miejsce Panu"}}
+
+	data, err := Tugalize(textDeck, profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := strings.Join([]string{
+		"# 000", "            ", "            ",
+		"# 001", "Zrobcie", "miejsce Panu",
+		"# 002", "            ", "            ",
+	}, "\n") + "\n"
+
+	if string(data) != expected {
+		t.Errorf("Expected %q, got %q", expected, data)
+	}
+
+	data, err = Tugalize([][]string{{"Wołam… Panu nadchodzącym"}}, profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		if len(line) > profile.Cols {
+			t.Errorf("Expected the rows to fit in %d columns, got %q", profile.Cols, line)
+		}
+	}
+}
+
+func TestParseTugal(t *testing.T) {
+	textDeck := [][]string{
+		{"<hint>Bar</hint>", "Pan kiedyś stanął nad brzegiem. Szukał ludzi gotowych pójść za Nim", "O Panie, to Ty na mnie spojrzałeś"},
+		{"Zróbcie Mu miejsce"},
+	}
+	profile := DefaultTugalProfile
+	profile.Rows = 3
+
+	data, err := Tugalize(textDeck, profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	songs, err := ParseTugal(data, TugalCP1250)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []TugalSong{
+		{
+			Title: "Pan kiedyś stanął",
+			Verses: []string{
+				"Pan kiedyś stanął\nnad brzegiem. Szukał\nludzi gotowych pójść\nza nim",
+				"O panie,\nto ty na mnie\nspojrzałeś",
+			},
+		},
+		{Title: "Zróbcie mu miejsce", Verses: []string{"Zróbcie mu miejsce"}},
+	}
+
+	if !reflect.DeepEqual(songs, expected) {
+		t.Errorf("Expected %q, got %q", expected, songs)
+	}
+}
+
+func TestParseTugalFullPage(t *testing.T) {
+	textDeck := [][]string{
+		{"Zróbcie Mu miejsce,\nPan idzie już.", "Drogi prostujcie,\nbo Pan jest blisko\ni zbawi nas"},
+	}
+	profile := DefaultTugalProfile
+	profile.Rows = 2
+
+	data, err := Tugalize(textDeck, profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	songs, err := ParseTugal(data, TugalCP1250)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the first verse fills the board but ends a sentence, the second one doesn't
+	expected := []TugalSong{
+		{
+			Title: "Zróbcie mu miejsce",
+			Verses: []string{
+				"Zróbcie mu miejsce,\npan idzie już.",
+				"Drogi prostujcie,\nbo pan jest blisko\ni zbawi nas",
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(songs, expected) {
+		t.Errorf("Expected %q, got %q", expected, songs)
+	}
+}
@@ -16,6 +16,10 @@ type Container struct {
 	Live    *services.LiveService
 	Users   *services.UsersService
 	Teams   *services.TeamsService
+	Images  *services.ImagesService
+	Themes  *services.ThemesService
+	Fonts   *services.FontsService
+	Import  *services.ImportService
 }
 
 func NewContainer(db *gorm.DB, redis *redis.Client) *Container {
@@ -29,7 +33,10 @@ func NewContainer(db *gorm.DB, redis *redis.Client) *Container {
 	liturgyRepo := repos.NewRedisLiturgyRepo(redis)
 	liturgy := services.NewLiturgyService(liturgyRepo)
 	liveRepo := repos.NewRedisLiveRepo(redis)
-	deck := services.NewDeckService(songs, liturgy)
+	images := services.NewImagesService(db, auth, teams)
+	themes := services.NewThemesService(db, teams)
+	fonts := services.NewFontsService(db, auth, teams)
+	deck := services.NewDeckService(songs, liturgy, teams, images, themes, fonts)
 
 	return &Container{
 		DB:      db,
@@ -40,6 +47,10 @@ func NewContainer(db *gorm.DB, redis *redis.Client) *Container {
 		Live:    services.NewLiveService(songs, liturgy, deck, liveRepo),
 		Users:   users,
 		Teams:   teams,
+		Images:  images,
+		Themes:  themes,
+		Fonts:   fonts,
+		Import:  services.NewImportService(db, songs, teams),
 	}
 }
 
@@ -51,7 +62,10 @@ func NewTestContainer(db *gorm.DB) *Container {
 	teams := services.NewTeamsService(db)
 	songs := services.NewSongsService(db, auth, teams)
 	liturgy := services.NewLiturgyService(repos.NewMemoryLiturgyRepo())
-	deck := services.NewDeckService(songs, liturgy)
+	images := services.NewImagesService(db, auth, teams)
+	themes := services.NewThemesService(db, teams)
+	fonts := services.NewFontsService(db, auth, teams)
+	deck := services.NewDeckService(songs, liturgy, teams, images, themes, fonts)
 
 	return &Container{
 		DB:      db,
@@ -62,5 +76,9 @@ func NewTestContainer(db *gorm.DB) *Container {
 		Live:    services.NewLiveService(songs, liturgy, deck, repos.NewMemoryLiveRepo()),
 		Users:   users,
 		Teams:   teams,
+		Images:  images,
+		Themes:  themes,
+		Fonts:   fonts,
+		Import:  services.NewImportService(db, songs, teams),
 	}
 }
@@ -1,31 +1,114 @@
 package dtos
 
 import (
+	"cmp"
 	"errors"
+	"fmt"
 	"regexp"
+	"strconv"
 
 	"github.com/hejmsdz/goslides/common"
 	"github.com/hejmsdz/goslides/core"
 )
 
 type DeckRequest struct {
-	Date            string     `json:"date"`
-	Items           []DeckItem `json:"items"`
-	Hints           bool       `json:"hints"`
-	Ratio           string     `json:"ratio"`
-	FontSize        int        `json:"fontSize"`
-	VerticalAlign   string     `json:"verticalAlign"`
-	TextColor       string     `json:"textColor"`
-	BackgroundColor string     `json:"backgroundColor"`
-	Format          string     `json:"format"`
-	Contents        bool       `json:"contents"`
+	Date        string     `json:"date"`
+	Items       []DeckItem `json:"items"`
+	Hints       bool       `json:"hints"`
+	Hyphenation bool       `json:"hyphenation"`
+	TeamID      string     `json:"teamId"`
+	ThemeID     string     `json:"themeId"`
+	TitleSlides bool       `json:"titleSlides"`
+	Format      string     `json:"format"`
+	Contents    bool       `json:"contents"`
+	Transpose   int        `json:"transpose"`
+	Capo        int        `json:"capo"`
+	PaperSize   string     `json:"paperSize"`
+	Columns     int        `json:"columns"`
+	Booklet     bool       `json:"booklet"`
+	// Language is the language of the items other than songs, which are
+	// hyphenated in the language of their lyrics. Polish when empty.
+	Language string `json:"language"`
+	// Tugal describes the LED board of the "txt" format, the default one when empty.
+	Tugal *TugalProfile `json:"tugal"`
+	DeckStyle
+}
+
+// TugalProfile overrides the options of the default LED board. The sizes left at zero
+// are the ones of the default board. The flags and the header are pointers, because
+// that board has them, so they can be turned off.
+type TugalProfile struct {
+	Rows        int     `json:"rows"`
+	Columns     int     `json:"columns"`
+	Header      *string `json:"header"`
+	UpperCase   *bool   `json:"upperCase"`
+	Encoding    string  `json:"encoding"`
+	BorderBlank *bool   `json:"borderBlank"`
+}
+
+func (t TugalProfile) Validate() error {
+	if t.Rows < 0 || t.Rows > 64 {
+		return errors.New("rows must be between 1 and 64, or 0 for the default")
+	}
+
+	if t.Columns != 0 && (t.Columns < 4 || t.Columns > 200) {
+		return errors.New("columns must be between 4 and 200, or 0 for the default")
+	}
+
+	if t.Header != nil && len([]rune(*t.Header)) > 500 {
+		return errors.New("header too long")
+	}
+
+	if t.Encoding != "" && t.Encoding != core.TugalCP1250 && t.Encoding != core.TugalISO88592 && t.Encoding != core.TugalASCII {
+		return errors.New("unsupported encoding")
+	}
+
+	return nil
+}
+
+// DeckStyle holds the options of a deck that can be saved in a theme.
+type DeckStyle struct {
+	HintContent       string  `json:"hintContent,omitempty"`
+	HintPlacement     string  `json:"hintPlacement,omitempty"`
+	Ratio             string  `json:"ratio,omitempty"`
+	Width             int     `json:"width,omitempty"`
+	Height            int     `json:"height,omitempty"`
+	Font              string  `json:"font,omitempty"`
+	FontSize          int     `json:"fontSize,omitempty"`
+	FitMode           string  `json:"fitMode,omitempty"`
+	MinFontSize       int     `json:"minFontSize,omitempty"`
+	LineBreaking      string  `json:"lineBreaking,omitempty"`
+	Margin            float64 `json:"margin,omitempty"`
+	VerticalAlign     string  `json:"verticalAlign,omitempty"`
+	HorizontalAlign   string  `json:"horizontalAlign,omitempty"`
+	TextColor         string  `json:"textColor,omitempty"`
+	ResponseColor     string  `json:"responseColor,omitempty"`
+	BackgroundColor   string  `json:"backgroundColor,omitempty"`
+	TextOutlineColor  string  `json:"textOutlineColor,omitempty"`
+	TextOutlineWidth  float64 `json:"textOutlineWidth,omitempty"`
+	TextShadowColor   string  `json:"textShadowColor,omitempty"`
+	TextShadowOffset  float64 `json:"textShadowOffset,omitempty"`
+	TextShadowBlur    float64 `json:"textShadowBlur,omitempty"`
+	BackgroundImage   string  `json:"backgroundImage,omitempty"`
+	BackgroundFit     string  `json:"backgroundFit,omitempty"`
+	BackgroundOverlay float64 `json:"backgroundOverlay,omitempty"`
+	Logo              string  `json:"logo,omitempty"`
+	TranslationLayout string  `json:"translationLayout,omitempty"`
+	TranslationColor  string  `json:"translationColor,omitempty"`
+	Credits           string  `json:"credits,omitempty"`
+	CCLILicense       string  `json:"ccliLicense,omitempty"`
 }
 
 type DeckItem struct {
-	ID       string   `json:"id"`
-	Type     string   `json:"type"`
-	Contents []string `json:"contents"`
-	Order    []int    `json:"order"`
+	ID                string   `json:"id"`
+	Type              string   `json:"type"`
+	Contents          []string `json:"contents"`
+	Order             []int    `json:"order"`
+	SecondaryLanguage string   `json:"secondaryLanguage"`
+	FontSize          int      `json:"fontSize"`
+	TextColor         string   `json:"textColor"`
+	BackgroundColor   string   `json:"backgroundColor"`
+	VerticalAlign     string   `json:"verticalAlign"`
 }
 
 type DeckResponse struct {
@@ -60,28 +143,38 @@ func (d DeckRequest) Validate() error {
 		return errors.New("too many items")
 	}
 
-	if d.FontSize > 0 && d.FontSize < 36 {
-		return errors.New("font size too small")
+	if err := d.DeckStyle.Validate(); err != nil {
+		return err
 	}
 
-	if d.FontSize > 72 {
-		return errors.New("font size too large")
+	if d.Language != "" && !languageRegexp.MatchString(d.Language) {
+		return errors.New("invalid language")
 	}
 
-	if d.Ratio != "" && d.Ratio != "16:9" && d.Ratio != "4:3" {
-		return errors.New("unsupported aspect ratio")
+	if d.Transpose < -11 || d.Transpose > 11 {
+		return errors.New("transpose must be between -11 and 11 semitones")
 	}
 
-	if d.VerticalAlign != "" && d.VerticalAlign != "top" && d.VerticalAlign != "bottom" && d.VerticalAlign != "center" {
-		return errors.New("unsupported vertical align")
+	if d.Capo < 0 || d.Capo > 11 {
+		return errors.New("capo must be between 0 and 11")
 	}
 
-	if d.TextColor != "" && !colorRegexp.MatchString(d.TextColor) {
-		return errors.New("invalid text color")
+	if d.PaperSize != "" && d.PaperSize != "A4" && d.PaperSize != "A5" {
+		return errors.New("unsupported paper size")
 	}
 
-	if d.BackgroundColor != "" && !colorRegexp.MatchString(d.BackgroundColor) {
-		return errors.New("invalid background color")
+	if d.Columns < 0 || d.Columns > 3 {
+		return errors.New("columns must be between 1 and 3")
+	}
+
+	if d.Booklet && d.PaperSize != "A5" {
+		return errors.New("booklet printing requires the A5 paper size")
+	}
+
+	if d.Tugal != nil {
+		if err := d.Tugal.Validate(); err != nil {
+			return err
+		}
 	}
 
 	for _, item := range d.Items {
@@ -93,8 +186,244 @@ func (d DeckRequest) Validate() error {
 	return nil
 }
 
+func (s DeckStyle) Validate() error {
+	if len(s.Font) > 64 {
+		return errors.New("invalid font")
+	}
+
+	if err := validateFontSize(s.FontSize); err != nil {
+		return err
+	}
+
+	if s.FitMode != "" && s.FitMode != core.FitSplit && s.FitMode != core.FitShrink && s.FitMode != core.FitShrinkThenSplit {
+		return errors.New("unsupported fit mode")
+	}
+
+	if s.MinFontSize != 0 && s.MinFontSize < 12 {
+		return errors.New("minimum font size too small")
+	}
+
+	if s.FontSize > 0 && s.MinFontSize > s.FontSize {
+		return errors.New("minimum font size must not exceed the font size")
+	}
+
+	if s.Margin < 0 || s.Margin > 72 {
+		return errors.New("margin must be between 0 and 72")
+	}
+
+	if s.LineBreaking != "" && s.LineBreaking != core.LineBreakBalanced && s.LineBreaking != core.LineBreakOptimal {
+		return errors.New("unsupported line breaking")
+	}
+
+	if s.HintContent != "" && s.HintContent != core.HintLetters && s.HintContent != core.HintTitle && s.HintContent != core.HintFirstLine && s.HintContent != core.HintNumber {
+		return errors.New("unsupported hint content")
+	}
+
+	if s.HintPlacement != "" && s.HintPlacement != core.HintSlide && s.HintPlacement != core.HintOverlay {
+		return errors.New("unsupported hint placement")
+	}
+
+	if s.Ratio != "" {
+		if ratio, ok := parseRatio(s.Ratio); !ok || !isSaneRatio(ratio) {
+			return errors.New("unsupported aspect ratio")
+		}
+	}
+
+	if (s.Width == 0) != (s.Height == 0) {
+		return errors.New("width and height must be given together")
+	}
+
+	if s.Width != 0 {
+		if s.Width < minPageDimension || s.Width > maxPageDimension || s.Height < minPageDimension || s.Height > maxPageDimension {
+			return fmt.Errorf("width and height must be between %d and %d pixels", minPageDimension, maxPageDimension)
+		}
+
+		if !isSaneRatio(float64(s.Width) / float64(s.Height)) {
+			return errors.New("unsupported aspect ratio")
+		}
+	}
+
+	if err := validateVerticalAlign(s.VerticalAlign); err != nil {
+		return err
+	}
+
+	if s.HorizontalAlign != "" && s.HorizontalAlign != core.AlignLeft && s.HorizontalAlign != core.AlignCenter && s.HorizontalAlign != core.AlignRight && s.HorizontalAlign != core.AlignJustify {
+		return errors.New("unsupported horizontal align")
+	}
+
+	if s.TextColor != "" && !colorRegexp.MatchString(s.TextColor) {
+		return errors.New("invalid text color")
+	}
+
+	if s.ResponseColor != "" && !colorRegexp.MatchString(s.ResponseColor) {
+		return errors.New("invalid response color")
+	}
+
+	if s.TranslationLayout != "" && s.TranslationLayout != core.TranslationStacked && s.TranslationLayout != core.TranslationColumns {
+		return errors.New("unsupported translation layout")
+	}
+
+	if s.TranslationColor != "" && !colorRegexp.MatchString(s.TranslationColor) {
+		return errors.New("invalid translation color")
+	}
+
+	if s.Credits != "" && s.Credits != core.CreditsAll && s.Credits != core.CreditsLast {
+		return errors.New("unsupported credits placement")
+	}
+
+	if s.CCLILicense != "" && !ccliNumberRegexp.MatchString(s.CCLILicense) {
+		return errors.New("invalid CCLI license number")
+	}
+
+	if s.BackgroundColor != "" && !colorRegexp.MatchString(s.BackgroundColor) {
+		return errors.New("invalid background color")
+	}
+
+	if s.TextOutlineColor != "" && !colorRegexp.MatchString(s.TextOutlineColor) {
+		return errors.New("invalid text outline color")
+	}
+
+	if s.TextOutlineWidth < 0 || s.TextOutlineWidth > 5 {
+		return errors.New("text outline width must be between 0 and 5")
+	}
+
+	if s.TextShadowColor != "" && !colorRegexp.MatchString(s.TextShadowColor) {
+		return errors.New("invalid text shadow color")
+	}
+
+	if s.TextShadowOffset < 0 || s.TextShadowOffset > 10 {
+		return errors.New("text shadow offset must be between 0 and 10")
+	}
+
+	if s.TextShadowBlur < 0 || s.TextShadowBlur > 10 {
+		return errors.New("text shadow blur must be between 0 and 10")
+	}
+
+	if s.BackgroundFit != "" && s.BackgroundFit != core.BackgroundCover && s.BackgroundFit != core.BackgroundContain {
+		return errors.New("unsupported background fit")
+	}
+
+	if s.BackgroundOverlay < 0 || s.BackgroundOverlay > 0.9 {
+		return errors.New("background overlay must be between 0 and 0.9")
+	}
+
+	return nil
+}
+
+var ratioRegexp = regexp.MustCompile(`^([1-9]\d{0,3}):([1-9]\d{0,3})$`)
+
+// the limits of the page size in pixels, and of the proportions of its sides
+const minPageDimension = 64
+const maxPageDimension = 4096
+const maxPageRatio = 4.0
+
+func parseRatio(ratio string) (float64, bool) {
+	match := ratioRegexp.FindStringSubmatch(ratio)
+	if match == nil {
+		return 0, false
+	}
+
+	width, _ := strconv.Atoi(match[1])
+	height, _ := strconv.Atoi(match[2])
+	return float64(width) / float64(height), true
+}
+
+func isSaneRatio(ratio float64) bool {
+	return ratio >= 1/maxPageRatio && ratio <= maxPageRatio
+}
+
+// PageRatio is the width of the slides divided by their height. The dimensions
+// in pixels take precedence over the ratio, and the slides are 16:9 by default.
+func (s DeckStyle) PageRatio() float64 {
+	if s.Width > 0 && s.Height > 0 {
+		return float64(s.Width) / float64(s.Height)
+	}
+
+	if ratio, ok := parseRatio(s.Ratio); ok {
+		return ratio
+	}
+
+	return 16.0 / 9.0
+}
+
+// WithDefaults fills the options that are not set in the style with the ones from the theme.
+// The ratio and the dimensions are taken together, so that the ratio of the style
+// isn't overridden by the dimensions of the theme.
+func (s DeckStyle) WithDefaults(theme DeckStyle) DeckStyle {
+	if s.Ratio == "" && s.Width == 0 && s.Height == 0 {
+		s.Ratio, s.Width, s.Height = theme.Ratio, theme.Width, theme.Height
+	}
+
+	s.HintContent = cmp.Or(s.HintContent, theme.HintContent)
+	s.HintPlacement = cmp.Or(s.HintPlacement, theme.HintPlacement)
+	s.Font = cmp.Or(s.Font, theme.Font)
+	s.FontSize = cmp.Or(s.FontSize, theme.FontSize)
+	s.FitMode = cmp.Or(s.FitMode, theme.FitMode)
+	s.MinFontSize = cmp.Or(s.MinFontSize, theme.MinFontSize)
+	s.LineBreaking = cmp.Or(s.LineBreaking, theme.LineBreaking)
+	s.Margin = cmp.Or(s.Margin, theme.Margin)
+	s.VerticalAlign = cmp.Or(s.VerticalAlign, theme.VerticalAlign)
+	s.HorizontalAlign = cmp.Or(s.HorizontalAlign, theme.HorizontalAlign)
+	s.TextColor = cmp.Or(s.TextColor, theme.TextColor)
+	s.ResponseColor = cmp.Or(s.ResponseColor, theme.ResponseColor)
+	s.BackgroundColor = cmp.Or(s.BackgroundColor, theme.BackgroundColor)
+	s.TextOutlineColor = cmp.Or(s.TextOutlineColor, theme.TextOutlineColor)
+	s.TextOutlineWidth = cmp.Or(s.TextOutlineWidth, theme.TextOutlineWidth)
+	s.TextShadowColor = cmp.Or(s.TextShadowColor, theme.TextShadowColor)
+	s.TextShadowOffset = cmp.Or(s.TextShadowOffset, theme.TextShadowOffset)
+	s.TextShadowBlur = cmp.Or(s.TextShadowBlur, theme.TextShadowBlur)
+	s.BackgroundImage = cmp.Or(s.BackgroundImage, theme.BackgroundImage)
+	s.BackgroundFit = cmp.Or(s.BackgroundFit, theme.BackgroundFit)
+	s.BackgroundOverlay = cmp.Or(s.BackgroundOverlay, theme.BackgroundOverlay)
+	s.Logo = cmp.Or(s.Logo, theme.Logo)
+	s.TranslationLayout = cmp.Or(s.TranslationLayout, theme.TranslationLayout)
+	s.TranslationColor = cmp.Or(s.TranslationColor, theme.TranslationColor)
+	s.Credits = cmp.Or(s.Credits, theme.Credits)
+	s.CCLILicense = cmp.Or(s.CCLILicense, theme.CCLILicense)
+
+	return s
+}
+
+func validateFontSize(fontSize int) error {
+	if fontSize > 0 && fontSize < 36 {
+		return errors.New("font size too small")
+	}
+
+	if fontSize > 72 {
+		return errors.New("font size too large")
+	}
+
+	return nil
+}
+
+func validateVerticalAlign(verticalAlign string) error {
+	if verticalAlign != "" && verticalAlign != "top" && verticalAlign != "bottom" && verticalAlign != "center" {
+		return errors.New("unsupported vertical align")
+	}
+
+	return nil
+}
+
 func (i DeckItem) Validate() error {
-	// TODO
+	if i.SecondaryLanguage != "" && !languageRegexp.MatchString(i.SecondaryLanguage) {
+		return errors.New("invalid secondary language")
+	}
+
+	if err := validateFontSize(i.FontSize); err != nil {
+		return err
+	}
+
+	if i.TextColor != "" && !colorRegexp.MatchString(i.TextColor) {
+		return errors.New("invalid text color")
+	}
+
+	if i.BackgroundColor != "" && !colorRegexp.MatchString(i.BackgroundColor) {
+		return errors.New("invalid background color")
+	}
+
+	if err := validateVerticalAlign(i.VerticalAlign); err != nil {
+		return err
+	}
 
 	return nil
 }
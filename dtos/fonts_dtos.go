@@ -0,0 +1,39 @@
+package dtos
+
+import (
+	"github.com/hejmsdz/goslides/fonts"
+	"github.com/hejmsdz/goslides/models"
+)
+
+type FontResponse struct {
+	ID      string `json:"id"`
+	Family  string `json:"family"`
+	Bundled bool   `json:"bundled"`
+}
+
+func NewFontResponse(font *models.Font) FontResponse {
+	return FontResponse{
+		ID:     font.UUID.String(),
+		Family: font.Family,
+	}
+}
+
+func NewFontListResponse(teamFonts []*models.Font) []FontResponse {
+	resp := make([]FontResponse, len(teamFonts))
+	for i, font := range teamFonts {
+		resp[i] = NewFontResponse(font)
+	}
+	return resp
+}
+
+func NewBundledFontListResponse(bundled []fonts.BundledFont) []FontResponse {
+	resp := make([]FontResponse, len(bundled))
+	for i, font := range bundled {
+		resp[i] = FontResponse{
+			ID:      font.ID,
+			Family:  font.Family,
+			Bundled: true,
+		}
+	}
+	return resp
+}
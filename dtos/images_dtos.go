@@ -0,0 +1,21 @@
+package dtos
+
+import (
+	"github.com/hejmsdz/goslides/models"
+)
+
+type ImageResponse struct {
+	ID          string `json:"id"`
+	ContentType string `json:"contentType"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+}
+
+func NewImageResponse(image *models.Image) ImageResponse {
+	return ImageResponse{
+		ID:          image.UUID.String(),
+		ContentType: image.ContentType,
+		Width:       image.Width,
+		Height:      image.Height,
+	}
+}
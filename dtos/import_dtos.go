@@ -0,0 +1,124 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hejmsdz/goslides/models"
+)
+
+type ImportedSongResponse struct {
+	ID     *string  `json:"id"`
+	Title  string   `json:"title"`
+	Lyrics []string `json:"lyrics"`
+}
+
+type ImportResponse struct {
+	Songs  []ImportedSongResponse `json:"songs"`
+	DryRun bool                   `json:"dryRun"`
+}
+
+func NewImportResponse(songs []models.Song, dryRun bool) ImportResponse {
+	resp := ImportResponse{
+		Songs:  make([]ImportedSongResponse, len(songs)),
+		DryRun: dryRun,
+	}
+
+	for i, song := range songs {
+		var id *string
+		if song.UUID != uuid.Nil {
+			songID := song.UUID.String()
+			id = &songID
+		}
+
+		resp.Songs[i] = ImportedSongResponse{
+			ID:     id,
+			Title:  song.Title,
+			Lyrics: song.FormatLyrics(models.FormatLyricsOptions{Raw: true}),
+		}
+	}
+
+	return resp
+}
+
+// SongBackup is a song in the JSON export of a team's songs, with everything needed
+// to import it back. The lyrics are raw, with the comments and the chords.
+type SongBackup struct {
+	ID               string    `json:"id"`
+	Title            string    `json:"title"`
+	Subtitle         string    `json:"subtitle,omitempty"`
+	Author           string    `json:"author,omitempty"`
+	Copyright        string    `json:"copyright,omitempty"`
+	CCLINumber       string    `json:"ccliNumber,omitempty"`
+	SongbookNumber   string    `json:"songbookNumber,omitempty"`
+	Language         string    `json:"language"`
+	Lyrics           []string  `json:"lyrics"`
+	VerseOrder       string    `json:"verseOrder,omitempty"`
+	TeamID           *string   `json:"teamId"`
+	OverriddenSongID *string   `json:"overriddenSongId"`
+	TranslationOfID  *string   `json:"translationOfId"`
+	IsUnofficial     bool      `json:"isUnofficial,omitempty"`
+	IsDraft          bool      `json:"isDraft,omitempty"`
+	CreatedAt        time.Time `json:"createdAt"`
+	UpdatedAt        time.Time `json:"updatedAt"`
+}
+
+func NewSongBackup(song *models.Song) SongBackup {
+	backup := SongBackup{
+		ID:             song.UUID.String(),
+		Title:          song.Title,
+		Subtitle:       song.Subtitle.String,
+		Author:         song.Author.String,
+		Copyright:      song.Copyright.String,
+		CCLINumber:     song.CCLINumber.String,
+		SongbookNumber: song.SongbookNumber.String,
+		Language:       song.Language,
+		Lyrics:         song.FormatLyrics(models.FormatLyricsOptions{Raw: true}),
+		VerseOrder:     song.VerseOrder,
+		IsUnofficial:   song.IsUnofficial,
+		IsDraft:        song.IsDraft,
+		CreatedAt:      song.CreatedAt,
+		UpdatedAt:      song.UpdatedAt,
+	}
+
+	if song.Team != nil {
+		teamID := song.Team.UUID.String()
+		backup.TeamID = &teamID
+	}
+
+	if song.OverriddenSong != nil {
+		songID := song.OverriddenSong.UUID.String()
+		backup.OverriddenSongID = &songID
+	}
+
+	if song.TranslationOf != nil {
+		songID := song.TranslationOf.UUID.String()
+		backup.TranslationOfID = &songID
+	}
+
+	return backup
+}
+
+// SongRequest recreates the song in the given team.
+func (b SongBackup) SongRequest(teamUUID string) SongRequest {
+	input := SongRequest{
+		Title:          b.Title,
+		Subtitle:       b.Subtitle,
+		Lyrics:         b.Lyrics,
+		VerseOrder:     b.VerseOrder,
+		Author:         b.Author,
+		Copyright:      b.Copyright,
+		CCLINumber:     b.CCLINumber,
+		SongbookNumber: b.SongbookNumber,
+		TeamID:         teamUUID,
+		IsOverride:     b.OverriddenSongID != nil,
+		IsDraft:        b.IsDraft,
+		Language:       b.Language,
+	}
+
+	if b.TranslationOfID != nil {
+		input.TranslationOfID = *b.TranslationOfID
+	}
+
+	return input
+}
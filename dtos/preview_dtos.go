@@ -0,0 +1,30 @@
+package dtos
+
+import "errors"
+
+type PreviewRequest struct {
+	Lyrics  []string `json:"lyrics"`
+	Verse   *int     `json:"verse"`
+	TeamID  string   `json:"teamId"`
+	ThemeID string   `json:"themeId"`
+	DeckStyle
+}
+
+// maxPreviewLength limits the lyrics that are typeset on every keystroke in the editor.
+const maxPreviewLength = 20000
+
+func (p PreviewRequest) Validate() error {
+	if p.Verse != nil && (*p.Verse < 0 || *p.Verse > 999) {
+		return errors.New("invalid verse")
+	}
+
+	length := 0
+	for _, verse := range p.Lyrics {
+		length += len([]rune(verse))
+	}
+	if length > maxPreviewLength {
+		return errors.New("lyrics too long")
+	}
+
+	return p.DeckStyle.Validate()
+}
@@ -2,6 +2,7 @@ package dtos
 
 import (
 	"errors"
+	"regexp"
 
 	"github.com/hejmsdz/goslides/models"
 )
@@ -14,6 +15,8 @@ type SongSummaryResponse struct {
 	TeamID       *string `json:"teamId"`
 	IsOverride   bool    `json:"isOverride"`
 	IsUnofficial bool    `json:"isUnofficial,omitempty"`
+	IsDraft      bool    `json:"isDraft,omitempty"`
+	Language     string  `json:"language"`
 }
 
 func NewSongSummaryResponse(song *models.Song) SongSummaryResponse {
@@ -29,6 +32,8 @@ func NewSongSummaryResponse(song *models.Song) SongSummaryResponse {
 		Slug:         song.Slug,
 		IsOverride:   song.OverriddenSongID != nil,
 		IsUnofficial: song.IsUnofficial,
+		IsDraft:      song.IsDraft,
+		Language:     song.Language,
 	}
 
 	if song.Team != nil {
@@ -64,8 +69,13 @@ func NewPaginatedSongListResponse(songs []models.Song, total int64) PaginatedSon
 type SongDetailResponse struct {
 	SongSummaryResponse
 	Author           *string  `json:"author"`
+	Copyright        *string  `json:"copyright"`
+	CCLINumber       *string  `json:"ccliNumber"`
+	SongbookNumber   *string  `json:"songbookNumber"`
 	OverriddenSongID *string  `json:"overriddenSongId"`
+	TranslationOfID  *string  `json:"translationOfId"`
 	Lyrics           []string `json:"lyrics"`
+	VerseOrder       string   `json:"verseOrder"`
 	CanEdit          bool     `json:"canEdit"`
 	CanDelete        bool     `json:"canDelete"`
 	CanOverride      bool     `json:"canOverride"`
@@ -78,16 +88,42 @@ func NewSongDetailResponse(song *models.Song, canEdit bool, canDelete bool, canO
 		overriddenSongID = &songID
 	}
 
+	var translationOfID *string
+	if song.TranslationOf != nil {
+		songID := song.TranslationOf.UUID.String()
+		translationOfID = &songID
+	}
+
 	var author *string
 	if song.Author.Valid {
 		author = &song.Author.String
 	}
 
+	var copyright *string
+	if song.Copyright.Valid {
+		copyright = &song.Copyright.String
+	}
+
+	var ccliNumber *string
+	if song.CCLINumber.Valid {
+		ccliNumber = &song.CCLINumber.String
+	}
+
+	var songbookNumber *string
+	if song.SongbookNumber.Valid {
+		songbookNumber = &song.SongbookNumber.String
+	}
+
 	return SongDetailResponse{
 		SongSummaryResponse: NewSongSummaryResponse(song),
 		Author:              author,
+		Copyright:           copyright,
+		CCLINumber:          ccliNumber,
+		SongbookNumber:      songbookNumber,
 		OverriddenSongID:    overriddenSongID,
+		TranslationOfID:     translationOfID,
 		Lyrics:              song.FormatLyrics(models.FormatLyricsOptions{Raw: true}),
+		VerseOrder:          song.VerseOrder,
 		CanEdit:             canEdit,
 		CanDelete:           canDelete,
 		CanOverride:         canOverride,
@@ -95,15 +131,26 @@ func NewSongDetailResponse(song *models.Song, canEdit bool, canDelete bool, canO
 }
 
 type SongRequest struct {
-	Title        string   `json:"title"`
-	Subtitle     string   `json:"subtitle"`
-	Lyrics       []string `json:"lyrics"`
-	Author       string   `json:"author"`
-	TeamID       string   `json:"teamId"`
-	IsOverride   bool     `json:"isOverride"`
-	IsUnofficial bool     `json:"isUnofficial"`
+	Title           string   `json:"title"`
+	Subtitle        string   `json:"subtitle"`
+	Lyrics          []string `json:"lyrics"`
+	VerseOrder      string   `json:"verseOrder"`
+	Author          string   `json:"author"`
+	Copyright       string   `json:"copyright"`
+	CCLINumber      string   `json:"ccliNumber"`
+	SongbookNumber  string   `json:"songbookNumber"`
+	TeamID          string   `json:"teamId"`
+	IsOverride      bool     `json:"isOverride"`
+	IsUnofficial    bool     `json:"isUnofficial"`
+	IsDraft         bool     `json:"isDraft"`
+	Language        string   `json:"language"`
+	TranslationOfID string   `json:"translationOfId"`
 }
 
+var languageRegexp = regexp.MustCompile(`^[a-z]{2}$`)
+var ccliNumberRegexp = regexp.MustCompile(`^\d{1,10}$`)
+var verseOrderRegexp = regexp.MustCompile(`^\s*(\w+\s*)*$`)
+
 func (r SongRequest) Validate() error {
 	if r.IsOverride {
 		if r.TeamID == "" {
@@ -115,5 +162,21 @@ func (r SongRequest) Validate() error {
 		return errors.New("teamId must be empty when creating an unofficial song")
 	}
 
+	if r.Language != "" && !languageRegexp.MatchString(r.Language) {
+		return errors.New("invalid language")
+	}
+
+	if r.CCLINumber != "" && !ccliNumberRegexp.MatchString(r.CCLINumber) {
+		return errors.New("invalid CCLI number")
+	}
+
+	if len([]rune(r.SongbookNumber)) > 10 {
+		return errors.New("songbook number too long")
+	}
+
+	if len(r.VerseOrder) > 500 || !verseOrderRegexp.MatchString(r.VerseOrder) {
+		return errors.New("invalid verse order")
+	}
+
 	return nil
 }
@@ -1,10 +1,14 @@
 package dtos
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"strings"
 	"time"
+	"unicode/utf8"
 
+	"github.com/google/uuid"
 	"github.com/hejmsdz/goslides/models"
 )
 
@@ -32,6 +36,26 @@ type TeamRequest struct {
 	Name string `json:"name"`
 }
 
+type TeamUpdateRequest struct {
+	Name            *string `json:"name"`
+	BackgroundImage *string `json:"backgroundImage"`
+	Logo            *string `json:"logo"`
+}
+
+func (t TeamUpdateRequest) Validate() error {
+	if t.Name != nil {
+		if strings.TrimSpace(*t.Name) == "" {
+			return errors.New("name is required")
+		}
+
+		if utf8.RuneCountInString(*t.Name) > 100 {
+			return errors.New("name too long")
+		}
+	}
+
+	return nil
+}
+
 type TeamMember struct {
 	ID   string `json:"id"`
 	Name string `json:"name"`
@@ -39,7 +63,18 @@ type TeamMember struct {
 
 type TeamDetailsResponse struct {
 	TeamResponse
-	Members []TeamMember `json:"members"`
+	Members         []TeamMember `json:"members"`
+	BackgroundImage *string      `json:"backgroundImage"`
+	Logo            *string      `json:"logo"`
+}
+
+func uuidToStringPtr(id *uuid.UUID) *string {
+	if id == nil {
+		return nil
+	}
+
+	s := id.String()
+	return &s
 }
 
 func NewTeamDetailsResponse(team *models.Team, members []*models.User) TeamDetailsResponse {
@@ -52,8 +87,10 @@ func NewTeamDetailsResponse(team *models.Team, members []*models.User) TeamDetai
 	}
 
 	return TeamDetailsResponse{
-		TeamResponse: NewTeamResponse(team),
-		Members:      membersResp,
+		TeamResponse:    NewTeamResponse(team),
+		Members:         membersResp,
+		BackgroundImage: uuidToStringPtr(team.BackgroundImageUUID),
+		Logo:            uuidToStringPtr(team.LogoUUID),
 	}
 }
 
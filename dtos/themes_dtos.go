@@ -0,0 +1,58 @@
+package dtos
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hejmsdz/goslides/models"
+)
+
+type ThemeRequest struct {
+	Name  string    `json:"name"`
+	Style DeckStyle `json:"style"`
+}
+
+func (t ThemeRequest) Validate() error {
+	if strings.TrimSpace(t.Name) == "" {
+		return errors.New("name is required")
+	}
+
+	if utf8.RuneCountInString(t.Name) > 100 {
+		return errors.New("name too long")
+	}
+
+	return t.Style.Validate()
+}
+
+type ThemeResponse struct {
+	ID    string    `json:"id"`
+	Name  string    `json:"name"`
+	Style DeckStyle `json:"style"`
+}
+
+// ThemeStyle decodes the style saved in a theme.
+func ThemeStyle(theme *models.Theme) (DeckStyle, error) {
+	var style DeckStyle
+	err := json.Unmarshal([]byte(theme.Style), &style)
+	return style, err
+}
+
+func NewThemeResponse(theme *models.Theme) ThemeResponse {
+	style, _ := ThemeStyle(theme)
+
+	return ThemeResponse{
+		ID:    theme.UUID.String(),
+		Name:  theme.Name,
+		Style: style,
+	}
+}
+
+func NewThemeListResponse(themes []*models.Theme) []ThemeResponse {
+	resp := make([]ThemeResponse, len(themes))
+	for i, theme := range themes {
+		resp[i] = NewThemeResponse(theme)
+	}
+	return resp
+}
@@ -0,0 +1,69 @@
+// Package fonts embeds the fonts bundled with the server, so that
+// decks don't depend on the working directory.
+package fonts
+
+import (
+	"embed"
+	"fmt"
+	"slices"
+)
+
+//go:embed *.ttf
+var files embed.FS
+
+const Default = "source-sans-pro"
+
+// Font is a typeface with its regular style and optionally the bold and italic ones.
+type Font struct {
+	Family  string
+	Regular []byte
+	Bold    []byte
+	Italic  []byte
+}
+
+type BundledFont struct {
+	ID     string
+	Family string
+}
+
+var bundled = []BundledFont{
+	{Default, "Source Sans Pro"},
+	{"dejavu-sans", "DejaVu Sans"},
+	{"dejavu-serif", "DejaVu Serif"},
+}
+
+// mustRead reads a style of a bundled font. Every bundled font comes with all its styles,
+// so a missing one is a mistake in the build rather than a style to go without.
+func mustRead(name string) []byte {
+	data, err := files.ReadFile(name)
+	if err != nil {
+		panic(fmt.Sprintf("bundled font file missing: %s", name))
+	}
+
+	return data
+}
+
+// Bundled loads a bundled font by its ID, the font files are named after it.
+func Bundled(id string) (*Font, bool) {
+	index := slices.IndexFunc(bundled, func(font BundledFont) bool { return font.ID == id })
+	if index < 0 {
+		return nil, false
+	}
+
+	return &Font{
+		Family:  bundled[index].Family,
+		Regular: mustRead(id + ".ttf"),
+		Bold:    mustRead(id + "-bold.ttf"),
+		Italic:  mustRead(id + "-italic.ttf"),
+	}, true
+}
+
+// IsBundled tells whether an ID refers to a bundled font rather than an uploaded one.
+func IsBundled(id string) bool {
+	return slices.ContainsFunc(bundled, func(font BundledFont) bool { return font.ID == id })
+}
+
+// BundledFonts lists the fonts that can be picked without uploading one.
+func BundledFonts() []BundledFont {
+	return slices.Clone(bundled)
+}
@@ -0,0 +1,22 @@
+package fonts
+
+import "testing"
+
+func TestBundledStyles(t *testing.T) {
+	for _, bundledFont := range BundledFonts() {
+		font, ok := Bundled(bundledFont.ID)
+		if !ok {
+			t.Fatalf("%s is not bundled", bundledFont.ID)
+		}
+
+		if len(font.Regular) == 0 || len(font.Bold) == 0 || len(font.Italic) == 0 {
+			t.Errorf("%s is missing a style", bundledFont.ID)
+		}
+	}
+}
+
+func TestBundledUnknown(t *testing.T) {
+	if _, ok := Bundled("comic-sans"); ok {
+		t.Error("expected an unknown font not to be bundled")
+	}
+}
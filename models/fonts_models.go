@@ -0,0 +1,27 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Font struct {
+	gorm.Model
+	UUID        uuid.UUID `gorm:"uniqueIndex"`
+	TeamID      uint      `gorm:"not null"`
+	Team        *Team
+	Family      string `gorm:"not null"`
+	Data        []byte `gorm:"not null"`
+	BoldData    []byte
+	ItalicData  []byte
+	CreatedByID uint  `gorm:"not null"`
+	CreatedBy   *User `gorm:"foreignKey:CreatedByID"`
+}
+
+func (f *Font) BeforeSave(tx *gorm.DB) (err error) {
+	if f.UUID == uuid.Nil {
+		f.UUID = uuid.New()
+	}
+
+	return nil
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Image struct {
+	gorm.Model
+	UUID        uuid.UUID `gorm:"uniqueIndex"`
+	TeamID      uint      `gorm:"not null"`
+	Team        *Team
+	ContentType string
+	Width       int
+	Height      int
+	Data        []byte `gorm:"not null"`
+	CreatedByID uint   `gorm:"not null"`
+	CreatedBy   *User  `gorm:"foreignKey:CreatedByID"`
+}
+
+func (i *Image) BeforeSave(tx *gorm.DB) (err error) {
+	if i.UUID == uuid.Nil {
+		i.UUID = uuid.New()
+	}
+
+	return nil
+}
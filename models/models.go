@@ -9,6 +9,9 @@ var AllModels = []interface{}{
 	&Team{},
 	&Invitation{},
 	&Nonce{},
+	&Image{},
+	&Theme{},
+	&Font{},
 }
 
 func AutoMigrate(db *gorm.DB) error {
@@ -23,8 +23,16 @@ type Song struct {
 	Team             *Team
 	OverriddenSong   *Song
 	OverriddenSongID *uint
+	Language         string `gorm:"not null;default:pl"`
+	VerseOrder       string `gorm:"not null;default:''"`
+	TranslationOf    *Song
+	TranslationOfID  *uint
 	Author           sql.NullString
+	Copyright        sql.NullString
+	CCLINumber       sql.NullString
+	SongbookNumber   sql.NullString
 	IsUnofficial     bool  `gorm:"not null;default:false"`
+	IsDraft          bool  `gorm:"not null;default:false"`
 	CreatedByID      uint  `gorm:"not null"`
 	CreatedBy        *User `gorm:"foreignKey:CreatedByID"`
 	UpdatedByID      uint  `gorm:"not null"`
@@ -34,6 +42,8 @@ type Song struct {
 var verseName = regexp.MustCompile(`^\[(\w+)\]\s+`)
 var verseRef = regexp.MustCompile(`^%(\w+)$`)
 
+const DefaultLanguage = "pl"
+
 const commentSymbol = "//"
 const lineBreakSymbol = " * "
 
@@ -48,10 +58,53 @@ func (s *Song) BeforeSave(tx *gorm.DB) (err error) {
 	return nil
 }
 
+// matchVerseName finds the name at the start of a verse, like "[ref] ".
+// A chord at the start of a verse, like "[G] ", is not a name.
+func matchVerseName(verse string) []string {
+	match := verseName.FindStringSubmatch(verse)
+	if match == nil || core.IsChord("["+match[1]+"]") {
+		return nil
+	}
+
+	return match
+}
+
+// uncommented is the verse without the comment symbol, if it's commented out.
+func uncommented(verse string) string {
+	return strings.TrimLeft(strings.TrimPrefix(verse, commentSymbol), " ")
+}
+
 type FormatLyricsOptions struct {
-	Raw   bool
-	Hints bool
-	Order []int
+	Raw         bool
+	Hints       bool
+	HintContent string
+	Chords      bool
+	Order       []int
+}
+
+// hint is the text shown before the song, by default the first letters of its title.
+// A song without a number in the songbook falls back to them too.
+func (s Song) hint(content string, lyrics []string) string {
+	switch content {
+	case core.HintTitle:
+		return s.Title
+	case core.HintFirstLine:
+		if len(lyrics) > 0 {
+			firstLine, _, _ := strings.Cut(core.PlainVerse(core.StripChords(lyrics[0])), "\n")
+			return firstLine
+		}
+	case core.HintNumber:
+		if s.SongbookNumber.String != "" {
+			return s.SongbookNumber.String
+		}
+	}
+
+	utfTitle := []rune(s.Title)
+	if len(utfTitle) < 2 {
+		return ""
+	}
+
+	return string(utfTitle[0:min(3, len(utfTitle))])
 }
 
 func (s Song) FormatLyrics(options FormatLyricsOptions) []string {
@@ -60,22 +113,17 @@ func (s Song) FormatLyrics(options FormatLyricsOptions) []string {
 	lyrics := make([]string, 0)
 	namedVerses := make(map[string]string)
 
-	if options.Hints {
-		utfTitle := []rune(s.Title)
-		if len(utfTitle) >= 2 {
-			hint := string(utfTitle[0:3])
-			lyrics = append(lyrics, core.HintStartTag+hint+core.HintEndTag)
-		}
+	// the stored order is used in place of the whole lyrics, but unlike the given one,
+	// it doesn't bring back the commented out verses
+	order := options.Order
+	if order == nil && !options.Raw {
+		order = s.verseOrder(verses)
 	}
-
-	var order []int
-	if options.Order == nil {
+	if order == nil {
 		order = make([]int, len(verses))
 		for i := range verses {
 			order[i] = i
 		}
-	} else {
-		order = options.Order
 	}
 
 	for _, index := range order {
@@ -103,16 +151,223 @@ func (s Song) FormatLyrics(options FormatLyricsOptions) []string {
 				}
 			} else {
 				verse = strings.ReplaceAll(verse, lineBreakSymbol, "\n")
-				if match := verseName.FindStringSubmatch(verse); match != nil {
+				if match := matchVerseName(verse); match != nil {
 					name := match[1]
 					verse = verse[len(match[0]):]
 					namedVerses[name] = verse
 				}
 			}
+
+			if !options.Chords {
+				verse = core.StripChords(verse)
+			}
+		}
+
+		lyrics = append(lyrics, verse)
+	}
+
+	if options.Hints {
+		if hint := s.hint(options.HintContent, lyrics); hint != "" {
+			lyrics = append([]string{core.HintStartTag + hint + core.HintEndTag}, lyrics...)
+		}
+	}
+
+	return lyrics
+}
+
+// verseOrder finds the verses named in the stored verse order, a list of names
+// separated with spaces like in OpenLyrics. It's nil when the song has no order,
+// so the verses are shown as they are. The verses without a name can't be listed,
+// so they are shown after the last repetition of the listed verse they follow in the lyrics.
+func (s Song) verseOrder(verses []string) []int {
+	if s.VerseOrder == "" {
+		return nil
+	}
+
+	names := strings.Fields(s.VerseOrder)
+	listed := make(map[string]bool)
+	for _, name := range names {
+		listed[name] = true
+	}
+
+	indices := make(map[string]int)
+	following := make(map[int][]int)
+	previous := -1
+	for i, verse := range verses {
+		if strings.HasPrefix(verse, commentSymbol) || verseRef.MatchString(verse) {
+			continue
+		}
+
+		match := matchVerseName(verse)
+		if match == nil {
+			following[previous] = append(following[previous], i)
+		} else if _, ok := indices[match[1]]; !ok && listed[match[1]] {
+			indices[match[1]] = i
+			previous = i
+		}
+	}
+
+	if len(indices) == 0 {
+		return nil
+	}
+
+	last := make(map[int]int)
+	for i, name := range names {
+		if index, ok := indices[name]; ok {
+			last[index] = i
+		}
+	}
+
+	order := append([]int{}, following[-1]...)
+	for i, name := range names {
+		if index, ok := indices[name]; ok {
+			order = append(order, index)
+			if last[index] == i {
+				order = append(order, following[index]...)
+			}
+		}
+	}
+
+	return order
+}
+
+// shownVerses is the order the verses are shown in when none is given: the stored one,
+// or else the verses as they are, without the commented out ones.
+func (s Song) shownVerses(verses []string) []int {
+	if order := s.verseOrder(verses); order != nil {
+		return order
+	}
+
+	order := make([]int, 0, len(verses))
+	for i, verse := range verses {
+		if !strings.HasPrefix(verse, commentSymbol) {
+			order = append(order, i)
 		}
+	}
 
+	return order
+}
+
+// verseKeys tells which verses of two versions of a song match: the named verses
+// and the references to them by the name, the others by their position among them.
+func verseKeys(verses []string) []string {
+	keys := make([]string, len(verses))
+	position := 0
+	for i, verse := range verses {
+		verse = uncommented(verse)
+		if match := verseRef.FindStringSubmatch(verse); match != nil {
+			keys[i] = match[1]
+		} else if match := matchVerseName(verse); match != nil {
+			keys[i] = match[1]
+		} else {
+			position++
+			keys[i] = fmt.Sprintf("#%d", position)
+		}
+	}
+
+	return keys
+}
+
+// FormatTranslation formats a translation of the original song in the order of the verses
+// of the original. The verses are matched by their names, so the translation can arrange them
+// in its own way, and the ones missing from the translation are left empty.
+func (s Song) FormatTranslation(original Song, options FormatLyricsOptions) []string {
+	originalVerses := strings.Split(original.Lyrics, "\n\n")
+	order := options.Order
+	if order == nil {
+		// the original is shown in its own order, which the translation follows by the names too
+		order = original.shownVerses(originalVerses)
+	}
+
+	verses := strings.Split(s.Lyrics, "\n\n")
+	indices := make(map[string]int)
+	for i, key := range verseKeys(verses) {
+		// a reference is formatted like the verse it refers to
+		if _, ok := indices[key]; !ok && !verseRef.MatchString(uncommented(verses[i])) {
+			indices[key] = i
+		}
+	}
+
+	originalKeys := verseKeys(originalVerses)
+	lyrics := make([]string, 0, len(order))
+	for _, index := range order {
+		if index >= len(originalKeys) {
+			continue
+		}
+
+		verse := ""
+		if translationIndex, ok := indices[originalKeys[index]]; ok {
+			verse = s.FormatLyrics(FormatLyricsOptions{Order: []int{translationIndex}, Chords: options.Chords})[0]
+		}
 		lyrics = append(lyrics, verse)
 	}
 
 	return lyrics
 }
+
+// OpenLyrics describes the song for the OpenLyrics export. The verses without a name
+// are named after their position and the references to the named verses are kept
+// in the verse order.
+func (s Song) OpenLyrics() core.OpenLyricsSong {
+	verses := strings.Split(s.Lyrics, "\n\n")
+
+	usedNames := make(map[string]bool)
+	for _, verse := range verses {
+		if match := matchVerseName(verse); match != nil {
+			usedNames[match[1]] = true
+		}
+	}
+
+	song := core.OpenLyricsSong{
+		Title:          s.Title,
+		Subtitle:       s.Subtitle.String,
+		Author:         s.Author.String,
+		Copyright:      s.Copyright.String,
+		CCLINumber:     s.CCLINumber.String,
+		SongbookNumber: s.SongbookNumber.String,
+		Language:       s.Language,
+		VerseOrder:     s.VerseOrder,
+		Verses:         make([]core.OpenLyricsVerse, 0),
+	}
+	if s.Team != nil {
+		song.Songbook = s.Team.Name
+	}
+
+	order := make([]string, 0)
+	hasReferences := false
+	number := 0
+	for _, verse := range verses {
+		if strings.HasPrefix(verse, commentSymbol) || strings.TrimSpace(verse) == "" {
+			continue
+		}
+
+		if match := verseRef.FindStringSubmatch(verse); match != nil {
+			order = append(order, match[1])
+			hasReferences = true
+			continue
+		}
+
+		verse = strings.ReplaceAll(verse, lineBreakSymbol, "\n")
+
+		var name string
+		if match := matchVerseName(verse); match != nil {
+			name = match[1]
+			verse = verse[len(match[0]):]
+		} else {
+			for name == "" || usedNames[name] {
+				number++
+				name = fmt.Sprintf("v%d", number)
+			}
+			usedNames[name] = true
+		}
+
+		order = append(order, name)
+		song.Verses = append(song.Verses, core.OpenLyricsVerse{Name: name, Text: verse})
+	}
+
+	if song.VerseOrder == "" && hasReferences {
+		song.VerseOrder = strings.Join(order, " ")
+	}
+
+	return song
+}
@@ -16,6 +16,8 @@ type Team struct {
 	CreatedBy                *User `gorm:"foreignKey:CreatedByID"`
 	CanAccessUnofficialSongs bool
 	Users                    []*User `gorm:"many2many:user_teams;"`
+	BackgroundImageUUID      *uuid.UUID
+	LogoUUID                 *uuid.UUID
 }
 
 func (t *Team) BeforeSave(tx *gorm.DB) (err error) {
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Theme struct {
+	gorm.Model
+	UUID        uuid.UUID `gorm:"uniqueIndex"`
+	TeamID      uint      `gorm:"not null"`
+	Team        *Team
+	Name        string `gorm:"not null"`
+	Style       string `gorm:"type:text;not null"` // JSON encoded dtos.DeckStyle
+	CreatedByID uint   `gorm:"not null"`
+	CreatedBy   *User  `gorm:"foreignKey:CreatedByID"`
+}
+
+func (t *Theme) BeforeSave(tx *gorm.DB) (err error) {
+	if t.UUID == uuid.Nil {
+		t.UUID = uuid.New()
+	}
+
+	return nil
+}
@@ -1,9 +1,10 @@
 package routers
 
 import (
+	"bytes"
 	"io"
 	"net/http"
-	"strings"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -19,6 +20,8 @@ func RegisterDeckRoutes(r gin.IRouter, dic *di.Container) {
 	h := NewDeckHandler(dic)
 
 	r.POST("/deck", h.Auth.OptionalAuthMiddleware, h.PostDeck)
+	r.POST("/preview", h.Auth.OptionalAuthMiddleware, h.PostPreview)
+	r.GET("/songs/:id/preview", h.Auth.OptionalAuthMiddleware, h.GetSongPreview)
 }
 
 type DeckHandler struct {
@@ -47,32 +50,75 @@ func (h *DeckHandler) PostDeck(c *gin.Context) {
 
 	user := h.Auth.GetCurrentUser(c)
 
-	textDeck, ok := h.Deck.BuildTextSlides(deck, user)
+	deck, err := h.Deck.ApplyTheme(deck, user)
+	if err != nil {
+		common.ReturnError(c, err)
+		return
+	}
+
+	textDeck, items, ok := h.Deck.BuildTextSlides(deck, user)
 	if !ok {
 		common.ReturnAPIError(c, http.StatusInternalServerError, "failed to get lyrics", nil)
 		return
 	}
 
-	pageConfig := h.Deck.GetPageConfig(deck)
+	pageConfig, err := h.Deck.GetPageConfig(deck, user)
+	if err != nil {
+		common.ReturnError(c, err)
+		return
+	}
 
 	extension := ""
 	var file io.Reader
 	var contents []core.ContentSlide
-	var err error
 
 	switch deck.Format {
 	case "txt":
 		extension = ".txt"
-		text := core.Tugalize(textDeck)
-		file = strings.NewReader(text)
+		var text []byte
+		text, err = core.Tugalize(textDeck, h.Deck.GetTugalProfile(deck))
+		file = bytes.NewReader(text)
+
+	case "pptx":
+		extension = ".pptx"
+		file, contents, err = core.BuildPPTX(textDeck, items, pageConfig)
+
+	case "odp":
+		extension = ".odp"
+		file, contents, err = core.BuildODP(textDeck, items, pageConfig)
+
+	case "html":
+		extension = ".html"
+		file, contents, err = core.BuildHTML(textDeck, items, pageConfig)
+
+	case core.ImagePNG, core.ImageJPEG:
+		extension = ".zip"
+		file, contents, err = core.BuildImages(textDeck, items, pageConfig, h.Deck.GetImageOptions(deck, pageConfig))
+
+	case "stage":
+		extension = ".html"
+		file, contents, err = core.BuildStageDisplay(textDeck, items, pageConfig)
+
+	case "stage-pdf":
+		extension = ".pdf"
+		file, contents, err = core.BuildStagePDF(textDeck, items, pageConfig)
+
+	case "chords":
+		extension = ".pdf"
+		file, err = core.BuildChordSheetPDF(textDeck, items, pageConfig, h.Deck.GetChordSheetOptions(deck))
+
+	case "songbook":
+		extension = ".pdf"
+		file, err = core.BuildSongbookPDF(textDeck, items, h.Deck.GetSongbookConfig(deck, pageConfig))
 
 	default:
 		extension = ".pdf"
-		file, contents, err = core.BuildPDF(textDeck, pageConfig)
-		if err != nil {
-			common.ReturnError(c, err)
-			return
-		}
+		file, contents, err = core.BuildPDF(textDeck, items, pageConfig)
+	}
+
+	if err != nil {
+		common.ReturnError(c, err)
+		return
 	}
 
 	fileName := uuid.New().String() + extension
@@ -85,3 +131,59 @@ func (h *DeckHandler) PostDeck(c *gin.Context) {
 	resp := dtos.NewDeckResponse(common.GetPublicURL(fileName), contents)
 	c.JSON(http.StatusOK, resp)
 }
+
+// svgContentType is the MIME type of the slide previews.
+const svgContentType = "image/svg+xml"
+
+func (h *DeckHandler) PostPreview(c *gin.Context) {
+	var input dtos.PreviewRequest
+	if err := c.ShouldBind(&input); err != nil {
+		common.ReturnBadRequestError(c, err)
+		return
+	}
+
+	if err := input.Validate(); err != nil {
+		common.ReturnAPIError(c, http.StatusUnprocessableEntity, err.Error(), err)
+		return
+	}
+
+	user := h.Auth.GetCurrentUser(c)
+	svg, err := h.Deck.PreviewLyrics(input, user)
+	if err != nil {
+		common.ReturnError(c, err)
+		return
+	}
+
+	c.Data(http.StatusOK, svgContentType, svg.Bytes())
+}
+
+// GetSongPreview draws a saved song in the default style, or the style of a theme.
+func (h *DeckHandler) GetSongPreview(c *gin.Context) {
+	input := dtos.PreviewRequest{
+		TeamID:  c.Query("teamId"),
+		ThemeID: c.Query("themeId"),
+	}
+
+	if verseStr := c.Query("verse"); verseStr != "" {
+		verse, err := strconv.Atoi(verseStr)
+		if err != nil {
+			common.ReturnBadRequestError(c, err)
+			return
+		}
+		input.Verse = &verse
+	}
+
+	if err := input.Validate(); err != nil {
+		common.ReturnAPIError(c, http.StatusUnprocessableEntity, err.Error(), err)
+		return
+	}
+
+	user := h.Auth.GetCurrentUser(c)
+	svg, err := h.Deck.PreviewSong(c.Param("id"), input, user)
+	if err != nil {
+		common.ReturnError(c, err)
+		return
+	}
+
+	c.Data(http.StatusOK, svgContentType, svg.Bytes())
+}
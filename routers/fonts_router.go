@@ -0,0 +1,95 @@
+package routers
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hejmsdz/goslides/common"
+	"github.com/hejmsdz/goslides/di"
+	"github.com/hejmsdz/goslides/dtos"
+	"github.com/hejmsdz/goslides/fonts"
+	"github.com/hejmsdz/goslides/services"
+)
+
+func RegisterFontRoutes(r gin.IRouter, dic *di.Container) {
+	h := NewFontsHandler(dic)
+	auth := dic.Auth.AuthMiddleware
+
+	r.GET("/fonts", h.GetBundledFonts)
+	r.GET("/teams/:uuid/fonts", auth, h.GetTeamFonts)
+	r.POST("/teams/:uuid/fonts", auth, h.PostFont)
+}
+
+type FontsHandler struct {
+	Fonts *services.FontsService
+	Auth  *services.AuthService
+}
+
+func NewFontsHandler(dic *di.Container) *FontsHandler {
+	return &FontsHandler{dic.Fonts, dic.Auth}
+}
+
+func (h *FontsHandler) GetBundledFonts(c *gin.Context) {
+	c.JSON(http.StatusOK, dtos.NewBundledFontListResponse(fonts.BundledFonts()))
+}
+
+func (h *FontsHandler) GetTeamFonts(c *gin.Context) {
+	user := h.Auth.GetCurrentUser(c)
+
+	teamFonts, err := h.Fonts.GetTeamFonts(user, c.Param("uuid"))
+	if err != nil {
+		common.ReturnError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.NewFontListResponse(teamFonts))
+}
+
+// readFontFile reads an uploaded font, the missing optional files are empty.
+func readFontFile(c *gin.Context, field string) ([]byte, error) {
+	fileHeader, err := c.FormFile(field)
+	if err == http.ErrMissingFile {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	// read one byte over the limit, so that the service can tell the file is too large
+	return io.ReadAll(io.LimitReader(file, services.MaxFontSize+1))
+}
+
+func (h *FontsHandler) PostFont(c *gin.Context) {
+	user := h.Auth.GetCurrentUser(c)
+	teamUUID := c.Param("uuid")
+
+	files := make([][]byte, 3)
+	for i, field := range []string{"file", "bold", "italic"} {
+		data, err := readFontFile(c, field)
+		if err != nil {
+			common.ReturnBadRequestError(c, err)
+			return
+		}
+		files[i] = data
+	}
+
+	if len(files[0]) == 0 {
+		common.ReturnAPIError(c, http.StatusBadRequest, "font file is missing", nil)
+		return
+	}
+
+	font, err := h.Fonts.UploadFont(user, teamUUID, files[0], files[1], files[2])
+	if err != nil {
+		common.ReturnError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dtos.NewFontResponse(font))
+}
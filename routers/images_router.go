@@ -0,0 +1,75 @@
+package routers
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hejmsdz/goslides/common"
+	"github.com/hejmsdz/goslides/di"
+	"github.com/hejmsdz/goslides/dtos"
+	"github.com/hejmsdz/goslides/services"
+)
+
+func RegisterImageRoutes(r gin.IRouter, dic *di.Container) {
+	h := NewImagesHandler(dic)
+	auth := dic.Auth.AuthMiddleware
+
+	r.POST("/teams/:uuid/images", auth, h.PostImage)
+	r.GET("/images/:id", auth, h.GetImage)
+}
+
+type ImagesHandler struct {
+	Images *services.ImagesService
+	Auth   *services.AuthService
+}
+
+func NewImagesHandler(dic *di.Container) *ImagesHandler {
+	return &ImagesHandler{dic.Images, dic.Auth}
+}
+
+func (h *ImagesHandler) PostImage(c *gin.Context) {
+	user := h.Auth.GetCurrentUser(c)
+	teamUUID := c.Param("uuid")
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		common.ReturnBadRequestError(c, err)
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		common.ReturnBadRequestError(c, err)
+		return
+	}
+	defer file.Close()
+
+	// read one byte over the limit, so that the service can tell the file is too large
+	data, err := io.ReadAll(io.LimitReader(file, services.MaxImageSize+1))
+	if err != nil {
+		common.ReturnBadRequestError(c, err)
+		return
+	}
+
+	image, err := h.Images.UploadImage(user, teamUUID, data)
+	if err != nil {
+		common.ReturnError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dtos.NewImageResponse(image))
+}
+
+func (h *ImagesHandler) GetImage(c *gin.Context) {
+	user := h.Auth.GetCurrentUser(c)
+
+	image, err := h.Images.GetImage(c.Param("id"), user)
+	if err != nil {
+		common.ReturnError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "private, max-age=86400")
+	c.Data(http.StatusOK, image.ContentType, image.Data)
+}
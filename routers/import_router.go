@@ -0,0 +1,89 @@
+package routers
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hejmsdz/goslides/common"
+	"github.com/hejmsdz/goslides/di"
+	"github.com/hejmsdz/goslides/dtos"
+	"github.com/hejmsdz/goslides/services"
+)
+
+func RegisterImportRoutes(r gin.IRouter, dic *di.Container) {
+	h := NewImportHandler(dic)
+	auth := dic.Auth.AuthMiddleware
+
+	r.POST("/teams/:uuid/import/tugal", auth, h.PostImportTugal)
+	r.POST("/songs/import", auth, h.PostImportSongs)
+}
+
+type ImportHandler struct {
+	Import *services.ImportService
+	Auth   *services.AuthService
+}
+
+func NewImportHandler(dic *di.Container) *ImportHandler {
+	return &ImportHandler{dic.Import, dic.Auth}
+}
+
+// readFormFile reads the uploaded file, one byte over the limit,
+// so that the service can tell the file is too large.
+func readFormFile(c *gin.Context) ([]byte, error) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return io.ReadAll(io.LimitReader(file, services.MaxImportSize+1))
+}
+
+func (h *ImportHandler) PostImportTugal(c *gin.Context) {
+	user := h.Auth.GetCurrentUser(c)
+	teamUUID := c.Param("uuid")
+	dryRun := c.Query("dryRun") == "true"
+
+	data, err := readFormFile(c)
+	if err != nil {
+		common.ReturnBadRequestError(c, err)
+		return
+	}
+
+	songs, err := h.Import.ImportTugal(user, teamUUID, data, c.PostForm("encoding"), dryRun)
+	if err != nil {
+		common.ReturnError(c, err)
+		return
+	}
+
+	status := http.StatusCreated
+	if dryRun {
+		status = http.StatusOK
+	}
+
+	c.JSON(status, dtos.NewImportResponse(songs, dryRun))
+}
+
+func (h *ImportHandler) PostImportSongs(c *gin.Context) {
+	user := h.Auth.GetCurrentUser(c)
+
+	data, err := readFormFile(c)
+	if err != nil {
+		common.ReturnBadRequestError(c, err)
+		return
+	}
+
+	songs, err := h.Import.ImportSongs(user, c.PostForm("teamId"), data)
+	if err != nil {
+		common.ReturnError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dtos.NewSongListResponse(songs))
+}
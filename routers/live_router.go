@@ -9,6 +9,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/hejmsdz/goslides/common"
+	"github.com/hejmsdz/goslides/core"
 	"github.com/hejmsdz/goslides/di"
 	"github.com/hejmsdz/goslides/dtos"
 	"github.com/hejmsdz/goslides/services"
@@ -22,6 +23,8 @@ func RegisterLiveRoutes(r gin.IRouter, dic *di.Container) {
 	r.PUT("/live/:key", h.Auth.OptionalAuthMiddleware, h.PutLive)
 	r.GET("/live/:key", h.GetLive)
 	r.GET("/live/:key/status", h.GetLiveStatus)
+	r.GET("/live/:key/stage", h.GetLiveStage)
+	r.GET("/live/:key/stage/slides", h.GetLiveStageSlides)
 	r.DELETE("/live/:key", h.DeleteLive)
 	r.POST("/live/:key/page", h.PostLivePage)
 }
@@ -171,6 +174,42 @@ func (h *LiveHandler) GetLiveStatus(c *gin.Context) {
 	c.JSON(http.StatusOK, dtos.NewLiveSessionStatusResponse(session))
 }
 
+// GetLiveStage serves the stage display, which follows the pages of the session from its event stream.
+func (h *LiveHandler) GetLiveStage(c *gin.Context) {
+	key := c.Param("key")
+
+	_, ok := h.Live.GetSession(key)
+	if !ok {
+		common.ReturnAPIError(c, http.StatusNotFound, "live session not found", nil)
+		return
+	}
+
+	// the URLs are relative to /live/:key/stage
+	stage, err := core.BuildStageHTML(key, nil, core.StageOptions{
+		SlidesURL: "stage/slides",
+		EventsURL: "../" + key,
+	})
+	if err != nil {
+		common.ReturnError(c, err)
+		return
+	}
+
+	c.Data(http.StatusOK, "text/html; charset=utf-8", stage.Bytes())
+}
+
+func (h *LiveHandler) GetLiveStageSlides(c *gin.Context) {
+	key := c.Param("key")
+
+	session, ok := h.Live.GetSession(key)
+	if !ok {
+		common.ReturnAPIError(c, http.StatusNotFound, "live session not found", nil)
+		return
+	}
+
+	c.Header("Cache-Control", "no-cache")
+	c.File(common.PublicFilePath(services.StageFileName(session.FileName)))
+}
+
 func (h *LiveHandler) DeleteLive(c *gin.Context) {
 	key := c.Param("key")
 	token := c.Query("token")
@@ -1,8 +1,11 @@
 package routers
 
 import (
+	"bytes"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/hejmsdz/goslides/common"
@@ -22,6 +25,8 @@ func RegisterSongRoutes(r gin.IRouter, dic *di.Container) {
 	r.GET("/songs/:id", optionalAuth, h.GetSong)
 	r.PATCH("/songs/:id", auth, h.PatchSong)
 	r.DELETE("/songs/:id", auth, h.DeleteSong)
+	r.GET("/songs/:id/openlyrics", optionalAuth, h.GetSongOpenLyrics)
+	r.GET("/teams/:uuid/songs/export", auth, h.GetTeamSongsExport)
 	r.GET("/lyrics/:id", optionalAuth, h.GetLyrics)
 }
 
@@ -185,3 +190,39 @@ func (h *SongsHandler) GetLyrics(c *gin.Context) {
 	resp := song.FormatLyrics(models.FormatLyricsOptions{Raw: raw})
 	c.JSON(http.StatusOK, resp)
 }
+
+func (h *SongsHandler) GetSongOpenLyrics(c *gin.Context) {
+	user := h.Auth.GetCurrentUser(c)
+	data, song, err := h.Songs.ExportOpenLyrics(c.Param("id"), user)
+
+	if err != nil {
+		common.ReturnError(c, err)
+		return
+	}
+
+	fileName := common.Slugify(song.Title, false) + ".xml"
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, fileName))
+	c.Data(http.StatusOK, "application/xml; charset=utf-8", data)
+}
+
+func (h *SongsHandler) GetTeamSongsExport(c *gin.Context) {
+	user := h.Auth.GetCurrentUser(c)
+	format := c.DefaultQuery("format", services.SongsExportJSON)
+
+	songs, team, err := h.Songs.ExportTeamSongs(user, c.Param("uuid"), format)
+	if err != nil {
+		common.ReturnError(c, err)
+		return
+	}
+
+	// the archive is written in full first, so that an error can still be returned
+	buf := new(bytes.Buffer)
+	if err := services.WriteSongsArchive(buf, songs, format); err != nil {
+		common.ReturnAPIError(c, http.StatusInternalServerError, "failed to write the songs archive", err)
+		return
+	}
+
+	fileName := strings.ReplaceAll(common.Slugify(team.Name, false), " ", "-") + "-songs.zip"
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, fileName))
+	c.Data(http.StatusOK, "application/zip", buf.Bytes())
+}
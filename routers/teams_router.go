@@ -17,6 +17,7 @@ func RegisterTeamRoutes(r gin.IRouter, dic *di.Container) {
 	r.GET("/teams", auth, h.GetTeams)
 	r.GET("/teams/:uuid", auth, h.GetTeam)
 	r.POST("/teams", auth, h.PostTeam)
+	r.PATCH("/teams/:uuid", auth, h.PatchTeam)
 	r.POST("/teams/:uuid/invite", auth, h.PostTeamInvite)
 	r.POST("/teams/join", auth, h.PostTeamJoin)
 	r.POST("/teams/:uuid/leave", auth, h.PostTeamLeave)
@@ -82,6 +83,48 @@ func (h *TeamsHandler) PostTeam(c *gin.Context) {
 	c.JSON(http.StatusOK, resp)
 }
 
+func (h *TeamsHandler) PatchTeam(c *gin.Context) {
+	user := h.Auth.GetCurrentUser(c)
+	uuid := c.Param("uuid")
+
+	input := &dtos.TeamUpdateRequest{}
+	if err := c.ShouldBindJSON(input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	if err := input.Validate(); err != nil {
+		common.ReturnAPIError(c, http.StatusUnprocessableEntity, err.Error(), err)
+		return
+	}
+
+	team, err := h.Teams.GetUserTeam(user, uuid)
+	if err != nil {
+		common.ReturnAPIError(c, http.StatusNotFound, "team not found", err)
+		return
+	}
+
+	if !h.Auth.CanManageTeam(user, team) {
+		common.ReturnAPIError(c, http.StatusForbidden, "forbidden", nil)
+		return
+	}
+
+	team, err = h.Teams.UpdateTeam(user, uuid, input)
+	if err != nil {
+		common.ReturnError(c, err)
+		return
+	}
+
+	members, err := h.Teams.GetTeamMembers(team)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get team members"})
+		return
+	}
+
+	resp := dtos.NewTeamDetailsResponse(team, members)
+	c.JSON(http.StatusOK, resp)
+}
+
 func (h *TeamsHandler) PostTeamInvite(c *gin.Context) {
 	user := h.Auth.GetCurrentUser(c)
 	uuid := c.Param("uuid")
@@ -0,0 +1,116 @@
+package routers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hejmsdz/goslides/common"
+	"github.com/hejmsdz/goslides/di"
+	"github.com/hejmsdz/goslides/dtos"
+	"github.com/hejmsdz/goslides/services"
+)
+
+func RegisterThemeRoutes(r gin.IRouter, dic *di.Container) {
+	h := NewThemesHandler(dic)
+	auth := dic.Auth.AuthMiddleware
+
+	r.GET("/teams/:uuid/themes", auth, h.GetThemes)
+	r.POST("/teams/:uuid/themes", auth, h.PostTheme)
+	r.GET("/teams/:uuid/themes/:id", auth, h.GetTheme)
+	r.PUT("/teams/:uuid/themes/:id", auth, h.PutTheme)
+	r.DELETE("/teams/:uuid/themes/:id", auth, h.DeleteTheme)
+}
+
+type ThemesHandler struct {
+	Themes *services.ThemesService
+	Auth   *services.AuthService
+}
+
+func NewThemesHandler(dic *di.Container) *ThemesHandler {
+	return &ThemesHandler{dic.Themes, dic.Auth}
+}
+
+func (h *ThemesHandler) GetThemes(c *gin.Context) {
+	user := h.Auth.GetCurrentUser(c)
+
+	themes, err := h.Themes.GetThemes(user, c.Param("uuid"))
+	if err != nil {
+		common.ReturnError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.NewThemeListResponse(themes))
+}
+
+func (h *ThemesHandler) GetTheme(c *gin.Context) {
+	user := h.Auth.GetCurrentUser(c)
+
+	theme, err := h.Themes.GetTheme(user, c.Param("uuid"), c.Param("id"))
+	if err != nil {
+		common.ReturnError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.NewThemeResponse(theme))
+}
+
+func bindThemeRequest(c *gin.Context) (dtos.ThemeRequest, bool) {
+	var input dtos.ThemeRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		common.ReturnBadRequestError(c, err)
+		return input, false
+	}
+
+	if err := input.Validate(); err != nil {
+		common.ReturnAPIError(c, http.StatusUnprocessableEntity, err.Error(), err)
+		return input, false
+	}
+
+	return input, true
+}
+
+func (h *ThemesHandler) PostTheme(c *gin.Context) {
+	user := h.Auth.GetCurrentUser(c)
+
+	input, ok := bindThemeRequest(c)
+	if !ok {
+		return
+	}
+
+	theme, err := h.Themes.CreateTheme(user, c.Param("uuid"), input)
+	if err != nil {
+		common.ReturnError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dtos.NewThemeResponse(theme))
+}
+
+func (h *ThemesHandler) PutTheme(c *gin.Context) {
+	user := h.Auth.GetCurrentUser(c)
+
+	input, ok := bindThemeRequest(c)
+	if !ok {
+		return
+	}
+
+	theme, err := h.Themes.UpdateTheme(user, c.Param("uuid"), c.Param("id"), input)
+	if err != nil {
+		common.ReturnError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.NewThemeResponse(theme))
+}
+
+func (h *ThemesHandler) DeleteTheme(c *gin.Context) {
+	user := h.Auth.GetCurrentUser(c)
+
+	err := h.Themes.DeleteTheme(user, c.Param("uuid"), c.Param("id"))
+	if err != nil {
+		common.ReturnError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -242,6 +242,16 @@ func (s *AuthService) Can(user *models.User, action string, resource *models.Son
 	return false
 }
 
+// CanManageTeam tells whether the user can change the name and the images of a team,
+// which only its owner can do, besides the admins.
+func (s *AuthService) CanManageTeam(user *models.User, team *models.Team) bool {
+	if user == nil {
+		return false
+	}
+
+	return user.IsAdmin || team.CreatedByID == user.ID
+}
+
 func getBearerToken(c *gin.Context) (string, bool) {
 	authHeader := c.Request.Header.Get("Authorization")
 	return strings.CutPrefix(authHeader, "Bearer ")
@@ -1,22 +1,31 @@
 package services
 
 import (
+	"bytes"
 	"fmt"
+	"math"
+	"net/http"
 	"strconv"
 	"strings"
 
+	"github.com/hejmsdz/goslides/common"
 	"github.com/hejmsdz/goslides/core"
 	"github.com/hejmsdz/goslides/dtos"
+	"github.com/hejmsdz/goslides/fonts"
 	"github.com/hejmsdz/goslides/models"
 )
 
 type DeckService struct {
 	songs   *SongsService
 	liturgy *LiturgyService
+	teams   *TeamsService
+	images  *ImagesService
+	themes  *ThemesService
+	fonts   *FontsService
 }
 
-func NewDeckService(songs *SongsService, liturgy *LiturgyService) *DeckService {
-	return &DeckService{songs: songs, liturgy: liturgy}
+func NewDeckService(songs *SongsService, liturgy *LiturgyService, teams *TeamsService, images *ImagesService, themes *ThemesService, fonts *FontsService) *DeckService {
+	return &DeckService{songs: songs, liturgy: liturgy, teams: teams, images: images, themes: themes, fonts: fonts}
 }
 
 func parseColor(color string, defaultColor core.Color) core.Color {
@@ -35,39 +44,383 @@ func parseColor(color string, defaultColor core.Color) core.Color {
 	return core.Color{R: uint8(r), G: uint8(g), B: uint8(b)}
 }
 
-func (s *DeckService) GetPageConfig(d dtos.DeckRequest) core.PageConfig {
-	ratio := 16.0 / 9.0
-	fontSize := 52
+// interleaveTranslation pairs the verses of a song with the verses
+// of its translation by their index. The verses with an empty translation stay alone.
+func interleaveTranslation(lyrics []string, translation []string) []string {
+	result := make([]string, len(lyrics))
+	index := 0
+	for i, verse := range lyrics {
+		result[i] = verse
+		if strings.HasPrefix(verse, core.HintStartTag) {
+			continue
+		}
+
+		if index < len(translation) && translation[index] != "" {
+			result[i] = core.JoinTranslation(verse, translation[index])
+		}
+		index++
+	}
+
+	return result
+}
+
+func (s *DeckService) GetChordSheetOptions(d dtos.DeckRequest) core.ChordSheetOptions {
+	return core.ChordSheetOptions{
+		Transpose: d.Transpose,
+		Capo:      d.Capo,
+	}
+}
 
-	if d.Ratio == "4:3" {
-		ratio = 4.0 / 3.0
+// GetTugalProfile applies the options of the request to the default LED board.
+func (s *DeckService) GetTugalProfile(d dtos.DeckRequest) core.TugalProfile {
+	profile := core.DefaultTugalProfile
+	if d.Tugal == nil {
+		return profile
 	}
 
+	if d.Tugal.Rows > 0 {
+		profile.Rows = d.Tugal.Rows
+	}
+	if d.Tugal.Columns > 0 {
+		profile.Cols = d.Tugal.Columns
+	}
+	if d.Tugal.Header != nil {
+		profile.Header = *d.Tugal.Header
+	}
+	if d.Tugal.UpperCase != nil {
+		profile.UpperCase = *d.Tugal.UpperCase
+	}
+	if d.Tugal.Encoding != "" {
+		profile.Encoding = d.Tugal.Encoding
+	}
+	if d.Tugal.BorderBlank != nil {
+		profile.BorderBlank = *d.Tugal.BorderBlank
+	}
+
+	return profile
+}
+
+// GetSongbookConfig prints the songbook in the font of the deck.
+func (s *DeckService) GetSongbookConfig(d dtos.DeckRequest, pageConfig core.PageConfig) core.SongbookConfig {
+	paperSize := "A4"
+	fontSize := 12
+	if d.PaperSize == "A5" {
+		paperSize = "A5"
+		fontSize = 10
+	}
+
+	columns := 1
+	if d.Columns > 0 {
+		columns = d.Columns
+	}
+
+	return core.SongbookConfig{
+		PaperSize: paperSize,
+		Columns:   columns,
+		Booklet:   d.Booklet,
+		Font:      pageConfig.Font,
+		FontSize:  fontSize,
+	}
+}
+
+// pageShortSide is the length of the shorter side of a page in points,
+// when the deck has a ratio rather than the size of the screen.
+const pageShortSide = 432.0
+
+// the default font size and margin in proportion to the shorter side of the page
+const defaultFontSizeScale = 0.12
+const defaultMarginScale = 1.0 / 54
+
+// imageShortSide is the length in pixels of the shorter side of the slide images,
+// which makes them full HD by default.
+const imageShortSide = 1080.0
+
+// backgroundImageScale renders background images at twice the page size in points,
+// so that they stay sharp on full HD screens.
+const backgroundImageScale = 2
+
+const textShadowOpacity = 0.6
+
+// the translation is set smaller and dimmed towards the background
+const translationFontScale = 0.75
+const translationDimming = 0.35
+
+// the credits are set in a third of the font size, dimmed like the translation
+const creditsFontScale = 3
+
+// the hint overlaid on a verse is set in half of the font size,
+// and dimmed more than the translation
+const hintOverlayFontScale = 2
+const hintDimming = 0.5
+
+// responseIndentScale indents the response lines by one and a half of the font size.
+const responseIndentScale = 1.5
+
+// getImages resolves the background image and the logo of a deck,
+// falling back to the defaults of the deck's team.
+func (s *DeckService) getImages(d dtos.DeckRequest, user *models.User) (*models.Image, *models.Image, error) {
+	backgroundImageID := d.BackgroundImage
+	logoID := d.Logo
+
+	if d.TeamID != "" {
+		team, err := s.teams.GetUserTeam(user, d.TeamID)
+		if err != nil {
+			return nil, nil, common.NewAPIError(http.StatusNotFound, "team not found", err)
+		}
+
+		if backgroundImageID == "" && team.BackgroundImageUUID != nil {
+			backgroundImageID = team.BackgroundImageUUID.String()
+		}
+
+		if logoID == "" && team.LogoUUID != nil {
+			logoID = team.LogoUUID.String()
+		}
+	}
+
+	var backgroundImage, logo *models.Image
+	var err error
+
+	if backgroundImageID != "" {
+		backgroundImage, err = s.images.GetImage(backgroundImageID, user)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if logoID != "" {
+		logo, err = s.images.GetImage(logoID, user)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return backgroundImage, logo, nil
+}
+
+// GetImageOptions sizes the slide images to the deck's dimensions in pixels if it has them.
+func (s *DeckService) GetImageOptions(d dtos.DeckRequest, pageConfig core.PageConfig) core.ImageOptions {
+	width, height := d.Width, d.Height
+	if width == 0 || height == 0 {
+		scale := imageShortSide / min(pageConfig.PageWidth, pageConfig.PageHeight)
+		width = int(math.Round(pageConfig.PageWidth * scale))
+		height = int(math.Round(pageConfig.PageHeight * scale))
+	}
+
+	return core.ImageOptions{
+		Format: d.Format,
+		Width:  width,
+		Height: height,
+	}
+}
+
+// ApplyTheme fills the style options that the deck doesn't set with the ones from its theme.
+func (s *DeckService) ApplyTheme(d dtos.DeckRequest, user *models.User) (dtos.DeckRequest, error) {
+	if d.ThemeID == "" {
+		return d, nil
+	}
+
+	theme, err := s.themes.GetThemeStyle(user, d.ThemeID)
+	if err != nil {
+		return d, err
+	}
+
+	// the options are checked together, since the theme may not suit the ones of the deck
+	d.DeckStyle = d.DeckStyle.WithDefaults(theme)
+	if err := d.DeckStyle.Validate(); err != nil {
+		return d, common.NewAPIError(http.StatusUnprocessableEntity, err.Error(), err)
+	}
+
+	return d, nil
+}
+
+// pageSize makes a point of the page a pixel of the screen, when the deck has its size.
+// Otherwise the page has the same shorter side in every orientation, so that a font size
+// looks the same on landscape and portrait screens.
+func pageSize(d dtos.DeckRequest) (float64, float64) {
+	if d.Width > 0 && d.Height > 0 {
+		return float64(d.Width), float64(d.Height)
+	}
+
+	ratio := d.PageRatio()
+	if ratio < 1 {
+		return pageShortSide, pageShortSide / ratio
+	}
+
+	return pageShortSide * ratio, pageShortSide
+}
+
+// textPageConfig sets up everything but the images, which are shared by the items of a deck.
+func (s *DeckService) textPageConfig(d dtos.DeckRequest) core.PageConfig {
+	pageWidth, pageHeight := pageSize(d)
+	shortSide := min(pageWidth, pageHeight)
+	// the sizes of the style are given for a page of the default size, so that
+	// they look the same on the screens of any resolution
+	scale := shortSide / pageShortSide
+
+	fontSize := int(math.Round(shortSide * defaultFontSizeScale))
 	if d.FontSize > 0 {
-		fontSize = d.FontSize
+		fontSize = int(math.Round(float64(d.FontSize) * scale))
+	}
+
+	fitMode := core.FitSplit
+	if d.FitMode != "" {
+		fitMode = d.FitMode
+	}
+
+	lineBreak := core.LineBreakOptions{Mode: d.LineBreaking}
+	if d.Hyphenation {
+		lineBreak.Language = d.Language
+		if lineBreak.Language == "" {
+			lineBreak.Language = models.DefaultLanguage
+		}
+	}
+
+	minFontSize := fontSize * 2 / 3
+	if d.MinFontSize > 0 {
+		minFontSize = min(int(math.Round(float64(d.MinFontSize)*scale)), fontSize)
 	}
 
-	pageHeight := 432.0
-	pageWidth := pageHeight * ratio
+	margin := shortSide * defaultMarginScale
+	if d.Margin > 0 {
+		margin = d.Margin * scale
+	}
+
+	pageConfig := core.PageConfig{
+		PageWidth:           pageWidth,
+		PageHeight:          pageHeight,
+		Margin:              margin,
+		FontSize:            fontSize,
+		MinFontSize:         minFontSize,
+		FitMode:             fitMode,
+		HintFontSize:        fontSize * 2 / 3,
+		HintPlacement:       d.HintPlacement,
+		HintOverlayFontSize: fontSize / hintOverlayFontScale,
+		LineSpacing:         1.3,
+		LineBreak:           lineBreak,
+		VerticalAlign:       d.VerticalAlign,
+		HorizontalAlign:     d.HorizontalAlign,
+		TextColor:           parseColor(d.TextColor, core.Color{R: 255, G: 255, B: 255}),
+		ResponseColor:       parseColor(d.ResponseColor, core.Color{R: 255, G: 214, B: 102}),
+		BackgroundColor:     parseColor(d.BackgroundColor, core.Color{R: 0, G: 0, B: 0}),
+		TitleSlides:         d.TitleSlides,
+		Credits:             d.Credits,
+		CreditsFontSize:     fontSize / creditsFontScale,
+		CCLILicense:         d.CCLILicense,
+		DocumentTitle:       d.Date,
+	}
+
+	translationColor := pageConfig.TextColor.Mix(pageConfig.BackgroundColor, translationDimming)
+	pageConfig.CreditsColor = translationColor
+	pageConfig.HintColor = pageConfig.TextColor.Mix(pageConfig.BackgroundColor, hintDimming)
+	pageConfig.Translation = core.TranslationOptions{
+		Layout:    d.TranslationLayout,
+		FontScale: translationFontScale,
+		Color:     parseColor(d.TranslationColor, translationColor),
+	}
 
-	return core.PageConfig{
-		PageWidth:       pageWidth,
-		PageHeight:      pageHeight,
-		Margin:          8,
-		FontSize:        fontSize,
-		HintFontSize:    fontSize * 2 / 3,
-		LineSpacing:     1.3,
-		Font:            "./fonts/source-sans-pro.ttf",
-		VerticalAlign:   d.VerticalAlign,
-		TextColor:       parseColor(d.TextColor, core.Color{R: 255, G: 255, B: 255}),
-		BackgroundColor: parseColor(d.BackgroundColor, core.Color{R: 0, G: 0, B: 0}),
+	// centered and right-aligned responses only stand out by their color
+	if d.HorizontalAlign == core.AlignLeft || d.HorizontalAlign == core.AlignJustify {
+		pageConfig.ResponseIndent = float64(fontSize) * responseIndentScale
 	}
+
+	if d.TextOutlineWidth > 0 {
+		pageConfig.TextOutline = &core.TextOutline{
+			Color: parseColor(d.TextOutlineColor, core.Color{R: 0, G: 0, B: 0}),
+			Width: d.TextOutlineWidth * scale,
+		}
+	}
+
+	if d.TextShadowColor != "" {
+		pageConfig.TextShadow = &core.TextShadow{
+			Color:   parseColor(d.TextShadowColor, core.Color{R: 0, G: 0, B: 0}),
+			Offset:  d.TextShadowOffset * scale,
+			Blur:    d.TextShadowBlur * scale,
+			Opacity: textShadowOpacity,
+		}
+	}
+
+	return pageConfig
+}
+
+// itemPageConfig applies the style overrides of an item to the deck's options.
+// Items that don't override anything use the deck's page config.
+func (s *DeckService) itemPageConfig(d dtos.DeckRequest, item dtos.DeckItem) *core.PageConfig {
+	if item.FontSize == 0 && item.TextColor == "" && item.BackgroundColor == "" && item.VerticalAlign == "" {
+		return nil
+	}
+
+	if item.FontSize > 0 {
+		d.FontSize = item.FontSize
+	}
+	if item.TextColor != "" {
+		d.TextColor = item.TextColor
+	}
+	if item.BackgroundColor != "" {
+		d.BackgroundColor = item.BackgroundColor
+	}
+	if item.VerticalAlign != "" {
+		d.VerticalAlign = item.VerticalAlign
+	}
+
+	pageConfig := s.textPageConfig(d)
+	return &pageConfig
+}
+
+func (s *DeckService) GetPageConfig(d dtos.DeckRequest, user *models.User) (core.PageConfig, error) {
+	pageConfig := s.textPageConfig(d)
+
+	fontID := fonts.Default
+	if d.Font != "" {
+		fontID = d.Font
+	}
+
+	font, err := s.fonts.GetFont(fontID, user)
+	if err != nil {
+		return pageConfig, err
+	}
+
+	pageConfig.Font = font.Regular
+	pageConfig.BoldFont = font.Bold
+	pageConfig.ItalicFont = font.Italic
+	pageConfig.FontFamily = font.Family
+
+	backgroundImage, logo, err := s.getImages(d, user)
+	if err != nil {
+		return pageConfig, err
+	}
+
+	if backgroundImage != nil {
+		// screens of a known size get an image of their resolution
+		imageWidth := int(pageConfig.PageWidth * backgroundImageScale)
+		imageHeight := int(pageConfig.PageHeight * backgroundImageScale)
+		if d.Width > 0 && d.Height > 0 {
+			imageWidth, imageHeight = d.Width, d.Height
+		}
+
+		pageConfig.BackgroundImage, err = core.PrepareBackgroundImage(
+			backgroundImage.Data,
+			imageWidth,
+			imageHeight,
+			d.BackgroundFit,
+			d.BackgroundOverlay,
+			pageConfig.BackgroundColor,
+		)
+		if err != nil {
+			return pageConfig, common.NewAPIError(http.StatusUnprocessableEntity, "failed to process the background image", err)
+		}
+	}
+
+	if logo != nil {
+		pageConfig.Logo = logo.Data
+	}
+
+	return pageConfig, nil
 }
 
 const PSALM = "PSALM"
 const ACCLAMATION = "ACCLAMATION"
 
-func (s *DeckService) BuildTextSlides(d dtos.DeckRequest, user *models.User) ([][]string, bool) {
+func (s *DeckService) BuildTextSlides(d dtos.DeckRequest, user *models.User) ([][]string, []core.ItemInfo, bool) {
 	hasLiturgy := false
 	for _, item := range d.Items {
 		if item.Type == PSALM || item.Type == ACCLAMATION {
@@ -82,14 +435,41 @@ func (s *DeckService) BuildTextSlides(d dtos.DeckRequest, user *models.User) ([]
 	}
 
 	slides := make([][]string, 0)
+	items := make([]core.ItemInfo, 0)
 	for _, item := range d.Items {
+		numItems := len(items)
 		if item.ID != "" {
 			song, err := s.songs.GetSong(item.ID, user)
 			if err != nil {
-				return slides, false
+				return slides, items, false
+			}
+			lyrics := song.FormatLyrics(models.FormatLyricsOptions{
+				Order:       item.Order,
+				Hints:       d.Hints,
+				HintContent: d.HintContent,
+				Chords:      d.Format == "chords",
+			})
+			translationLanguage := ""
+			if item.SecondaryLanguage != "" {
+				// a song without the translation is shown in one language
+				if translation, err := s.songs.GetTranslation(song, item.SecondaryLanguage, user); err == nil {
+					lyrics = interleaveTranslation(lyrics, translation.FormatTranslation(*song, models.FormatLyricsOptions{
+						Order:  item.Order,
+						Chords: d.Format == "chords",
+					}))
+					translationLanguage = translation.Language
+				}
 			}
-			lyrics := song.FormatLyrics(models.FormatLyricsOptions{Order: item.Order, Hints: d.Hints})
 			slides = append(slides, lyrics)
+			items = append(items, core.ItemInfo{
+				Title:               song.Title,
+				Subtitle:            song.Subtitle.String,
+				Author:              song.Author.String,
+				Copyright:           song.Copyright.String,
+				CCLINumber:          song.CCLINumber.String,
+				Language:            song.Language,
+				TranslationLanguage: translationLanguage,
+			})
 		} else if item.Type == PSALM && liturgyOk {
 			alleluiaticSuffix := ", albo: Alleluja"
 			isAlleluiatic := strings.HasSuffix(liturgy.Psalm, alleluiaticSuffix)
@@ -99,16 +479,76 @@ func (s *DeckService) BuildTextSlides(d dtos.DeckRequest, user *models.User) ([]
 			} else {
 				slides = append(slides, []string{liturgy.Psalm})
 			}
+			items = append(items, core.ItemInfo{Title: "Psalm responsoryjny"})
 		} else if item.Type == ACCLAMATION && liturgyOk {
 			fullAcclamation := fmt.Sprintf("%s\n\n%s\n\n%s",
 				liturgy.Acclamation,
 				liturgy.AcclamationVerse,
 				liturgy.Acclamation)
 			slides = append(slides, []string{fullAcclamation})
+			items = append(items, core.ItemInfo{Title: "Aklamacja przed Ewangelią"})
 		} else if len(item.Contents) > 0 {
 			slides = append(slides, item.Contents)
+			items = append(items, core.ItemInfo{})
 		}
+
+		if len(items) > numItems {
+			items[numItems].PageConfig = s.itemPageConfig(d, item)
+		}
+	}
+
+	return slides, items, true
+}
+
+// PreviewSong draws the slides of a saved song, or of one of its verses, in the given style.
+func (s *DeckService) PreviewSong(id string, p dtos.PreviewRequest, user *models.User) (*bytes.Buffer, error) {
+	song, err := s.songs.GetSong(id, user)
+	if err != nil {
+		return nil, common.NewAPIError(http.StatusNotFound, "song not found", err)
+	}
+
+	return s.preview(*song, p, user)
+}
+
+// PreviewLyrics draws the slides of lyrics that aren't saved yet, like a song in the editor.
+func (s *DeckService) PreviewLyrics(p dtos.PreviewRequest, user *models.User) (*bytes.Buffer, error) {
+	song := models.Song{Lyrics: strings.ReplaceAll(strings.Join(p.Lyrics, "\n\n"), "\r\n", "\n")}
+	return s.preview(song, p, user)
+}
+
+func (s *DeckService) preview(song models.Song, p dtos.PreviewRequest, user *models.User) (*bytes.Buffer, error) {
+	options := models.FormatLyricsOptions{}
+	if p.Verse != nil {
+		options.Order = []int{*p.Verse}
+	}
+
+	lyrics := song.FormatLyrics(options)
+	if len(lyrics) == 0 {
+		return nil, common.NewAPIError(http.StatusNotFound, "verse not found", nil)
+	}
+
+	deck, err := s.ApplyTheme(dtos.DeckRequest{TeamID: p.TeamID, ThemeID: p.ThemeID, DeckStyle: p.DeckStyle}, user)
+	if err != nil {
+		return nil, err
+	}
+
+	pageConfig, err := s.GetPageConfig(deck, user)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []core.ItemInfo{{
+		Title:      song.Title,
+		Subtitle:   song.Subtitle.String,
+		Author:     song.Author.String,
+		Copyright:  song.Copyright.String,
+		CCLINumber: song.CCLINumber.String,
+	}}
+
+	buf, _, err := core.BuildSVG([][]string{lyrics}, items, pageConfig)
+	if err != nil {
+		return nil, common.NewAPIError(http.StatusInternalServerError, "failed to draw the preview", err)
 	}
 
-	return slides, true
+	return buf, nil
 }
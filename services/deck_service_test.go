@@ -0,0 +1,103 @@
+package services_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hejmsdz/goslides/dtos"
+	"github.com/hejmsdz/goslides/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetPageConfigSize(t *testing.T) {
+	te := tests.NewTestEnvironment(t)
+
+	te.Run("page sizes keep the shorter side", func(t *testing.T, tce *tests.TestCaseEnvironment) {
+		testCases := []struct {
+			style  dtos.DeckStyle
+			width  float64
+			height float64
+		}{
+			{dtos.DeckStyle{}, 768, 432},
+			{dtos.DeckStyle{Ratio: "4:3"}, 576, 432},
+			{dtos.DeckStyle{Ratio: "21:9"}, 1008, 432},
+			{dtos.DeckStyle{Ratio: "9:16"}, 432, 768},
+		}
+
+		for _, tc := range testCases {
+			pageConfig, err := tce.Container.Deck.GetPageConfig(dtos.DeckRequest{DeckStyle: tc.style}, nil)
+			assert.NoError(t, err)
+			assert.InDelta(t, tc.width, pageConfig.PageWidth, 0.001)
+			assert.InDelta(t, tc.height, pageConfig.PageHeight, 0.001)
+			assert.Equal(t, 52, pageConfig.FontSize)
+			assert.InDelta(t, 8, pageConfig.Margin, 0.001)
+		}
+	})
+
+	te.Run("pages of the screen size scale the defaults and the style", func(t *testing.T, tce *tests.TestCaseEnvironment) {
+		testCases := []struct {
+			style    dtos.DeckStyle
+			width    float64
+			height   float64
+			fontSize int
+			margin   float64
+		}{
+			{dtos.DeckStyle{Width: 1920, Height: 1080}, 1920, 1080, 130, 20},
+			{dtos.DeckStyle{Ratio: "4:3", Width: 512, Height: 256}, 512, 256, 31, 256.0 / 54},
+			{dtos.DeckStyle{Width: 1080, Height: 1920, FontSize: 40, Margin: 16}, 1080, 1920, 100, 40},
+		}
+
+		for _, tc := range testCases {
+			pageConfig, err := tce.Container.Deck.GetPageConfig(dtos.DeckRequest{DeckStyle: tc.style}, nil)
+			assert.NoError(t, err)
+			assert.InDelta(t, tc.width, pageConfig.PageWidth, 0.001)
+			assert.InDelta(t, tc.height, pageConfig.PageHeight, 0.001)
+			assert.Equal(t, tc.fontSize, pageConfig.FontSize)
+			assert.InDelta(t, tc.margin, pageConfig.Margin, 0.001)
+		}
+	})
+}
+
+func TestDeckStyleWithDefaults(t *testing.T) {
+	theme := dtos.DeckStyle{Width: 1920, Height: 1080, FontSize: 48}
+
+	style := dtos.DeckStyle{Ratio: "4:3"}.WithDefaults(theme)
+	assert.Equal(t, 0, style.Width)
+	assert.Equal(t, 0, style.Height)
+	assert.InDelta(t, 4.0/3, style.PageRatio(), 0.001)
+	assert.Equal(t, 48, style.FontSize)
+
+	style = dtos.DeckStyle{}.WithDefaults(theme)
+	assert.Equal(t, 1920, style.Width)
+	assert.Equal(t, 1080, style.Height)
+
+	style = dtos.DeckStyle{MinFontSize: 52}.WithDefaults(theme)
+	assert.Equal(t, 48, style.FontSize)
+	assert.Error(t, style.Validate())
+}
+
+func TestPreviewLyrics(t *testing.T) {
+	te := tests.NewTestEnvironment(t)
+
+	lyrics := []string{"Pan kiedyś stanął nad brzegiem", "O Panie, to Ty na mnie spojrzałeś"}
+
+	te.Run("draws every verse", func(t *testing.T, tce *tests.TestCaseEnvironment) {
+		svg, err := tce.Container.Deck.PreviewLyrics(dtos.PreviewRequest{Lyrics: lyrics}, nil)
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(svg.String(), "<svg "))
+		assert.Equal(t, 2, strings.Count(svg.String(), "<rect "))
+	})
+
+	te.Run("draws one verse", func(t *testing.T, tce *tests.TestCaseEnvironment) {
+		verse := 1
+		svg, err := tce.Container.Deck.PreviewLyrics(dtos.PreviewRequest{Lyrics: lyrics, Verse: &verse}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, strings.Count(svg.String(), "<rect "))
+	})
+
+	te.Run("fails for a missing verse", func(t *testing.T, tce *tests.TestCaseEnvironment) {
+		verse := 2
+		_, err := tce.Container.Deck.PreviewLyrics(dtos.PreviewRequest{Lyrics: lyrics, Verse: &verse}, nil)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/hejmsdz/goslides/common"
+	"github.com/hejmsdz/goslides/core"
+	"github.com/hejmsdz/goslides/fonts"
+	"github.com/hejmsdz/goslides/models"
+	"gorm.io/gorm"
+)
+
+const MaxFontSize = 10 << 20
+
+type FontsService struct {
+	db    *gorm.DB
+	auth  *AuthService
+	teams *TeamsService
+}
+
+func NewFontsService(db *gorm.DB, auth *AuthService, teams *TeamsService) *FontsService {
+	return &FontsService{db, auth, teams}
+}
+
+func checkFontFile(data []byte, style string) (string, error) {
+	if len(data) > MaxFontSize {
+		return "", common.NewAPIError(http.StatusRequestEntityTooLarge, "the "+style+" font is too large", nil)
+	}
+
+	family, err := core.CheckFont(data)
+	if err != nil {
+		return "", common.NewAPIError(http.StatusUnprocessableEntity, "invalid "+style+" font: "+err.Error(), err)
+	}
+
+	return family, nil
+}
+
+// UploadFont adds a font to the team. Only TrueType fonts are accepted. The bold
+// and italic styles are optional, emphasized text is set in the regular one without them.
+func (s *FontsService) UploadFont(user *models.User, teamUUID string, regular []byte, bold []byte, italic []byte) (*models.Font, error) {
+	if user == nil {
+		return nil, errors.New("user is nil")
+	}
+
+	team, err := s.teams.GetUserTeam(user, teamUUID)
+	if err != nil {
+		return nil, common.NewAPIError(http.StatusNotFound, "team not found", err)
+	}
+
+	family, err := checkFontFile(regular, "regular")
+	if err != nil {
+		return nil, err
+	}
+
+	for style, data := range map[string][]byte{"bold": bold, "italic": italic} {
+		if len(data) == 0 {
+			continue
+		}
+
+		if _, err := checkFontFile(data, style); err != nil {
+			return nil, err
+		}
+	}
+
+	font := &models.Font{
+		TeamID:      team.ID,
+		Team:        team,
+		Family:      family,
+		Data:        regular,
+		BoldData:    bold,
+		ItalicData:  italic,
+		CreatedByID: user.ID,
+	}
+
+	err = s.db.Create(font).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return font, nil
+}
+
+func (s *FontsService) GetTeamFonts(user *models.User, teamUUID string) ([]*models.Font, error) {
+	team, err := s.teams.GetUserTeam(user, teamUUID)
+	if err != nil {
+		return nil, common.NewAPIError(http.StatusNotFound, "team not found", err)
+	}
+
+	var teamFonts []*models.Font
+	err = s.db.Omit("data", "bold_data", "italic_data").
+		Where("team_id = ?", team.ID).
+		Order("family").
+		Find(&teamFonts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return teamFonts, nil
+}
+
+// GetFont loads a bundled font by its ID or one of the user's teams' fonts by its UUID.
+func (s *FontsService) GetFont(id string, user *models.User) (*fonts.Font, error) {
+	if font, ok := fonts.Bundled(id); ok {
+		return font, nil
+	}
+
+	uuid, err := uuid.Parse(id)
+	if err != nil {
+		return nil, common.NewAPIError(http.StatusUnprocessableEntity, "font not found", err)
+	}
+
+	var font models.Font
+	err = s.db.Where("uuid = ?", uuid).Take(&font).Error
+	if err != nil {
+		return nil, common.NewAPIError(http.StatusUnprocessableEntity, "font not found", err)
+	}
+
+	if !s.auth.UserBelongsToTeam(user, font.TeamID) {
+		return nil, common.NewAPIError(http.StatusForbidden, "forbidden", nil)
+	}
+
+	return &fonts.Font{
+		Family:  font.Family,
+		Regular: font.Data,
+		Bold:    font.BoldData,
+		Italic:  font.ItalicData,
+	}, nil
+}
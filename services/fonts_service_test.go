@@ -0,0 +1,49 @@
+package services_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/hejmsdz/goslides/fonts"
+	"github.com/hejmsdz/goslides/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFonts(t *testing.T) {
+	te := tests.NewTestEnvironment(t)
+
+	data, err := os.ReadFile("../fonts/dejavu-sans.ttf")
+	assert.NoError(t, err)
+
+	te.Run("upload a font", func(t *testing.T, tce *tests.TestCaseEnvironment) {
+		user, team := tests.CreateUserWithTeam(t, tce.DB, "test@example.com")
+
+		font, err := tce.Container.Fonts.UploadFont(user, team.UUID.String(), data, nil, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "DejaVu Sans", font.Family)
+
+		teamFonts, err := tce.Container.Fonts.GetTeamFonts(user, team.UUID.String())
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(teamFonts))
+
+		loaded, err := tce.Container.Fonts.GetFont(font.UUID.String(), user)
+		assert.NoError(t, err)
+		assert.Equal(t, data, loaded.Regular)
+	})
+
+	te.Run("reject invalid fonts", func(t *testing.T, tce *tests.TestCaseEnvironment) {
+		user, team := tests.CreateUserWithTeam(t, tce.DB, "test@example.com")
+
+		_, err := tce.Container.Fonts.UploadFont(user, team.UUID.String(), []byte("not a font"), nil, nil)
+		assert.Error(t, err)
+
+		_, err = tce.Container.Fonts.UploadFont(user, team.UUID.String(), data, []byte("not a font"), nil)
+		assert.Error(t, err)
+	})
+
+	te.Run("load a bundled font", func(t *testing.T, tce *tests.TestCaseEnvironment) {
+		font, err := tce.Container.Fonts.GetFont(fonts.Default, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "Source Sans Pro", font.Family)
+	})
+}
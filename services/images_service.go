@@ -0,0 +1,89 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/hejmsdz/goslides/common"
+	"github.com/hejmsdz/goslides/core"
+	"github.com/hejmsdz/goslides/models"
+	"gorm.io/gorm"
+)
+
+const MaxImageSize = 10 << 20
+const maxImageDimension = 8000
+
+type ImagesService struct {
+	db    *gorm.DB
+	auth  *AuthService
+	teams *TeamsService
+}
+
+func NewImagesService(db *gorm.DB, auth *AuthService, teams *TeamsService) *ImagesService {
+	return &ImagesService{db, auth, teams}
+}
+
+func (s *ImagesService) UploadImage(user *models.User, teamUUID string, data []byte) (*models.Image, error) {
+	if user == nil {
+		return nil, errors.New("user is nil")
+	}
+
+	team, err := s.teams.GetUserTeam(user, teamUUID)
+	if err != nil {
+		return nil, common.NewAPIError(http.StatusNotFound, "team not found", err)
+	}
+
+	if len(data) > MaxImageSize {
+		return nil, common.NewAPIError(http.StatusRequestEntityTooLarge, "image is too large", nil)
+	}
+
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || (format != "jpeg" && format != "png") {
+		return nil, common.NewAPIError(http.StatusUnprocessableEntity, "unsupported image format, use JPEG or PNG", err)
+	}
+
+	if config.Width > maxImageDimension || config.Height > maxImageDimension || config.Width*config.Height > core.MaxImagePixels {
+		return nil, common.NewAPIError(http.StatusUnprocessableEntity, "image dimensions are too large", nil)
+	}
+
+	image := &models.Image{
+		TeamID:      team.ID,
+		Team:        team,
+		ContentType: "image/" + format,
+		Width:       config.Width,
+		Height:      config.Height,
+		Data:        data,
+		CreatedByID: user.ID,
+	}
+
+	err = s.db.Create(image).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return image, nil
+}
+
+func (s *ImagesService) GetImage(uuidString string, user *models.User) (*models.Image, error) {
+	uuid, err := uuid.Parse(uuidString)
+	if err != nil {
+		return nil, common.NewAPIError(http.StatusBadRequest, "invalid image id", err)
+	}
+
+	var image models.Image
+	err = s.db.Where("uuid = ?", uuid).Take(&image).Error
+	if err != nil {
+		return nil, common.NewAPIError(http.StatusNotFound, "image not found", err)
+	}
+
+	if !s.auth.UserBelongsToTeam(user, image.TeamID) {
+		return nil, common.NewAPIError(http.StatusForbidden, "forbidden", nil)
+	}
+
+	return &image, nil
+}
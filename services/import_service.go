@@ -0,0 +1,375 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/hejmsdz/goslides/common"
+	"github.com/hejmsdz/goslides/core"
+	"github.com/hejmsdz/goslides/dtos"
+	"github.com/hejmsdz/goslides/models"
+	"gorm.io/gorm"
+)
+
+const MaxImportSize = 5 << 20
+
+type ImportService struct {
+	db    *gorm.DB
+	songs *SongsService
+	teams *TeamsService
+}
+
+func NewImportService(db *gorm.DB, songs *SongsService, teams *TeamsService) *ImportService {
+	return &ImportService{db, songs, teams}
+}
+
+// ImportTugal creates the songs found in a file of LED board pages in the team.
+// In a dry run, the songs are returned without saving them, to be reviewed first.
+func (s *ImportService) ImportTugal(user *models.User, teamUUID string, data []byte, charset string, dryRun bool) ([]models.Song, error) {
+	if user == nil {
+		return nil, errors.New("user is nil")
+	}
+
+	team, err := s.teams.GetUserTeam(user, teamUUID)
+	if err != nil {
+		return nil, common.NewAPIError(http.StatusNotFound, "team not found", err)
+	}
+
+	if len(data) > MaxImportSize {
+		return nil, common.NewAPIError(http.StatusRequestEntityTooLarge, "file is too large", nil)
+	}
+
+	if charset == "" {
+		charset = core.TugalCP1250
+	} else if charset != core.TugalCP1250 && charset != core.TugalISO88592 {
+		return nil, common.NewAPIError(http.StatusBadRequest, "invalid encoding", nil)
+	}
+
+	tugalSongs, err := core.ParseTugal(data, charset)
+	if err != nil {
+		return nil, common.NewAPIError(http.StatusUnprocessableEntity, "failed to read the file", err)
+	}
+
+	if len(tugalSongs) == 0 {
+		return nil, common.NewAPIError(http.StatusUnprocessableEntity, "no songs found in the file", nil)
+	}
+
+	// the board loses the formatting, so the songs are saved as drafts to be reviewed
+	imports := make([]songImport, len(tugalSongs))
+	for i, tugalSong := range tugalSongs {
+		imports[i].input = dtos.SongRequest{
+			Title:   tugalSong.Title,
+			Lyrics:  tugalSong.Verses,
+			TeamID:  teamUUID,
+			IsDraft: true,
+		}
+
+		if tugalSong.Title == "" {
+			return nil, common.NewAPIError(http.StatusUnprocessableEntity, fmt.Sprintf("song %d has no title", i+1), nil)
+		}
+
+		if err := imports[i].input.Validate(); err != nil {
+			return nil, common.NewAPIError(http.StatusUnprocessableEntity, fmt.Sprintf("invalid song %d", i+1), err)
+		}
+	}
+
+	if dryRun {
+		songs := make([]models.Song, len(imports))
+		for i, item := range imports {
+			songs[i] = models.Song{Title: item.input.Title, Lyrics: strings.Join(item.input.Lyrics, "\n\n"), IsDraft: true}
+		}
+
+		return songs, nil
+	}
+
+	return s.createSongs(imports, team, user)
+}
+
+// songImport is a song to be created, with the links it had where it was exported from.
+type songImport struct {
+	input            dtos.SongRequest
+	id               string
+	overriddenSongID string
+}
+
+// existingSong finds the song an imported one was exported from, when it's imported
+// back into the same team, so that restoring a backup updates the songs rather than
+// copying them. The overrides are found by the song they override.
+func existingSong(tx *gorm.DB, item songImport, teamID uint) (*models.Song, error) {
+	var song models.Song
+
+	if id, err := uuid.Parse(item.id); err == nil {
+		err := tx.Where("team_id = ? AND uuid = ?", teamID, id).Take(&song).Error
+		if err == nil {
+			return &song, nil
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+	}
+
+	if id, err := uuid.Parse(item.overriddenSongID); err == nil {
+		err := tx.Joins("INNER JOIN songs AS overridden ON overridden.id = songs.overridden_song_id").
+			Where("songs.team_id = ? AND overridden.uuid = ?", teamID, id).
+			Take(&song).Error
+		if err == nil {
+			return &song, nil
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+	}
+
+	return nil, nil
+}
+
+// createSongs saves either all the songs or none, so that the file can be sent again.
+// The originals are saved before the translations, to link them to the new songs.
+func (s *ImportService) createSongs(imports []songImport, team *models.Team, user *models.User) ([]models.Song, error) {
+	sort.SliceStable(imports, func(i, j int) bool {
+		return imports[i].input.TranslationOfID == "" && imports[j].input.TranslationOfID != ""
+	})
+
+	songs := make([]models.Song, 0, len(imports))
+	newIDs := make(map[string]string)
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		songsService := NewSongsService(tx, s.songs.auth, s.songs.teams)
+
+		for _, item := range imports {
+			input := item.input
+			if newID, ok := newIDs[input.TranslationOfID]; ok {
+				input.TranslationOfID = newID
+			}
+
+			existing, err := existingSong(tx, item, team.ID)
+			if err != nil {
+				return common.NewAPIError(http.StatusInternalServerError, "failed to find the song", err)
+			}
+
+			var song *models.Song
+			if existing != nil {
+				song, err = songsService.UpdateSong(existing.UUID.String(), input, user)
+			} else if item.overriddenSongID != "" {
+				song, err = songsService.OverrideSong(item.overriddenSongID, input, user)
+			} else {
+				song, err = songsService.CreateSong(input, user)
+			}
+			if err != nil {
+				return err
+			}
+
+			if item.id != "" {
+				newIDs[item.id] = song.UUID.String()
+			}
+			songs = append(songs, *song)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return songs, nil
+}
+
+type importFile struct {
+	name string
+	data []byte
+}
+
+// zipFiles reads the XML and JSON files of a ZIP archive, checking their size
+// as they are unpacked, since the sizes in the archive can't be trusted.
+func zipFiles(data []byte) ([]importFile, error) {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, common.NewAPIError(http.StatusUnprocessableEntity, "failed to read the archive", err)
+	}
+
+	files := make([]importFile, 0)
+	totalSize := 0
+	for _, file := range reader.File {
+		name := path.Base(file.Name)
+		if file.FileInfo().IsDir() || strings.HasPrefix(file.Name, "__MACOSX/") || strings.HasPrefix(name, ".") ||
+			(!strings.EqualFold(path.Ext(name), ".xml") && !strings.EqualFold(path.Ext(name), ".json")) {
+			continue
+		}
+
+		rc, err := file.Open()
+		if err != nil {
+			return nil, common.NewAPIError(http.StatusUnprocessableEntity, fmt.Sprintf("failed to read %s", name), err)
+		}
+		fileData, err := io.ReadAll(io.LimitReader(rc, int64(MaxImportSize-totalSize+1)))
+		rc.Close()
+		if err != nil {
+			return nil, common.NewAPIError(http.StatusUnprocessableEntity, fmt.Sprintf("failed to read %s", name), err)
+		}
+
+		totalSize += len(fileData)
+		if totalSize > MaxImportSize {
+			return nil, common.NewAPIError(http.StatusRequestEntityTooLarge, "archive is too large", nil)
+		}
+
+		files = append(files, importFile{name, fileData})
+	}
+
+	return files, nil
+}
+
+var openLyricsVerseNameRegexp = regexp.MustCompile(`^\w+$`)
+var openLyricsLanguageRegexp = regexp.MustCompile(`^([a-z]{2})(?:[-_]|$)`)
+
+// openLyricsSongImport turns the verse names into the named verses of the lyrics.
+func openLyricsSongImport(data []byte, teamUUID string) (*songImport, error) {
+	song, err := core.ParseOpenLyrics(data)
+	if err != nil {
+		return nil, err
+	}
+
+	usedNames := make(map[string]bool)
+	for _, verse := range song.Verses {
+		usedNames[verse.Name] = true
+	}
+
+	// the names like "c" would be read as chords, so they are numbered like "c1"
+	names := make(map[string]string)
+	for name := range usedNames {
+		if !core.IsChord("[" + name + "]") {
+			continue
+		}
+
+		newName := name
+		for number := 1; newName == name || usedNames[newName]; number++ {
+			newName = fmt.Sprintf("%s%d", name, number)
+		}
+		usedNames[newName] = true
+		names[name] = newName
+	}
+
+	lyrics := make([]string, 0, len(song.Verses))
+	for _, verse := range song.Verses {
+		name := verse.Name
+		if newName, ok := names[name]; ok {
+			name = newName
+		}
+
+		if openLyricsVerseNameRegexp.MatchString(name) {
+			lyrics = append(lyrics, "["+name+"] "+verse.Text)
+		} else {
+			lyrics = append(lyrics, verse.Text)
+		}
+	}
+
+	verseOrder := strings.Fields(song.VerseOrder)
+	for i, name := range verseOrder {
+		if newName, ok := names[name]; ok {
+			verseOrder[i] = newName
+		}
+	}
+
+	language := ""
+	if match := openLyricsLanguageRegexp.FindStringSubmatch(strings.ToLower(song.Language)); match != nil {
+		language = match[1]
+	}
+
+	return &songImport{input: dtos.SongRequest{
+		Title:          song.Title,
+		Subtitle:       song.Subtitle,
+		Lyrics:         lyrics,
+		VerseOrder:     strings.Join(verseOrder, " "),
+		Author:         song.Author,
+		Copyright:      song.Copyright,
+		CCLINumber:     song.CCLINumber,
+		SongbookNumber: song.SongbookNumber,
+		TeamID:         teamUUID,
+		Language:       language,
+	}}, nil
+}
+
+// backupSongImport reads a song from the JSON export of a team's songs. The songs
+// that belong to no team are the official ones, so they are left as they are.
+func backupSongImport(data []byte, teamUUID string) (*songImport, error) {
+	var backup dtos.SongBackup
+	if err := json.Unmarshal(data, &backup); err != nil {
+		return nil, err
+	}
+
+	if backup.Title == "" {
+		return nil, errors.New("song has no title")
+	}
+
+	if backup.TeamID == nil {
+		return nil, nil
+	}
+
+	imported := &songImport{input: backup.SongRequest(teamUUID), id: backup.ID}
+	if backup.OverriddenSongID != nil {
+		imported.overriddenSongID = *backup.OverriddenSongID
+	}
+
+	return imported, nil
+}
+
+// ImportSongs creates the songs of an OpenLyrics file, a JSON file from the export
+// of a team's songs, or a ZIP archive of them, in the team.
+func (s *ImportService) ImportSongs(user *models.User, teamUUID string, data []byte) ([]models.Song, error) {
+	if user == nil {
+		return nil, errors.New("user is nil")
+	}
+
+	team, err := s.teams.GetUserTeam(user, teamUUID)
+	if err != nil {
+		return nil, common.NewAPIError(http.StatusNotFound, "team not found", err)
+	}
+
+	if len(data) > MaxImportSize {
+		return nil, common.NewAPIError(http.StatusRequestEntityTooLarge, "file is too large", nil)
+	}
+
+	files := []importFile{{"the file", data}}
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		var err error
+		if files, err = zipFiles(data); err != nil {
+			return nil, err
+		}
+	}
+
+	imports := make([]songImport, 0, len(files))
+	for _, file := range files {
+		var imported *songImport
+		var err error
+
+		if bytes.HasPrefix(bytes.TrimSpace(file.data), []byte("{")) {
+			imported, err = backupSongImport(file.data, teamUUID)
+		} else {
+			imported, err = openLyricsSongImport(file.data, teamUUID)
+		}
+		if err != nil {
+			return nil, common.NewAPIError(http.StatusUnprocessableEntity, fmt.Sprintf("failed to read %s", file.name), err)
+		}
+
+		if imported == nil {
+			continue
+		}
+
+		if err := imported.input.Validate(); err != nil {
+			return nil, common.NewAPIError(http.StatusUnprocessableEntity, fmt.Sprintf("invalid song in %s", file.name), err)
+		}
+
+		imports = append(imports, *imported)
+	}
+
+	if len(imports) == 0 {
+		return nil, common.NewAPIError(http.StatusUnprocessableEntity, "no songs found in the file", nil)
+	}
+
+	return s.createSongs(imports, team, user)
+}
@@ -0,0 +1,142 @@
+package services_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/hejmsdz/goslides/core"
+	"github.com/hejmsdz/goslides/models"
+	"github.com/hejmsdz/goslides/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportTugal(t *testing.T) {
+	te := tests.NewTestEnvironment(t)
+
+	textDeck := [][]string{
+		{"Pan kiedyś stanął nad brzegiem", "O Panie, to Ty na mnie spojrzałeś"},
+		{"Zróbcie Mu miejsce"},
+	}
+	data, err := core.Tugalize(textDeck, core.DefaultTugalProfile)
+	assert.NoError(t, err)
+
+	te.Run("returns the songs without saving them in a dry run", func(t *testing.T, tce *tests.TestCaseEnvironment) {
+		user, team := tests.CreateUserWithTeam(t, tce.DB, "test@example.com")
+
+		songs, err := tce.Container.Import.ImportTugal(user, team.UUID.String(), data, "", true)
+		assert.NoError(t, err)
+		assert.Len(t, songs, 2)
+		assert.Equal(t, "Pan kiedyś stanął", songs[0].Title)
+		assert.Equal(t, "Zróbcie mu miejsce", songs[1].Title)
+
+		var count int64
+		tce.DB.Model(&models.Song{}).Count(&count)
+		assert.Equal(t, int64(0), count)
+	})
+
+	te.Run("creates the songs in the team", func(t *testing.T, tce *tests.TestCaseEnvironment) {
+		user, team := tests.CreateUserWithTeam(t, tce.DB, "test@example.com")
+
+		songs, err := tce.Container.Import.ImportTugal(user, team.UUID.String(), data, core.TugalCP1250, false)
+		assert.NoError(t, err)
+		assert.Len(t, songs, 2)
+
+		var saved []models.Song
+		tce.DB.Where("team_id = ?", team.ID).Order("id").Find(&saved)
+		assert.Len(t, saved, 2)
+		assert.Equal(t, 2, len(saved[0].FormatLyrics(models.FormatLyricsOptions{Raw: true})))
+		assert.True(t, saved[0].IsDraft)
+		assert.True(t, saved[1].IsDraft)
+	})
+
+	te.Run("fails for a song without a title", func(t *testing.T, tce *tests.TestCaseEnvironment) {
+		user, team := tests.CreateUserWithTeam(t, tce.DB, "test@example.com")
+		untitled, err := core.Tugalize([][]string{{"...", "Zróbcie Mu miejsce"}}, core.DefaultTugalProfile)
+		assert.NoError(t, err)
+
+		_, err = tce.Container.Import.ImportTugal(user, team.UUID.String(), untitled, "", true)
+		assert.Error(t, err)
+	})
+
+	te.Run("fails for a team of another user", func(t *testing.T, tce *tests.TestCaseEnvironment) {
+		_, team := tests.CreateUserWithTeam(t, tce.DB, "test@example.com")
+		otherUser := &models.User{Email: "other@example.com", DisplayName: "Other User"}
+		assert.NoError(t, tce.DB.Create(otherUser).Error)
+
+		_, err := tce.Container.Import.ImportTugal(otherUser, team.UUID.String(), data, "", true)
+		assert.Error(t, err)
+	})
+
+	te.Run("fails for an unknown encoding", func(t *testing.T, tce *tests.TestCaseEnvironment) {
+		user, team := tests.CreateUserWithTeam(t, tce.DB, "test@example.com")
+
+		_, err := tce.Container.Import.ImportTugal(user, team.UUID.String(), data, "utf-16", true)
+		assert.Error(t, err)
+	})
+}
+
+func TestImportOpenLyrics(t *testing.T) {
+	te := tests.NewTestEnvironment(t)
+
+	openLyrics := func(title string) []byte {
+		data, err := core.BuildOpenLyrics(core.OpenLyricsSong{
+			Title:      title,
+			Language:   "pl",
+			VerseOrder: "v1 c v2 c",
+			Verses: []core.OpenLyricsVerse{
+				{Name: "v1", Text: "Pan kiedyś stanął nad brzegiem"},
+				{Name: "c", Text: "O Panie, to Ty na mnie spojrzałeś"},
+				{Name: "v2", Text: "Jestem ubogim człowiekiem"},
+			},
+		})
+		assert.NoError(t, err)
+		return data
+	}
+
+	te.Run("imports a song with its verse order", func(t *testing.T, tce *tests.TestCaseEnvironment) {
+		user, team := tests.CreateUserWithTeam(t, tce.DB, "test@example.com")
+
+		songs, err := tce.Container.Import.ImportSongs(user, team.UUID.String(), openLyrics("Barka"))
+		assert.NoError(t, err)
+		assert.Len(t, songs, 1)
+		assert.Equal(t, "Barka", songs[0].Title)
+		// "c" would be read as a chord
+		assert.Equal(t, "v1 c1 v2 c1", songs[0].VerseOrder)
+		assert.Equal(t, []string{
+			"Pan kiedyś stanął nad brzegiem",
+			"O Panie, to Ty na mnie spojrzałeś",
+			"Jestem ubogim człowiekiem",
+			"O Panie, to Ty na mnie spojrzałeś",
+		}, songs[0].FormatLyrics(models.FormatLyricsOptions{}))
+	})
+
+	te.Run("imports the songs of a ZIP archive", func(t *testing.T, tce *tests.TestCaseEnvironment) {
+		user, team := tests.CreateUserWithTeam(t, tce.DB, "test@example.com")
+
+		var buf bytes.Buffer
+		archive := zip.NewWriter(&buf)
+		for _, title := range []string{"Barka", "Abba Ojcze"} {
+			file, err := archive.Create(title + ".xml")
+			assert.NoError(t, err)
+			_, err = file.Write(openLyrics(title))
+			assert.NoError(t, err)
+		}
+		assert.NoError(t, archive.Close())
+
+		songs, err := tce.Container.Import.ImportSongs(user, team.UUID.String(), buf.Bytes())
+		assert.NoError(t, err)
+		assert.Len(t, songs, 2)
+
+		var count int64
+		tce.DB.Model(&models.Song{}).Where("team_id = ?", team.ID).Count(&count)
+		assert.Equal(t, int64(2), count)
+	})
+
+	te.Run("fails for a file that isn't OpenLyrics", func(t *testing.T, tce *tests.TestCaseEnvironment) {
+		user, team := tests.CreateUserWithTeam(t, tce.DB, "test@example.com")
+
+		_, err := tce.Container.Import.ImportSongs(user, team.UUID.String(), []byte("Pan kiedyś stanął nad brzegiem"))
+		assert.Error(t, err)
+	})
+}
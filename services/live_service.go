@@ -1,11 +1,14 @@
 package services
 
 import (
+	"bytes"
 	"crypto/subtle"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"regexp"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -69,21 +72,57 @@ func (l *LiveService) ValidateLiveSessionKey(key string) bool {
 }
 
 func (l *LiveService) GenerateLiveSessionDeck(input dtos.LiveSessionRequest, user *models.User) (string, error) {
-	textDeck, ok := l.Deck.BuildTextSlides(input.Deck, user)
+	textDeck, items, ok := l.Deck.BuildTextSlides(input.Deck, user)
 	if !ok {
 		return "", errors.New("failed to build text deck")
 	}
 
-	file, _, err := core.BuildPDF(textDeck, l.Deck.GetPageConfig(input.Deck))
+	pageConfig, err := l.Deck.GetPageConfig(input.Deck, user)
+	if err != nil {
+		return "", err
+	}
+
+	file, _, err := core.BuildPDF(textDeck, items, pageConfig)
 	if err != nil {
 		return "", err
 	}
 
 	fileName := uuid.New().String() + ".pdf"
-	return fileName, common.SavePublicFile(file, fileName)
+	err = common.SavePublicFile(file, fileName)
+	if err != nil {
+		return "", err
+	}
+
+	stage, err := core.StageSlides(textDeck, items, pageConfig)
+	if err != nil {
+		return "", err
+	}
+
+	stageData, err := json.Marshal(stage)
+	if err != nil {
+		return "", err
+	}
+
+	return fileName, common.SavePublicFile(bytes.NewReader(stageData), StageFileName(fileName))
+}
+
+// StageFileName names the slides of the stage display, which are saved along with the deck of a session.
+func StageFileName(fileName string) string {
+	return strings.TrimSuffix(fileName, ".pdf") + ".stage.json"
+}
+
+func deleteSessionFiles(fileName string) {
+	common.DeletePublicFile(fileName)
+	common.DeletePublicFile(StageFileName(fileName))
 }
 
 func (l *LiveService) CreateSession(input dtos.LiveSessionRequest, user *models.User) (string, *models.LiveSession, error) {
+	deck, err := l.Deck.ApplyTheme(input.Deck, user)
+	if err != nil {
+		return "", nil, err
+	}
+	input.Deck = deck
+
 	fileName, err := l.GenerateLiveSessionDeck(input, user)
 	if err != nil {
 		return "", nil, err
@@ -127,7 +166,7 @@ func (l *LiveService) DeleteSession(key string) error {
 		return err
 	}
 
-	common.DeletePublicFile(session.FileName)
+	deleteSessionFiles(session.FileName)
 
 	return nil
 }
@@ -138,12 +177,18 @@ func (l *LiveService) UpdateSession(key string, input dtos.LiveSessionRequest, u
 		return errors.New("session not found")
 	}
 
+	deck, err := l.Deck.ApplyTheme(input.Deck, user)
+	if err != nil {
+		return err
+	}
+	input.Deck = deck
+
 	fileName, err := l.GenerateLiveSessionDeck(input, user)
 	if err != nil {
 		return err
 	}
 
-	common.DeletePublicFile(session.FileName)
+	deleteSessionFiles(session.FileName)
 
 	session.FileName = fileName
 	session.URL = common.GetPublicURL(fileName)
@@ -189,7 +234,7 @@ func (l *LiveService) CleanUp() int {
 	}
 
 	for _, filename := range filenames {
-		common.DeletePublicFile(filename)
+		deleteSessionFiles(filename)
 	}
 
 	return len(filenames)
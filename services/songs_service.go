@@ -1,11 +1,16 @@
 package services
 
 import (
+	"archive/zip"
 	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
 	"strings"
 
 	"github.com/google/uuid"
 	"github.com/hejmsdz/goslides/common"
+	"github.com/hejmsdz/goslides/core"
 	"github.com/hejmsdz/goslides/dtos"
 	"github.com/hejmsdz/goslides/models"
 	"gorm.io/gorm"
@@ -29,7 +34,7 @@ func (s SongsService) GetSong(uuidString string, user *models.User) (*models.Son
 		return nil, common.NewAPIError(400, "invalid id", err)
 	}
 
-	err = s.db.Preload("Team").Preload("OverriddenSong").Where("uuid", uuid).Take(&song).Error
+	err = s.db.Preload("Team").Preload("OverriddenSong").Preload("TranslationOf").Where("uuid", uuid).Take(&song).Error
 	if err != nil {
 		return nil, common.NewAPIError(404, "song not found", err)
 	}
@@ -41,7 +46,69 @@ func (s SongsService) GetSong(uuidString string, user *models.User) (*models.Son
 	return &song, nil
 }
 
-func (s SongsService) getSongsQuery(query string, teamID uint, includeUnofficial bool) (*gorm.DB, error) {
+// GetTranslation finds a song in the given language among the original
+// of the song and the other translations of it.
+func (s SongsService) GetTranslation(song *models.Song, language string, user *models.User) (*models.Song, error) {
+	originalID := song.ID
+	if song.TranslationOfID != nil {
+		originalID = *song.TranslationOfID
+	}
+
+	var translations []models.Song
+	err := s.db.Where("language = ?", language).
+		Where("id = ? OR translation_of_id = ?", originalID, originalID).
+		Where("id <> ?", song.ID).
+		Order("id ASC").
+		Find(&translations).Error
+	if err != nil {
+		return nil, common.NewAPIError(500, "failed to get translations", err)
+	}
+
+	for _, translation := range translations {
+		if s.auth.Can(user, "read", &translation) {
+			return &translation, nil
+		}
+	}
+
+	return nil, common.NewAPIError(404, "translation not found", nil)
+}
+
+// setTranslationOf links a song to the original it translates. All the translations
+// are linked to the same original, even if the song is translated from another one.
+func (s SongsService) setTranslationOf(song *models.Song, input dtos.SongRequest, user *models.User) error {
+	song.Language = input.Language
+	if song.Language == "" {
+		song.Language = models.DefaultLanguage
+	}
+
+	if input.TranslationOfID == "" {
+		song.TranslationOf = nil
+		song.TranslationOfID = nil
+		return nil
+	}
+
+	original, err := s.GetSong(input.TranslationOfID, user)
+	if err != nil {
+		return err
+	}
+
+	if original.TranslationOf != nil {
+		original = original.TranslationOf
+	}
+
+	if original.ID == song.ID {
+		return common.NewAPIError(422, "a song can't be a translation of itself", nil)
+	}
+
+	song.TranslationOf = original
+	song.TranslationOfID = &original.ID
+
+	return nil
+}
+
+// teamSongsQuery finds the songs a team can see: its own ones and the official ones
+// it hasn't overridden. Without a team, only the official songs are found.
+func (s SongsService) teamSongsQuery(query string, teamID uint, includeUnofficial bool) *gorm.DB {
 	querySlug := common.Slugify(query, true)
 
 	db := s.db.Debug().Model(&models.Song{})
@@ -60,11 +127,11 @@ func (s SongsService) getSongsQuery(query string, teamID uint, includeUnofficial
 		db = db.Where("songs.is_unofficial = false")
 	}
 
-	db = db.Preload("Team").
-		Omit("lyrics").
-		Order("title ASC, subtitle ASC")
+	return db.Preload("Team").Order("title ASC, subtitle ASC")
+}
 
-	return db, nil
+func (s SongsService) getSongsQuery(query string, teamID uint, includeUnofficial bool) (*gorm.DB, error) {
+	return s.teamSongsQuery(query, teamID, includeUnofficial).Omit("lyrics"), nil
 }
 
 func (s SongsService) FilterSongsPaginated(query string, user *models.User, teamUUID string, limit int, offset int) ([]models.Song, int64, error) {
@@ -123,12 +190,17 @@ func (s SongsService) CreateSong(input dtos.SongRequest, user *models.User) (*mo
 	}
 
 	song := &models.Song{
-		Title:       input.Title,
-		Subtitle:    sql.NullString{String: input.Subtitle, Valid: input.Subtitle != ""},
-		Author:      sql.NullString{String: input.Author, Valid: input.Author != ""},
-		Lyrics:      strings.Join(input.Lyrics, "\n\n"),
-		CreatedByID: user.ID,
-		UpdatedByID: user.ID,
+		Title:          input.Title,
+		Subtitle:       sql.NullString{String: input.Subtitle, Valid: input.Subtitle != ""},
+		Author:         sql.NullString{String: input.Author, Valid: input.Author != ""},
+		Copyright:      sql.NullString{String: input.Copyright, Valid: input.Copyright != ""},
+		CCLINumber:     sql.NullString{String: input.CCLINumber, Valid: input.CCLINumber != ""},
+		SongbookNumber: sql.NullString{String: input.SongbookNumber, Valid: input.SongbookNumber != ""},
+		Lyrics:         strings.Join(input.Lyrics, "\n\n"),
+		VerseOrder:     strings.Join(strings.Fields(input.VerseOrder), " "),
+		IsDraft:        input.IsDraft,
+		CreatedByID:    user.ID,
+		UpdatedByID:    user.ID,
 	}
 
 	if team != nil {
@@ -140,6 +212,10 @@ func (s SongsService) CreateSong(input dtos.SongRequest, user *models.User) (*mo
 		song.IsUnofficial = input.IsUnofficial
 	}
 
+	if err := s.setTranslationOf(song, input, user); err != nil {
+		return nil, err
+	}
+
 	if !s.auth.Can(user, "create", song) {
 		return nil, common.NewAPIError(403, "forbidden", nil)
 	}
@@ -170,7 +246,12 @@ func (s SongsService) UpdateSong(id string, input dtos.SongRequest, user *models
 	song.Title = input.Title
 	song.Subtitle = sql.NullString{String: input.Subtitle, Valid: input.Subtitle != ""}
 	song.Author = sql.NullString{String: input.Author, Valid: input.Author != ""}
+	song.Copyright = sql.NullString{String: input.Copyright, Valid: input.Copyright != ""}
+	song.CCLINumber = sql.NullString{String: input.CCLINumber, Valid: input.CCLINumber != ""}
+	song.SongbookNumber = sql.NullString{String: input.SongbookNumber, Valid: input.SongbookNumber != ""}
 	song.Lyrics = strings.Join(input.Lyrics, "\n\n")
+	song.VerseOrder = strings.Join(strings.Fields(input.VerseOrder), " ")
+	song.IsDraft = input.IsDraft
 	song.UpdatedByID = user.ID
 
 	if newTeam == nil {
@@ -185,6 +266,10 @@ func (s SongsService) UpdateSong(id string, input dtos.SongRequest, user *models
 		song.IsUnofficial = input.IsUnofficial
 	}
 
+	if err := s.setTranslationOf(song, input, user); err != nil {
+		return nil, err
+	}
+
 	err = s.db.Save(&song).Error
 	if err != nil {
 		return nil, common.NewAPIError(500, "failed to save", err)
@@ -251,3 +336,118 @@ func (s SongsService) DeleteSong(id string, user *models.User) error {
 
 	return nil
 }
+
+// ExportOpenLyrics writes the song in the OpenLyrics format, to be opened in OpenLP.
+func (s SongsService) ExportOpenLyrics(id string, user *models.User) ([]byte, *models.Song, error) {
+	song, err := s.GetSong(id, user)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data, err := core.BuildOpenLyrics(song.OpenLyrics())
+	if err != nil {
+		return nil, nil, common.NewAPIError(500, "failed to export the song", err)
+	}
+
+	return data, song, nil
+}
+
+const SongsExportJSON = "json"
+const SongsExportText = "txt"
+const SongsExportOpenLyrics = "openlyrics"
+
+// ExportTeamSongs finds all the songs the team can see in the song list, with their lyrics.
+func (s SongsService) ExportTeamSongs(user *models.User, teamUUID string, format string) ([]models.Song, *models.Team, error) {
+	if format != SongsExportJSON && format != SongsExportText && format != SongsExportOpenLyrics {
+		return nil, nil, common.NewAPIError(400, "invalid format", nil)
+	}
+
+	team, err := s.teams.GetUserTeam(user, teamUUID)
+	if err != nil || team == nil {
+		return nil, nil, common.NewAPIError(404, "team not found", err)
+	}
+
+	var songs []models.Song
+	err = s.teamSongsQuery("", team.ID, team.CanAccessUnofficialSongs).
+		Preload("OverriddenSong").
+		Preload("TranslationOf").
+		Find(&songs).Error
+	if err != nil {
+		return nil, nil, common.NewAPIError(500, "failed to get songs", err)
+	}
+
+	return songs, team, nil
+}
+
+// songText writes the song as plain text, with the metadata above the lyrics.
+func songText(song *models.Song) string {
+	lines := []string{song.Title}
+	if song.Subtitle.Valid {
+		lines = append(lines, song.Subtitle.String)
+	}
+	lines = append(lines, "")
+
+	metadata := [][2]string{
+		{"Author", song.Author.String},
+		{"Copyright", song.Copyright.String},
+		{"CCLI", song.CCLINumber.String},
+		{"Songbook", song.SongbookNumber.String},
+		{"Language", song.Language},
+		{"ID", song.UUID.String()},
+	}
+	if song.OverriddenSong != nil {
+		metadata = append(metadata, [2]string{"Overrides", song.OverriddenSong.UUID.String()})
+	}
+
+	for _, field := range metadata {
+		if field[1] != "" {
+			lines = append(lines, field[0]+": "+field[1])
+		}
+	}
+
+	lyrics := song.FormatLyrics(models.FormatLyricsOptions{})
+	return strings.Join(lines, "\n") + "\n\n" + strings.Join(lyrics, "\n\n") + "\n"
+}
+
+// WriteSongsArchive writes a ZIP with a file for every song. The JSON files can be
+// imported back as they are, the other formats are for reading and other programs.
+func WriteSongsArchive(w io.Writer, songs []models.Song, format string) error {
+	archive := zip.NewWriter(w)
+	usedNames := make(map[string]bool)
+
+	for _, song := range songs {
+		var data []byte
+		var err error
+		extension := format
+
+		switch format {
+		case SongsExportText:
+			data = []byte(songText(&song))
+		case SongsExportOpenLyrics:
+			extension = "xml"
+			data, err = core.BuildOpenLyrics(song.OpenLyrics())
+		default:
+			data, err = json.MarshalIndent(dtos.NewSongBackup(&song), "", "  ")
+		}
+		if err != nil {
+			return err
+		}
+
+		slug := strings.ReplaceAll(common.Slugify(song.Title, false), " ", "-")
+		name := slug
+		for i := 2; name == "" || usedNames[name]; i++ {
+			name = fmt.Sprintf("%s-%d", slug, i)
+		}
+		usedNames[name] = true
+
+		file, err := archive.Create(name + "." + extension)
+		if err != nil {
+			return err
+		}
+		if _, err := file.Write(data); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
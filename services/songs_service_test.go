@@ -1,11 +1,16 @@
 package services_test
 
 import (
+	"archive/zip"
+	"bytes"
 	"database/sql"
 	"fmt"
+	"strings"
 	"testing"
 
+	"github.com/hejmsdz/goslides/dtos"
 	"github.com/hejmsdz/goslides/models"
+	"github.com/hejmsdz/goslides/services"
 	"github.com/hejmsdz/goslides/tests"
 	"github.com/stretchr/testify/assert"
 )
@@ -208,3 +213,231 @@ func TestFilterSongsUnofficialFiltering(t *testing.T) {
 		}
 	})
 }
+
+func TestFormatTranslation(t *testing.T) {
+	original := models.Song{Lyrics: "[v1] Zwrotka 1\n\n[r] Refren\n\n[v2] Zwrotka 2\n\n%r"}
+	translation := models.Song{Lyrics: "[r] Chorus\n\n[v1] Verse 1\n\n[v2] Verse 2"}
+
+	lyrics := translation.FormatTranslation(original, models.FormatLyricsOptions{Order: []int{0, 1, 2, 3}})
+	assert.Equal(t, []string{"Verse 1", "Chorus", "Verse 2", "Chorus"}, lyrics)
+
+	lyrics = translation.FormatTranslation(original, models.FormatLyricsOptions{Order: []int{2, 3}})
+	assert.Equal(t, []string{"Verse 2", "Chorus"}, lyrics)
+
+	original = models.Song{Lyrics: "Zwrotka 1\n\nZwrotka 2\n\nZwrotka 3"}
+	translation = models.Song{Lyrics: "Verse 1\n\nVerse 2"}
+
+	lyrics = translation.FormatTranslation(original, models.FormatLyricsOptions{Order: []int{2, 0}})
+	assert.Equal(t, []string{"", "Verse 1"}, lyrics)
+}
+
+func TestFormatTranslationInStoredOrder(t *testing.T) {
+	original := models.Song{Lyrics: "[v1] Zwrotka 1\n\n[r] Refren\n\n// [v2] Zwrotka 2", VerseOrder: "v1 r v2 r"}
+	translation := models.Song{Lyrics: "[r] Chorus\n\n[v1] Verse 1\n\n[v2] Verse 2", VerseOrder: "r v1 r v2 r"}
+
+	lyrics := translation.FormatTranslation(original, models.FormatLyricsOptions{})
+	assert.Equal(t, []string{"Zwrotka 1", "Refren", "Refren"}, original.FormatLyrics(models.FormatLyricsOptions{}))
+	assert.Equal(t, []string{"Verse 1", "Chorus", "Chorus"}, lyrics)
+
+	original.VerseOrder = ""
+	lyrics = translation.FormatTranslation(original, models.FormatLyricsOptions{})
+	assert.Equal(t, []string{"Verse 1", "Chorus"}, lyrics)
+}
+
+func TestFormatLyricsVerseOrder(t *testing.T) {
+	song := models.Song{
+		Lyrics:     "Wstęp\n\n[v1] Zwrotka 1\n\n[r] Refren\n\n// [v2] Zwrotka 2\n\nZakończenie",
+		VerseOrder: "v1 r v2 r",
+	}
+
+	lyrics := song.FormatLyrics(models.FormatLyricsOptions{})
+	assert.Equal(t, []string{"Wstęp", "Zwrotka 1", "Refren", "Refren", "Zakończenie"}, lyrics)
+
+	lyrics = song.FormatLyrics(models.FormatLyricsOptions{Order: []int{1, 3}})
+	assert.Equal(t, []string{"Zwrotka 1", "Zwrotka 2"}, lyrics)
+}
+
+func TestGetTranslation(t *testing.T) {
+	te := tests.NewTestEnvironment(t)
+
+	te.Run("finds the original and the other translations of a song", func(t *testing.T, tce *tests.TestCaseEnvironment) {
+		testData := createTestData(t, tce, false)
+		original := testData.Songs[0]
+
+		english := &models.Song{
+			Title:           "Official Song 1 (English)",
+			Lyrics:          "Verse 1\n\nVerse 2",
+			Language:        "en",
+			TranslationOfID: &original.ID,
+			CreatedByID:     testData.User.ID,
+			UpdatedByID:     testData.User.ID,
+		}
+		err := tce.DB.Create(english).Error
+		assert.NoError(t, err)
+
+		ukrainian := &models.Song{
+			Title:           "Official Song 1 (Ukrainian)",
+			Lyrics:          "Verse 1\n\nVerse 2",
+			Language:        "uk",
+			TranslationOfID: &original.ID,
+			CreatedByID:     testData.User.ID,
+			UpdatedByID:     testData.User.ID,
+		}
+		err = tce.DB.Create(ukrainian).Error
+		assert.NoError(t, err)
+
+		translation, err := tce.Container.Songs.GetTranslation(original, "en", testData.User)
+		assert.NoError(t, err)
+		assert.Equal(t, english.ID, translation.ID)
+
+		translation, err = tce.Container.Songs.GetTranslation(english, "pl", testData.User)
+		assert.NoError(t, err)
+		assert.Equal(t, original.ID, translation.ID)
+
+		translation, err = tce.Container.Songs.GetTranslation(english, "uk", testData.User)
+		assert.NoError(t, err)
+		assert.Equal(t, ukrainian.ID, translation.ID)
+
+		_, err = tce.Container.Songs.GetTranslation(original, "de", testData.User)
+		assert.Error(t, err)
+	})
+}
+
+func TestExportOpenLyrics(t *testing.T) {
+	te := tests.NewTestEnvironment(t)
+
+	te.Run("names the verses and keeps the references in the verse order", func(t *testing.T, tce *tests.TestCaseEnvironment) {
+		testData := createTestData(t, tce, false)
+
+		song := &models.Song{
+			Title:       "Barka",
+			Lyrics:      "Pan kiedyś stanął nad brzegiem\n\n[c1] O Panie, to Ty na mnie spojrzałeś\n\nJestem ubogim człowiekiem\n\n%c1",
+			TeamID:      &testData.Team.ID,
+			CreatedByID: testData.User.ID,
+			UpdatedByID: testData.User.ID,
+		}
+		assert.NoError(t, tce.DB.Create(song).Error)
+
+		data, _, err := tce.Container.Songs.ExportOpenLyrics(song.UUID.String(), testData.User)
+		assert.NoError(t, err)
+
+		document := string(data)
+		assert.Contains(t, document, "<verseOrder>v1 c1 v2 c1</verseOrder>")
+		assert.Contains(t, document, `<verse name="c1">`)
+		assert.Equal(t, 3, strings.Count(document, "<verse "))
+	})
+}
+
+// exportTestSongs adds an override, a song and its translation to the team
+// and exports all the songs the team can see.
+func exportTestSongs(t *testing.T, tce *tests.TestCaseEnvironment, testData *TestData) (override *models.Song, original *models.Song, archive []byte) {
+	teamID := testData.Team.UUID.String()
+
+	override, err := tce.Container.Songs.OverrideSong(testData.Songs[0].UUID.String(), dtos.SongRequest{
+		Title:  "Official Song 1",
+		Lyrics: []string{"Verse 1", "Verse 2", "Verse 3"},
+		TeamID: teamID,
+	}, testData.User)
+	assert.NoError(t, err)
+
+	original, err = tce.Container.Songs.CreateSong(dtos.SongRequest{
+		Title:      "Barka",
+		Author:     "Cesáreo Gabaráin",
+		Lyrics:     []string{"[v1] Pan kiedyś stanął nad brzegiem", "[c1] O Panie, to Ty na mnie spojrzałeś", "[v2] Jestem ubogim człowiekiem"},
+		VerseOrder: "v1 c1 v2 c1",
+		TeamID:     teamID,
+	}, testData.User)
+	assert.NoError(t, err)
+
+	_, err = tce.Container.Songs.CreateSong(dtos.SongRequest{
+		Title:           "Fishers of Men",
+		Lyrics:          []string{"Lord, you have come to the seashore"},
+		Language:        "en",
+		TranslationOfID: original.UUID.String(),
+		TeamID:          teamID,
+	}, testData.User)
+	assert.NoError(t, err)
+
+	songs, _, err := tce.Container.Songs.ExportTeamSongs(testData.User, teamID, services.SongsExportJSON)
+	assert.NoError(t, err)
+	assert.Len(t, songs, 4)
+
+	var buf bytes.Buffer
+	assert.NoError(t, services.WriteSongsArchive(&buf, songs, services.SongsExportJSON))
+
+	return override, original, buf.Bytes()
+}
+
+func TestExportTeamSongs(t *testing.T) {
+	te := tests.NewTestEnvironment(t)
+
+	te.Run("exports the songs the team can see and imports them back", func(t *testing.T, tce *tests.TestCaseEnvironment) {
+		testData := createTestData(t, tce, false)
+		override, original, data := exportTestSongs(t, tce, testData)
+
+		archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		assert.NoError(t, err)
+		assert.Len(t, archive.File, 4)
+
+		newTeam := &models.Team{Name: "New Team", CreatedByID: testData.User.ID, Users: []*models.User{testData.User}}
+		assert.NoError(t, tce.DB.Create(newTeam).Error)
+
+		imported, err := tce.Container.Import.ImportSongs(testData.User, newTeam.UUID.String(), data)
+		assert.NoError(t, err)
+		// the official song is left out, it's there already
+		assert.Len(t, imported, 3)
+
+		byTitle := make(map[string]models.Song)
+		for _, song := range imported {
+			byTitle[song.Title] = song
+		}
+
+		assert.Equal(t, testData.Songs[0].ID, *byTitle[override.Title].OverriddenSongID)
+		assert.Equal(t, "v1 c1 v2 c1", byTitle["Barka"].VerseOrder)
+		assert.Equal(t, "Cesáreo Gabaráin", byTitle["Barka"].Author.String)
+		assert.Equal(t, original.Lyrics, byTitle["Barka"].Lyrics)
+		assert.Equal(t, byTitle["Barka"].ID, *byTitle["Fishers of Men"].TranslationOfID)
+	})
+
+	te.Run("restores the songs into the team they were exported from", func(t *testing.T, tce *tests.TestCaseEnvironment) {
+		testData := createTestData(t, tce, false)
+		teamID := testData.Team.UUID.String()
+		override, original, data := exportTestSongs(t, tce, testData)
+
+		_, err := tce.Container.Songs.UpdateSong(original.UUID.String(), dtos.SongRequest{
+			Title:  "Barka",
+			Lyrics: []string{"Pan kiedyś stanął nad brzegiem"},
+			TeamID: teamID,
+		}, testData.User)
+		assert.NoError(t, err)
+
+		var countBefore int64
+		tce.DB.Model(&models.Song{}).Where("team_id = ?", testData.Team.ID).Count(&countBefore)
+
+		imported, err := tce.Container.Import.ImportSongs(testData.User, teamID, data)
+		assert.NoError(t, err)
+		assert.Len(t, imported, 3)
+
+		var countAfter int64
+		tce.DB.Model(&models.Song{}).Where("team_id = ?", testData.Team.ID).Count(&countAfter)
+		assert.Equal(t, countBefore, countAfter)
+
+		byTitle := make(map[string]models.Song)
+		for _, song := range imported {
+			byTitle[song.Title] = song
+		}
+
+		assert.Equal(t, override.UUID, byTitle[override.Title].UUID)
+		assert.Equal(t, original.UUID, byTitle["Barka"].UUID)
+		assert.Equal(t, original.Lyrics, byTitle["Barka"].Lyrics)
+		assert.Equal(t, "v1 c1 v2 c1", byTitle["Barka"].VerseOrder)
+		assert.Equal(t, original.ID, *byTitle["Fishers of Men"].TranslationOfID)
+	})
+
+	te.Run("fails for an unknown format", func(t *testing.T, tce *tests.TestCaseEnvironment) {
+		testData := createTestData(t, tce, false)
+
+		_, _, err := tce.Container.Songs.ExportTeamSongs(testData.User, testData.Team.UUID.String(), "pdf")
+		assert.Error(t, err)
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/hejmsdz/goslides/common"
 	"github.com/hejmsdz/goslides/dtos"
 	"github.com/hejmsdz/goslides/models"
@@ -98,6 +99,57 @@ func (t *TeamsService) CreateTeam(user *models.User, input *dtos.TeamRequest) (*
 	return team, nil
 }
 
+// teamImageUUID finds an image of the team by its UUID, an empty string clears the setting.
+func (t *TeamsService) teamImageUUID(team *models.Team, imageUUID string) (*uuid.UUID, error) {
+	if imageUUID == "" {
+		return nil, nil
+	}
+
+	var image models.Image
+	err := t.db.Where("uuid = ? AND team_id = ?", imageUUID, team.ID).Take(&image).Error
+	if err != nil {
+		return nil, common.NewAPIError(http.StatusUnprocessableEntity, "image not found in the team", err)
+	}
+
+	return &image.UUID, nil
+}
+
+func (t *TeamsService) UpdateTeam(user *models.User, uuid string, input *dtos.TeamUpdateRequest) (*models.Team, error) {
+	if user == nil {
+		return nil, errors.New("user is nil")
+	}
+
+	team, err := t.GetUserTeam(user, uuid)
+	if err != nil {
+		return nil, common.NewAPIError(http.StatusNotFound, "team not found", err)
+	}
+
+	if input.Name != nil {
+		team.Name = *input.Name
+	}
+
+	if input.BackgroundImage != nil {
+		team.BackgroundImageUUID, err = t.teamImageUUID(team, *input.BackgroundImage)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if input.Logo != nil {
+		team.LogoUUID, err = t.teamImageUUID(team, *input.Logo)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = t.db.Save(team).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return team, nil
+}
+
 func (t *TeamsService) CreateInvitation(user *models.User, uuid string) (*models.Invitation, error) {
 	if user == nil {
 		return nil, errors.New("user is nil")
@@ -438,3 +438,20 @@ func TestJoinTeam(t *testing.T) {
 		assert.Equal(t, "invitation not found", err.Error())
 	})
 }
+
+func TestCanManageTeam(t *testing.T) {
+	te := tests.NewTestEnvironment(t)
+
+	te.Run("only the owner and the admins can manage a team", func(t *testing.T, tce *tests.TestCaseEnvironment) {
+		owner, team := tests.CreateUserWithTeam(t, tce.DB, "owner@example.com")
+		member := &models.User{Email: "member@example.com", DisplayName: "Member", Teams: []*models.Team{team}}
+		admin := &models.User{Email: "admin@example.com", DisplayName: "Admin", IsAdmin: true}
+		assert.NoError(t, tce.DB.Create(member).Error)
+		assert.NoError(t, tce.DB.Create(admin).Error)
+
+		assert.True(t, tce.Container.Auth.CanManageTeam(owner, team))
+		assert.False(t, tce.Container.Auth.CanManageTeam(member, team))
+		assert.True(t, tce.Container.Auth.CanManageTeam(admin, team))
+		assert.False(t, tce.Container.Auth.CanManageTeam(nil, team))
+	})
+}
@@ -0,0 +1,191 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/hejmsdz/goslides/common"
+	"github.com/hejmsdz/goslides/dtos"
+	"github.com/hejmsdz/goslides/fonts"
+	"github.com/hejmsdz/goslides/models"
+	"gorm.io/gorm"
+)
+
+type ThemesService struct {
+	db    *gorm.DB
+	teams *TeamsService
+}
+
+func NewThemesService(db *gorm.DB, teams *TeamsService) *ThemesService {
+	return &ThemesService{db, teams}
+}
+
+func (t *ThemesService) getTeam(user *models.User, teamUUID string) (*models.Team, error) {
+	if user == nil {
+		return nil, errors.New("user is nil")
+	}
+
+	team, err := t.teams.GetUserTeam(user, teamUUID)
+	if err != nil {
+		return nil, common.NewAPIError(http.StatusNotFound, "team not found", err)
+	}
+
+	return team, nil
+}
+
+func (t *ThemesService) GetThemes(user *models.User, teamUUID string) ([]*models.Theme, error) {
+	team, err := t.getTeam(user, teamUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	var themes []*models.Theme
+	err = t.db.Where("team_id = ?", team.ID).Order("name").Find(&themes).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return themes, nil
+}
+
+func (t *ThemesService) findTheme(team *models.Team, themeUUID string) (*models.Theme, error) {
+	var theme *models.Theme
+	err := t.db.Where("uuid = ? AND team_id = ?", themeUUID, team.ID).Take(&theme).Error
+	if err != nil {
+		return nil, common.NewAPIError(http.StatusNotFound, "theme not found", err)
+	}
+
+	return theme, nil
+}
+
+func (t *ThemesService) GetTheme(user *models.User, teamUUID string, themeUUID string) (*models.Theme, error) {
+	team, err := t.getTeam(user, teamUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.findTheme(team, themeUUID)
+}
+
+// checkFont makes sure that a theme uses one of the bundled fonts or a font of its team.
+func (t *ThemesService) checkFont(team *models.Team, fontID string) error {
+	if fontID == "" || fonts.IsBundled(fontID) {
+		return nil
+	}
+
+	fontUUID, err := uuid.Parse(fontID)
+	if err != nil {
+		return common.NewAPIError(http.StatusUnprocessableEntity, "font not found in the team", err)
+	}
+
+	var font models.Font
+	err = t.db.Omit("data", "bold_data", "italic_data").Where("uuid = ? AND team_id = ?", fontUUID, team.ID).Take(&font).Error
+	if err != nil {
+		return common.NewAPIError(http.StatusUnprocessableEntity, "font not found in the team", err)
+	}
+
+	return nil
+}
+
+// encodeStyle makes sure that the images and the font of a theme belong to its team.
+func (t *ThemesService) encodeStyle(team *models.Team, style dtos.DeckStyle) (string, error) {
+	for _, image := range []string{style.BackgroundImage, style.Logo} {
+		if _, err := t.teams.teamImageUUID(team, image); err != nil {
+			return "", err
+		}
+	}
+
+	if err := t.checkFont(team, style.Font); err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(style)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+func (t *ThemesService) CreateTheme(user *models.User, teamUUID string, input dtos.ThemeRequest) (*models.Theme, error) {
+	team, err := t.getTeam(user, teamUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	style, err := t.encodeStyle(team, input.Style)
+	if err != nil {
+		return nil, err
+	}
+
+	theme := &models.Theme{
+		TeamID:      team.ID,
+		Name:        input.Name,
+		Style:       style,
+		CreatedByID: user.ID,
+	}
+
+	err = t.db.Create(theme).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return theme, nil
+}
+
+func (t *ThemesService) UpdateTheme(user *models.User, teamUUID string, themeUUID string, input dtos.ThemeRequest) (*models.Theme, error) {
+	team, err := t.getTeam(user, teamUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	theme, err := t.findTheme(team, themeUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	style, err := t.encodeStyle(team, input.Style)
+	if err != nil {
+		return nil, err
+	}
+
+	theme.Name = input.Name
+	theme.Style = style
+
+	err = t.db.Save(theme).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return theme, nil
+}
+
+func (t *ThemesService) DeleteTheme(user *models.User, teamUUID string, themeUUID string) error {
+	theme, err := t.GetTheme(user, teamUUID, themeUUID)
+	if err != nil {
+		return err
+	}
+
+	return t.db.Delete(theme).Error
+}
+
+// GetThemeStyle finds a theme in any of the user's teams, for the decks
+// which refer to it by its UUID alone.
+func (t *ThemesService) GetThemeStyle(user *models.User, themeUUID string) (dtos.DeckStyle, error) {
+	if user == nil {
+		return dtos.DeckStyle{}, common.NewAPIError(http.StatusNotFound, "theme not found", nil)
+	}
+
+	var theme *models.Theme
+	err := t.db.Joins("INNER JOIN user_teams ON user_teams.team_id = themes.team_id").
+		Where("user_teams.user_id = ?", user.ID).
+		Where("themes.uuid = ?", themeUUID).
+		Take(&theme).Error
+	if err != nil {
+		return dtos.DeckStyle{}, common.NewAPIError(http.StatusNotFound, "theme not found", err)
+	}
+
+	return dtos.ThemeStyle(theme)
+}
@@ -0,0 +1,93 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/hejmsdz/goslides/dtos"
+	"github.com/hejmsdz/goslides/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThemes(t *testing.T) {
+	te := tests.NewTestEnvironment(t)
+
+	te.Run("theme lifecycle", func(t *testing.T, tce *tests.TestCaseEnvironment) {
+		user, team := tests.CreateUserWithTeam(t, tce.DB, "test@example.com")
+		themes := tce.Container.Themes
+
+		input := dtos.ThemeRequest{
+			Name:  "Wieczór uwielbienia",
+			Style: dtos.DeckStyle{FontSize: 60, BackgroundColor: "#000080"},
+		}
+		theme, err := themes.CreateTheme(user, team.UUID.String(), input)
+		assert.NoError(t, err)
+
+		list, err := themes.GetThemes(user, team.UUID.String())
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(list))
+		assert.Equal(t, input.Style, dtos.NewThemeResponse(list[0]).Style)
+
+		input.Style.FontSize = 48
+		updated, err := themes.UpdateTheme(user, team.UUID.String(), theme.UUID.String(), input)
+		assert.NoError(t, err)
+		assert.Equal(t, 48, dtos.NewThemeResponse(updated).Style.FontSize)
+
+		err = themes.DeleteTheme(user, team.UUID.String(), theme.UUID.String())
+		assert.NoError(t, err)
+
+		_, err = themes.GetTheme(user, team.UUID.String(), theme.UUID.String())
+		assert.Error(t, err)
+	})
+
+	te.Run("themes are private to the team", func(t *testing.T, tce *tests.TestCaseEnvironment) {
+		user, team := tests.CreateUserWithTeam(t, tce.DB, "test@example.com")
+		outsider, _ := tests.CreateUserWithTeam(t, tce.DB, "outsider@example.com")
+		themes := tce.Container.Themes
+
+		theme, err := themes.CreateTheme(user, team.UUID.String(), dtos.ThemeRequest{Name: "Theme"})
+		assert.NoError(t, err)
+
+		_, err = themes.GetThemes(outsider, team.UUID.String())
+		assert.Error(t, err)
+
+		_, err = themes.CreateTheme(outsider, team.UUID.String(), dtos.ThemeRequest{Name: "Theme"})
+		assert.Error(t, err)
+
+		_, err = themes.GetThemeStyle(outsider, theme.UUID.String())
+		assert.Error(t, err)
+	})
+
+	te.Run("deck options override the theme", func(t *testing.T, tce *tests.TestCaseEnvironment) {
+		user, team := tests.CreateUserWithTeam(t, tce.DB, "test@example.com")
+
+		theme, err := tce.Container.Themes.CreateTheme(user, team.UUID.String(), dtos.ThemeRequest{
+			Name:  "Theme",
+			Style: dtos.DeckStyle{FontSize: 60, TextColor: "#FFFF00"},
+		})
+		assert.NoError(t, err)
+
+		deck := dtos.DeckRequest{ThemeID: theme.UUID.String()}
+		deck.TextColor = "#FFFFFF"
+
+		deck, err = tce.Container.Deck.ApplyTheme(deck, user)
+		assert.NoError(t, err)
+		assert.Equal(t, 60, deck.FontSize)
+		assert.Equal(t, "#FFFFFF", deck.TextColor)
+	})
+
+	te.Run("fails when the deck options don't suit the theme", func(t *testing.T, tce *tests.TestCaseEnvironment) {
+		user, team := tests.CreateUserWithTeam(t, tce.DB, "test@example.com")
+
+		theme, err := tce.Container.Themes.CreateTheme(user, team.UUID.String(), dtos.ThemeRequest{
+			Name:  "Theme",
+			Style: dtos.DeckStyle{FontSize: 40},
+		})
+		assert.NoError(t, err)
+
+		deck := dtos.DeckRequest{ThemeID: theme.UUID.String()}
+		deck.MinFontSize = 48
+
+		_, err = tce.Container.Deck.ApplyTheme(deck, user)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,29 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/hejmsdz/goslides/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// CreateUserWithTeam creates a user with the given email and a team of which they are the only member.
+func CreateUserWithTeam(t *testing.T, db *gorm.DB, email string) (*models.User, *models.Team) {
+	user := &models.User{
+		Email:       email,
+		DisplayName: "Test User",
+	}
+	err := db.Create(user).Error
+	assert.NoError(t, err)
+
+	team := &models.Team{
+		Name:        "Test Team",
+		CreatedByID: user.ID,
+		Users:       []*models.User{user},
+	}
+	err = db.Create(team).Error
+	assert.NoError(t, err)
+
+	return user, team
+}